@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	"github.com/thediveo/enumflag/v2"
 	"github.com/turbot/go-kit/helpers"
@@ -18,6 +19,7 @@ import (
 	"github.com/turbot/steampipe-plugin-sdk/v5/sperr"
 	"github.com/turbot/steampipe/v2/pkg/cmdconfig"
 	"github.com/turbot/steampipe/v2/pkg/constants"
+	"github.com/turbot/steampipe/v2/pkg/display"
 	"github.com/turbot/steampipe/v2/pkg/error_helpers"
 	"github.com/turbot/steampipe/v2/pkg/query"
 	"github.com/turbot/steampipe/v2/pkg/query/queryexecute"
@@ -30,6 +32,12 @@ var queryTimingMode = constants.QueryTimingModeOff
 // variable used to assign the output mode flag
 var queryOutputMode = constants.QueryOutputModeTable
 
+// variable used to assign the template file of the output flag, when it is 'template=<path>'
+var queryOutputTemplate string
+
+// variable used to assign the on-error mode flag
+var queryOnErrorMode = constants.QueryOnErrorModeContinue
+
 // queryConfig holds the configuration needed for query validation
 // This avoids concurrent access to global viper state
 type queryConfig struct {
@@ -37,6 +45,18 @@ type queryConfig struct {
 	share    bool
 	export   []string
 	output   string
+	args     []string
+	watch    string
+	watchKey []string
+	// snapshot file to display the query result of
+	fromSnapshot string
+	// session transcript to replay
+	replay       string
+	failIfRows   bool
+	expectRows   string
+	template     string
+	timing       string
+	timingFile   string
 }
 
 func queryCmd() *cobra.Command {
@@ -58,7 +78,31 @@ Examples:
   steampipe query
 
   # Run a specific query directly
-  steampipe query "select * from cloud"`,
+  steampipe query "select * from cloud"
+
+  # Run a query file, passing query parameters
+  steampipe query instances.sql --arg region=us-east-1 --arg min_cpu=2
+
+  # Run each statement of a query file, stopping at the first failure
+  steampipe query queries.sql --on-error stop
+
+  # Re-run a query every 30 seconds, highlighting rows which change
+  steampipe query "select instance_id, instance_state from aws_ec2_instance" --watch 30s --watch-key instance_id
+
+  # Append the timing of each query, as JSON, to a file
+  steampipe query queries.sql --timing json --timing-file timing.jsonl
+
+  # Fail (exit code 42) if a compliance query returns any rows
+  steampipe query "select * from aws_s3_bucket where not versioning_enabled" --fail-if-rows
+
+  # Render the results using a Go template
+  steampipe query "select name, region from aws_s3_bucket" --output template=buckets.tmpl
+
+  # Convert the query result of a snapshot to csv, without starting the database
+  steampipe query --from-snapshot result.sps --output csv
+
+  # Re-run a session recorded with '.record start session.jsonl', reporting results which differ
+  steampipe query --replay session.jsonl`,
 	}
 
 	// Notes:
@@ -70,22 +114,33 @@ Examples:
 		AddBoolFlag(pconstants.ArgHelp, false, "Help for query", cmdconfig.FlagOptions.WithShortHand("h")).
 		AddBoolFlag(pconstants.ArgHeader, true, "Include column headers csv and table output").
 		AddStringFlag(pconstants.ArgSeparator, ",", "Separator string for csv output").
-		AddVarFlag(enumflag.New(&queryOutputMode, pconstants.ArgOutput, constants.QueryOutputModeIds, enumflag.EnumCaseInsensitive),
+		AddVarFlag(newQueryOutputFlag(&queryOutputMode, &queryOutputTemplate),
 			pconstants.ArgOutput,
-			fmt.Sprintf("Output format; one of: %s", strings.Join(constants.FlagValues(constants.QueryOutputModeIds), ", "))).
+			fmt.Sprintf("Output format; one of: %s (e.g. %s=results.tmpl, to render the results using a Go template)", strings.Join(constants.FlagValues(constants.QueryOutputModeIds), ", "), constants.OutputFormatTemplate)).
 		AddVarFlag(enumflag.New(&queryTimingMode, pconstants.ArgTiming, constants.QueryTimingModeIds, enumflag.EnumCaseInsensitive),
 			pconstants.ArgTiming,
 			fmt.Sprintf("Display query timing; one of: %s", strings.Join(constants.FlagValues(constants.QueryTimingModeIds), ", ")),
 			cmdconfig.FlagOptions.NoOptDefVal(pconstants.ArgOn)).
+		AddStringFlag(constants.ArgTimingFile, "", "Append query timing to a file rather than displaying it").
+		AddVarFlag(enumflag.New(&queryOnErrorMode, constants.ArgOnError, constants.QueryOnErrorModeIds, enumflag.EnumCaseInsensitive),
+			constants.ArgOnError,
+			fmt.Sprintf("Behaviour when a query fails in batch mode; one of: %s", strings.Join([]string{constants.OnErrorContinue, constants.OnErrorStop}, ", "))).
 		AddStringSliceFlag(pconstants.ArgSearchPath, nil, "Set a custom search_path for the steampipe user for a query session (comma-separated)").
 		AddStringSliceFlag(pconstants.ArgSearchPathPrefix, nil, "Set a prefix to the current search path for a query session (comma-separated)").
 		AddBoolFlag(pconstants.ArgInput, true, "Enable interactive prompts").
 		AddBoolFlag(pconstants.ArgSnapshot, false, "Create snapshot in Turbot Pipes with the default (workspace) visibility").
 		AddBoolFlag(pconstants.ArgShare, false, "Create snapshot in Turbot Pipes with 'anyone_with_link' visibility").
 		AddStringArrayFlag(pconstants.ArgSnapshotTag, nil, "Specify tags to set on the snapshot").
+		AddStringArrayFlag(pconstants.ArgArg, nil, "Specify the value of a query parameter, either positional ('--arg value' for $1, $2...) or named ('--arg name=value' for @name)").
 		AddStringFlag(pconstants.ArgSnapshotTitle, "", "The title to give a snapshot").
 		AddIntFlag(pconstants.ArgDatabaseQueryTimeout, 0, "The query timeout").
-		AddStringSliceFlag(pconstants.ArgExport, nil, "Export output to file, supported format: sps (snapshot)").
+		AddStringSliceFlag(pconstants.ArgExport, nil, "Export output to file, supported formats: csv, json, jsonl, parquet, sqlite, sps (snapshot)").
+		AddStringFlag(constants.ArgWatch, "", "Re-run the query at the given interval (e.g. 30s, 5m), highlighting rows which have changed").
+		AddStringSliceFlag(constants.ArgWatchKey, nil, "Columns used to match rows between executions in watch mode (comma-separated)").
+		AddBoolFlag(constants.ArgFailIfRows, false, fmt.Sprintf("Exit with code %d if any query returns rows", constants.ExitCodeQueryAssertionFailed)).
+		AddStringFlag(constants.ArgExpectRows, "", fmt.Sprintf("Exit with code %d if any query returns a number of rows outside the range; one of: N, N-M, N-, >N, >=N, <N, <=N", constants.ExitCodeQueryAssertionFailed)).
+		AddStringFlag(constants.ArgFromSnapshot, "", "Display the query result stored in a snapshot file, without starting the database").
+		AddStringFlag(constants.ArgReplay, "", fmt.Sprintf("Re-run the queries of a session transcript recorded with the %s metaquery, exiting with code %d if any results differ", constants.CmdRecord, constants.ExitCodeReplayDifferences)).
 		AddStringFlag(pconstants.ArgSnapshotLocation, "", "The location to write snapshots - either a local file path or a Turbot Pipes workspace").
 		AddBoolFlag(pconstants.ArgProgress, true, "Display snapshot upload status")
 
@@ -108,11 +163,24 @@ func runQueryCmd(cmd *cobra.Command, args []string) {
 		share:    viper.IsSet(pconstants.ArgShare),
 		export:   viper.GetStringSlice(pconstants.ArgExport),
 		output:   viper.GetString(pconstants.ArgOutput),
+		args:     viper.GetStringSlice(pconstants.ArgArg),
+		watch:    viper.GetString(constants.ArgWatch),
+		watchKey: viper.GetStringSlice(constants.ArgWatchKey),
+
+		fromSnapshot: viper.GetString(constants.ArgFromSnapshot),
+		replay:       viper.GetString(constants.ArgReplay),
+		failIfRows:   viper.GetBool(constants.ArgFailIfRows),
+		expectRows:   viper.GetString(constants.ArgExpectRows),
+		template:     queryOutputTemplate,
+		timing:       viper.GetString(pconstants.ArgTiming),
+		timingFile:   viper.GetString(constants.ArgTimingFile),
 	}
 
 	// validate args
 	err := validateQueryArgs(ctx, args, cfg)
 	error_helpers.FailOnError(err)
+	// the template file is set by the output flag - make it available to the template display
+	viper.Set(constants.ConfigKeyOutputTemplate, cfg.template)
 
 	// if diagnostic mode is set, print out config and return
 	if _, ok := os.LookupEnv(constants.EnvConfigDump); ok {
@@ -120,7 +188,19 @@ func runQueryCmd(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	if len(args) == 0 {
+	// if we are displaying a snapshot, there is no need to initialise the database
+	if cfg.fromSnapshot != "" {
+		failures, err := queryexecute.RunSnapshotSession(ctx, cfg.fromSnapshot)
+		if err != nil {
+			exitCode = constants.ExitCodeInsufficientOrWrongInputs
+			error_helpers.ShowError(ctx, err)
+		} else if failures > 0 {
+			exitCode = constants.ExitCodeQueryExecutionFailed
+		}
+		return
+	}
+
+	if len(args) == 0 && cfg.replay == "" {
 		// no positional arguments - check if there's anything on stdin
 		if stdinData := getPipedStdinData(); len(stdinData) > 0 {
 			// we have data - treat this as an argument
@@ -129,7 +209,7 @@ func runQueryCmd(cmd *cobra.Command, args []string) {
 	}
 
 	// enable paging only in interactive mode
-	interactiveMode := len(args) == 0
+	interactiveMode := len(args) == 0 && cfg.replay == ""
 	// set config to indicate whether we are running an interactive query
 	viper.Set(constants.ConfigKeyInteractive, interactiveMode)
 
@@ -146,36 +226,75 @@ func runQueryCmd(cmd *cobra.Command, args []string) {
 	}
 	defer initData.Cleanup(ctx)
 
-	var failures int
+	var failures, assertionFailures, replayDifferences int
 	switch {
+	case cfg.replay != "":
+		ctx = statushooks.DisableStatusHooks(ctx)
+		replayDifferences, err = queryexecute.RunReplaySession(ctx, initData, cfg.replay)
 	case interactiveMode:
 		err = queryexecute.RunInteractiveSession(ctx, initData)
+	case cfg.watch != "":
+		ctx = statushooks.DisableStatusHooks(ctx)
+		err = queryexecute.RunWatchSession(ctx, initData)
 	default:
 		// NOTE: disable any status updates - we do not want 'loading' output from any queries
 		ctx = statushooks.DisableStatusHooks(ctx)
 
 		// fall through to running a batch query
-		failures, err = queryexecute.RunBatchSession(ctx, initData)
+		failures, assertionFailures, err = queryexecute.RunBatchSession(ctx, initData)
 	}
 
-	// check for err and set the exit code else set the exit code if some queries failed or some rows returned an error
+	// check for err and set the exit code else set the exit code if some queries failed or some rows returned an error,
+	// if some queries returned an unexpected number of rows, or if some replayed queries differ from the recording
 	if err != nil {
 		exitCode = constants.ExitCodeInitializationFailed
 		error_helpers.ShowError(ctx, err)
 	} else if failures > 0 {
 		exitCode = constants.ExitCodeQueryExecutionFailed
+	} else if assertionFailures > 0 {
+		exitCode = constants.ExitCodeQueryAssertionFailed
+	} else if replayDifferences > 0 {
+		exitCode = constants.ExitCodeReplayDifferences
 	}
 }
 
 func validateQueryArgs(ctx context.Context, args []string, cfg *queryConfig) error {
-	interactiveMode := len(args) == 0
+	if err := validateFromSnapshotArgs(args, cfg); err != nil {
+		exitCode = constants.ExitCodeInsufficientOrWrongInputs
+		return err
+	}
+	if err := validateReplayArgs(args, cfg); err != nil {
+		exitCode = constants.ExitCodeInsufficientOrWrongInputs
+		return err
+	}
+	interactiveMode := len(args) == 0 && cfg.fromSnapshot == "" && cfg.replay == ""
 	if interactiveMode && (cfg.snapshot || cfg.share) {
 		exitCode = constants.ExitCodeInsufficientOrWrongInputs
 		return sperr.New("cannot share snapshots in interactive mode")
 	}
 	if interactiveMode && len(cfg.export) > 0 {
 		exitCode = constants.ExitCodeInsufficientOrWrongInputs
-		return sperr.New("cannot export query results in interactive mode")
+		return sperr.New("cannot export query results in interactive mode - use the %s metaquery to export the last result", constants.CmdExport)
+	}
+	if interactiveMode && len(cfg.args) > 0 {
+		exitCode = constants.ExitCodeInsufficientOrWrongInputs
+		return sperr.New("cannot pass query args in interactive mode")
+	}
+	if _, err := query.ParseQueryArgs(cfg.args); err != nil {
+		exitCode = constants.ExitCodeInsufficientOrWrongInputs
+		return err
+	}
+	if err := validateWatchArgs(interactiveMode, cfg); err != nil {
+		exitCode = constants.ExitCodeInsufficientOrWrongInputs
+		return err
+	}
+	if err := validateRowAssertionArgs(interactiveMode, cfg); err != nil {
+		exitCode = constants.ExitCodeInsufficientOrWrongInputs
+		return err
+	}
+	if err := validateTimingArgs(interactiveMode, cfg); err != nil {
+		exitCode = constants.ExitCodeInsufficientOrWrongInputs
+		return err
 	}
 	// if share or snapshot args are set, there must be a query specified
 	err := cmdconfig.ValidateSnapshotArgs(ctx)
@@ -184,15 +303,158 @@ func validateQueryArgs(ctx context.Context, args []string, cfg *queryConfig) err
 		return err
 	}
 
-	validOutputFormats := []string{constants.OutputFormatLine, constants.OutputFormatCSV, constants.OutputFormatTable, constants.OutputFormatJSON, constants.OutputFormatSnapshot, constants.OutputFormatSnapshotShort, constants.OutputFormatNone}
+	validOutputFormats := []string{constants.OutputFormatLine, constants.OutputFormatCSV, constants.OutputFormatTable, constants.OutputFormatJSON, constants.OutputFormatSnapshot, constants.OutputFormatSnapshotShort, constants.OutputFormatTemplate, constants.OutputFormatNone}
 	if !slices.Contains(validOutputFormats, cfg.output) {
 		exitCode = constants.ExitCodeInsufficientOrWrongInputs
 		return sperr.New("invalid output format: '%s', must be one of [%s]", cfg.output, strings.Join(validOutputFormats, ", "))
 	}
+	if err := validateTemplateArgs(interactiveMode, cfg); err != nil {
+		exitCode = constants.ExitCodeInsufficientOrWrongInputs
+		return err
+	}
+
+	return nil
+}
+
+// validateFromSnapshotArgs validates the args when displaying a snapshot - no query is executed
+// so only the output args may be used
+func validateFromSnapshotArgs(args []string, cfg *queryConfig) error {
+	if cfg.fromSnapshot == "" {
+		return nil
+	}
+	switch {
+	case len(args) > 0:
+		return sperr.New("cannot pass a query when using --%s", constants.ArgFromSnapshot)
+	case len(cfg.args) > 0:
+		return sperr.New("cannot pass query args when using --%s", constants.ArgFromSnapshot)
+	case cfg.watch != "":
+		return sperr.New("cannot watch a snapshot")
+	case cfg.snapshot || cfg.share || len(cfg.export) > 0:
+		return sperr.New("cannot export or share query results when using --%s", constants.ArgFromSnapshot)
+	}
+	return nil
+}
+
+// validateReplayArgs validates the args when replaying a session transcript - the queries are read from the transcript
+// and the results are compared with the recording, so no queries, exports or assertions may be given
+func validateReplayArgs(args []string, cfg *queryConfig) error {
+	if cfg.replay == "" {
+		return nil
+	}
+	switch {
+	case len(args) > 0:
+		return sperr.New("cannot pass a query when using --%s", constants.ArgReplay)
+	case len(cfg.args) > 0:
+		return sperr.New("cannot pass query args when using --%s", constants.ArgReplay)
+	case cfg.fromSnapshot != "":
+		return sperr.New("only one of --%s and --%s may be set", constants.ArgFromSnapshot, constants.ArgReplay)
+	case cfg.watch != "":
+		return sperr.New("cannot watch a replayed session")
+	case cfg.snapshot || cfg.share || len(cfg.export) > 0:
+		return sperr.New("cannot export or share query results when using --%s", constants.ArgReplay)
+	case cfg.failIfRows || cfg.expectRows != "":
+		return sperr.New("row count assertions are not supported when using --%s", constants.ArgReplay)
+	}
+	return nil
+}
+
+// validateWatchArgs validates the watch and watch-key args
+// watch mode redraws the query results in the terminal, so cannot be used with any other output
+func validateWatchArgs(interactiveMode bool, cfg *queryConfig) error {
+	if cfg.watch == "" {
+		if len(cfg.watchKey) > 0 {
+			return sperr.New("--%s can only be used with --%s", constants.ArgWatchKey, constants.ArgWatch)
+		}
+		return nil
+	}
+	if interactiveMode {
+		return sperr.New("cannot watch queries in interactive mode")
+	}
+	if cfg.snapshot || cfg.share || len(cfg.export) > 0 {
+		return sperr.New("cannot export or share query results in watch mode")
+	}
+	if cfg.output != constants.OutputFormatTable {
+		return sperr.New("watch mode only supports table output")
+	}
+	_, err := queryexecute.ParseWatchInterval(cfg.watch)
+	return err
+}
+
+// validateRowAssertionArgs validates the fail-if-rows and expect-rows args
+// row counts are only asserted in batch mode
+func validateRowAssertionArgs(interactiveMode bool, cfg *queryConfig) error {
+	if !cfg.failIfRows && cfg.expectRows == "" {
+		return nil
+	}
+	switch {
+	case cfg.failIfRows && cfg.expectRows != "":
+		return sperr.New("only one of --%s and --%s may be set", constants.ArgFailIfRows, constants.ArgExpectRows)
+	case interactiveMode:
+		return sperr.New("cannot assert on query results in interactive mode")
+	case cfg.watch != "" || cfg.fromSnapshot != "":
+		return sperr.New("row count assertions are only supported when executing queries in batch mode")
+	}
+	if cfg.expectRows != "" {
+		if _, err := queryexecute.ParseRowCountRange(cfg.expectRows); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateTemplateArgs validates the template of the output arg - the template must be set, and valid, if the output is 'template'
+func validateTemplateArgs(interactiveMode bool, cfg *queryConfig) error {
+	if cfg.output != constants.OutputFormatTemplate {
+		return nil
+	}
+	if interactiveMode {
+		return sperr.New("template output is not supported in interactive mode")
+	}
+	if cfg.template == "" {
+		return sperr.New("template output requires a template file, e.g. '--%s %s=results.tmpl'", pconstants.ArgOutput, constants.OutputFormatTemplate)
+	}
+	_, err := display.LoadOutputTemplate(cfg.template)
+	return err
+}
 
+// validateTimingArgs validates that timing is enabled if the timing file is set
+// in interactive mode, timing may be enabled later using the .timing meta-command
+func validateTimingArgs(interactiveMode bool, cfg *queryConfig) error {
+	if interactiveMode || (cfg.timing != "" && cfg.timing != pconstants.ArgOff && cfg.timing != "false") {
+		return nil
+	}
+	if cfg.timingFile != "" {
+		return sperr.New("--%s requires --%s", constants.ArgTimingFile, pconstants.ArgTiming)
+	}
 	return nil
 }
 
+// queryOutputFlag is the value of the query output flag - as well as the output formats, it accepts
+// 'template=<path>', which sets the output to 'template' and the template file used to render the results
+type queryOutputFlag struct {
+	pflag.Value
+	templatePath *string
+}
+
+func newQueryOutputFlag(outputMode *constants.QueryOutputMode, templatePath *string) *queryOutputFlag {
+	return &queryOutputFlag{
+		Value:        enumflag.New(outputMode, pconstants.ArgOutput, constants.QueryOutputModeIds, enumflag.EnumCaseInsensitive),
+		templatePath: templatePath,
+	}
+}
+
+func (f *queryOutputFlag) Set(value string) error {
+	format, path, isTemplate := strings.Cut(value, "=")
+	if !isTemplate {
+		return f.Value.Set(value)
+	}
+	if !strings.EqualFold(format, constants.OutputFormatTemplate) {
+		return sperr.New("only '%s' output accepts a file, e.g. '%s=results.tmpl'", constants.OutputFormatTemplate, constants.OutputFormatTemplate)
+	}
+	*f.templatePath = path
+	return f.Value.Set(format)
+}
+
 // getPipedStdinData reads the Standard Input and returns the available data as a string
 // if and only if the data was piped to the process
 func getPipedStdinData() string {
@@ -3,11 +3,13 @@ package cmd
 import (
 	"context"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/turbot/steampipe/v2/pkg/constants"
 )
 
@@ -164,3 +166,343 @@ func TestValidateQueryArgs_InvalidOutputFormat(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid output format")
 }
+
+// TestValidateQueryArgs_InteractiveModeWithQueryArgs tests that query args are rejected in interactive mode
+func TestValidateQueryArgs_InteractiveModeWithQueryArgs(t *testing.T) {
+	ctx := context.Background()
+
+	cfg := &queryConfig{
+		export: []string{},
+		output: constants.OutputFormatTable,
+		args:   []string{"region=us-east-1"},
+	}
+
+	err := validateQueryArgs(ctx, []string{}, cfg)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot pass query args in interactive mode")
+}
+
+// TestValidateQueryArgs_MixedQueryArgs tests that named and positional query args cannot be combined
+func TestValidateQueryArgs_MixedQueryArgs(t *testing.T) {
+	ctx := context.Background()
+
+	cfg := &queryConfig{
+		export: []string{},
+		output: constants.OutputFormatTable,
+		args:   []string{"region=us-east-1", "2"},
+	}
+
+	err := validateQueryArgs(ctx, []string{"SELECT 1"}, cfg)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "named and positional query args cannot be combined")
+}
+
+// TestValidateQueryArgs_Watch tests the validation of watch mode args
+func TestValidateQueryArgs_Watch(t *testing.T) {
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		args    []string
+		cfg     *queryConfig
+		wantErr string
+	}{
+		"valid": {
+			args: []string{"SELECT 1"},
+			cfg:  &queryConfig{output: constants.OutputFormatTable, watch: "5s", watchKey: []string{"id"}},
+		},
+		"interactive": {
+			args:    []string{},
+			cfg:     &queryConfig{output: constants.OutputFormatTable, watch: "5s"},
+			wantErr: "cannot watch queries in interactive mode",
+		},
+		"export": {
+			args:    []string{"SELECT 1"},
+			cfg:     &queryConfig{output: constants.OutputFormatTable, watch: "5s", export: []string{"csv"}},
+			wantErr: "cannot export or share query results in watch mode",
+		},
+		"json output": {
+			args:    []string{"SELECT 1"},
+			cfg:     &queryConfig{output: constants.OutputFormatJSON, watch: "5s"},
+			wantErr: "watch mode only supports table output",
+		},
+		"invalid interval": {
+			args:    []string{"SELECT 1"},
+			cfg:     &queryConfig{output: constants.OutputFormatTable, watch: "soon"},
+			wantErr: "invalid watch interval",
+		},
+		"key without watch": {
+			args:    []string{"SELECT 1"},
+			cfg:     &queryConfig{output: constants.OutputFormatTable, watchKey: []string{"id"}},
+			wantErr: "--watch-key can only be used with --watch",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := validateQueryArgs(ctx, tc.args, tc.cfg)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
+
+// TestValidateQueryArgs_FromSnapshot tests the validation of the from-snapshot arg
+func TestValidateQueryArgs_FromSnapshot(t *testing.T) {
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		args    []string
+		cfg     *queryConfig
+		wantErr string
+	}{
+		"valid": {
+			cfg: &queryConfig{output: constants.OutputFormatCSV, fromSnapshot: "result.sps"},
+		},
+		"query": {
+			args:    []string{"SELECT 1"},
+			cfg:     &queryConfig{output: constants.OutputFormatCSV, fromSnapshot: "result.sps"},
+			wantErr: "cannot pass a query when using --from-snapshot",
+		},
+		"export": {
+			cfg:     &queryConfig{output: constants.OutputFormatCSV, fromSnapshot: "result.sps", export: []string{"csv"}},
+			wantErr: "cannot export or share query results when using --from-snapshot",
+		},
+		"invalid output": {
+			cfg:     &queryConfig{output: "invalid-format", fromSnapshot: "result.sps"},
+			wantErr: "invalid output format",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := validateQueryArgs(ctx, tc.args, tc.cfg)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
+
+// TestValidateQueryArgs_RowAssertions tests the validation of the fail-if-rows and expect-rows args
+func TestValidateQueryArgs_RowAssertions(t *testing.T) {
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		args    []string
+		cfg     *queryConfig
+		wantErr string
+	}{
+		"fail if rows": {
+			args: []string{"SELECT 1"},
+			cfg:  &queryConfig{output: constants.OutputFormatTable, failIfRows: true},
+		},
+		"expect rows": {
+			args: []string{"SELECT 1"},
+			cfg:  &queryConfig{output: constants.OutputFormatTable, expectRows: "1-5"},
+		},
+		"both": {
+			args:    []string{"SELECT 1"},
+			cfg:     &queryConfig{output: constants.OutputFormatTable, failIfRows: true, expectRows: "1"},
+			wantErr: "only one of --fail-if-rows and --expect-rows may be set",
+		},
+		"interactive": {
+			args:    []string{},
+			cfg:     &queryConfig{output: constants.OutputFormatTable, failIfRows: true},
+			wantErr: "cannot assert on query results in interactive mode",
+		},
+		"watch": {
+			args:    []string{"SELECT 1"},
+			cfg:     &queryConfig{output: constants.OutputFormatTable, failIfRows: true, watch: "5s"},
+			wantErr: "row count assertions are only supported when executing queries in batch mode",
+		},
+		"invalid range": {
+			args:    []string{"SELECT 1"},
+			cfg:     &queryConfig{output: constants.OutputFormatTable, expectRows: "lots"},
+			wantErr: "invalid row count 'lots'",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := validateQueryArgs(ctx, tc.args, tc.cfg)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
+
+// TestValidateQueryArgs_Template tests the validation of template output
+func TestValidateQueryArgs_Template(t *testing.T) {
+	ctx := context.Background()
+	templatePath := filepath.Join(t.TempDir(), "output.tmpl")
+	require.NoError(t, os.WriteFile(templatePath, []byte("{{len .Rows}} rows"), 0600))
+
+	tests := map[string]struct {
+		args    []string
+		cfg     *queryConfig
+		wantErr string
+	}{
+		"valid": {
+			args: []string{"SELECT 1"},
+			cfg:  &queryConfig{output: constants.OutputFormatTemplate, template: templatePath},
+		},
+		"missing template": {
+			args:    []string{"SELECT 1"},
+			cfg:     &queryConfig{output: constants.OutputFormatTemplate},
+			wantErr: "template output requires a template file, e.g. '--output template=results.tmpl'",
+		},
+		"interactive": {
+			args:    []string{},
+			cfg:     &queryConfig{output: constants.OutputFormatTemplate, template: templatePath},
+			wantErr: "template output is not supported in interactive mode",
+		},
+		"missing file": {
+			args:    []string{"SELECT 1"},
+			cfg:     &queryConfig{output: constants.OutputFormatTemplate, template: filepath.Join(t.TempDir(), "missing.tmpl")},
+			wantErr: "failed to load output template",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := validateQueryArgs(ctx, tc.args, tc.cfg)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
+
+// TestQueryOutputFlag tests parsing the output flag, including the 'template=<path>' form
+func TestQueryOutputFlag(t *testing.T) {
+	tests := map[string]struct {
+		value        string
+		wantMode     constants.QueryOutputMode
+		wantTemplate string
+		wantErr      string
+	}{
+		"format":                {value: "csv", wantMode: constants.QueryOutputModeCsv},
+		"template":              {value: "template", wantMode: constants.QueryOutputModeTemplate},
+		"template with file":    {value: "template=results.tmpl", wantMode: constants.QueryOutputModeTemplate, wantTemplate: "results.tmpl"},
+		"template upper case":   {value: "TEMPLATE=a=b.tmpl", wantMode: constants.QueryOutputModeTemplate, wantTemplate: "a=b.tmpl"},
+		"file for other format": {value: "csv=results.csv", wantErr: "only 'template' output accepts a file"},
+		"invalid format":        {value: "xml", wantErr: "must be"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			mode, template := constants.QueryOutputModeTable, ""
+			err := newQueryOutputFlag(&mode, &template).Set(tc.value)
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantMode, mode)
+			assert.Equal(t, tc.wantTemplate, template)
+		})
+	}
+}
+
+// TestValidateQueryArgs_Timing tests that timing must be enabled to use the timing file
+func TestValidateQueryArgs_Timing(t *testing.T) {
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		args    []string
+		cfg     *queryConfig
+		wantErr string
+	}{
+		"json timing file": {
+			args: []string{"SELECT 1"},
+			cfg:  &queryConfig{output: constants.OutputFormatTable, timing: constants.TimingJSON, timingFile: "timing.jsonl"},
+		},
+		"timing file without timing": {
+			args:    []string{"SELECT 1"},
+			cfg:     &queryConfig{output: constants.OutputFormatTable, timing: "off", timingFile: "timing.jsonl"},
+			wantErr: "--timing-file requires --timing",
+		},
+		"interactive": {
+			args: []string{},
+			cfg:  &queryConfig{output: constants.OutputFormatTable, timing: "off", timingFile: "timing.jsonl"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := validateQueryArgs(ctx, tc.args, tc.cfg)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
+
+func TestValidateQueryArgs_Replay(t *testing.T) {
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		args    []string
+		cfg     *queryConfig
+		wantErr string
+	}{
+		"replay": {
+			cfg: &queryConfig{output: constants.OutputFormatTable, replay: "session.jsonl"},
+		},
+		"replay with query": {
+			args:    []string{"SELECT 1"},
+			cfg:     &queryConfig{output: constants.OutputFormatTable, replay: "session.jsonl"},
+			wantErr: "cannot pass a query",
+		},
+		"replay with query args": {
+			cfg:     &queryConfig{output: constants.OutputFormatTable, replay: "session.jsonl", args: []string{"1"}},
+			wantErr: "cannot pass query args",
+		},
+		"replay with watch": {
+			cfg:     &queryConfig{output: constants.OutputFormatTable, replay: "session.jsonl", watch: "30s"},
+			wantErr: "cannot watch",
+		},
+		"replay with export": {
+			cfg:     &queryConfig{output: constants.OutputFormatTable, replay: "session.jsonl", export: []string{"csv"}},
+			wantErr: "cannot export or share",
+		},
+		"replay with row assertion": {
+			cfg:     &queryConfig{output: constants.OutputFormatTable, replay: "session.jsonl", failIfRows: true},
+			wantErr: "row count assertions are not supported",
+		},
+		"replay with from snapshot": {
+			cfg:     &queryConfig{output: constants.OutputFormatTable, replay: "session.jsonl", fromSnapshot: "result.sps"},
+			wantErr: "only one of --from-snapshot and --replay",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := validateQueryArgs(ctx, tc.args, tc.cfg)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
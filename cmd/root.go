@@ -107,6 +107,7 @@ func AddCommands() {
 		generateCompletionScriptsCmd(),
 		pluginManagerCmd(),
 		loginCmd(),
+		snapshotCmd(),
 	)
 }
 
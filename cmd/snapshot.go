@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/thediveo/enumflag/v2"
+	"github.com/turbot/go-kit/helpers"
+	pconstants "github.com/turbot/pipe-fittings/v2/constants"
+	"github.com/turbot/pipe-fittings/v2/utils"
+	"github.com/turbot/steampipe/v2/pkg/cmdconfig"
+	"github.com/turbot/steampipe/v2/pkg/constants"
+	"github.com/turbot/steampipe/v2/pkg/display"
+	"github.com/turbot/steampipe/v2/pkg/error_helpers"
+	"github.com/turbot/steampipe/v2/pkg/snapshot"
+)
+
+// variable used to assign the output mode flag of snapshot diff
+var snapshotDiffOutputMode = constants.SnapshotDiffOutputModeTable
+
+func snapshotCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "snapshot [command]",
+		Args:  cobra.NoArgs,
+		Short: "Steampipe snapshot management",
+		Long: `Steampipe snapshot management.
+
+Work with query snapshot files, as created by 'steampipe query --export sps'
+or 'steampipe query --output snapshot'.`,
+	}
+
+	cmd.AddCommand(snapshotDiffCmd())
+	cmd.Flags().BoolP(pconstants.ArgHelp, "h", false, "Help for snapshot")
+	return cmd
+}
+
+// handler for snapshot diff
+func snapshotDiffCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "diff <previous> <current>",
+		Args:  cobra.ExactArgs(2),
+		Run:   runSnapshotDiffCmd,
+		Short: "Compare the query results of two snapshots",
+		Long: `Compare the query results of two query snapshots.
+
+Rows are matched using the values of the key columns and reported as added,
+removed or changed. If no key columns are given, rows are matched using all
+column values.
+
+Examples:
+
+  # Show the rows which have changed between two snapshots
+  steampipe snapshot diff monday.sps tuesday.sps --key instance_id
+
+  # Write the diff as a snapshot, with the status of each row in the _diff_status column
+  steampipe snapshot diff monday.sps tuesday.sps --key instance_id --output snapshot > drift.sps`,
+	}
+
+	cmdconfig.
+		OnCmd(cmd).
+		AddBoolFlag(pconstants.ArgHelp, false, "Help for snapshot diff", cmdconfig.FlagOptions.WithShortHand("h")).
+		AddBoolFlag(pconstants.ArgHeader, true, "Include column headers in table output").
+		AddStringSliceFlag(constants.ArgKey, nil, "Columns used to match rows between the snapshots (comma-separated)").
+		AddVarFlag(enumflag.New(&snapshotDiffOutputMode, pconstants.ArgOutput, constants.SnapshotDiffOutputModeIds, enumflag.EnumCaseInsensitive),
+			pconstants.ArgOutput,
+			fmt.Sprintf("Output format; one of: %s", strings.Join(constants.FlagValues(constants.SnapshotDiffOutputModeIds), ", ")))
+
+	return cmd
+}
+
+func runSnapshotDiffCmd(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	utils.LogTime("runSnapshotDiffCmd start")
+	defer func() {
+		utils.LogTime("runSnapshotDiffCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	startTime := time.Now()
+	diff, err := diffSnapshotFiles(args[0], args[1], viper.GetStringSlice(constants.ArgKey))
+	if err != nil {
+		error_helpers.ShowError(ctx, err)
+		exitCode = snapshotDiffExitCode(err)
+		return
+	}
+
+	if err := writeSnapshotDiff(os.Stdout, diff, viper.GetString(pconstants.ArgOutput), viper.GetBool(pconstants.ArgHeader), startTime); err != nil {
+		error_helpers.ShowError(ctx, err)
+		exitCode = snapshotDiffExitCode(err)
+	}
+}
+
+// snapshotDiffExitCode returns the exit code for an error diffing snapshots - file system errors (e.g. an unreadable
+// snapshot file or a failed write) are file system access failures, other errors (e.g. an invalid snapshot
+// or key column) are input errors
+func snapshotDiffExitCode(err error) int {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return constants.ExitCodeFileSystemAccessFailure
+	}
+	return constants.ExitCodeInsufficientOrWrongInputs
+}
+
+func diffSnapshotFiles(previousPath, currentPath string, keyColumns []string) (*snapshot.SnapshotDiff, error) {
+	previous, err := snapshot.Load(previousPath)
+	if err != nil {
+		return nil, err
+	}
+	current, err := snapshot.Load(currentPath)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot.DiffSnapshots(previous, current, keyColumns)
+}
+
+// writeSnapshotDiff writes the diff in the given output format
+func writeSnapshotDiff(w io.Writer, diff *snapshot.SnapshotDiff, outputFormat string, showHeader bool, startTime time.Time) error {
+	switch outputFormat {
+	case constants.OutputFormatJSON:
+		return writeIndentedJSON(w, diff)
+	case constants.OutputFormatSnapshot, constants.OutputFormatSpSnapshotShort:
+		snap, err := diff.ToSnapshot(startTime)
+		if err != nil {
+			return err
+		}
+		return writeIndentedJSON(w, snap)
+	default:
+		table, err := display.BuildRowDiffTable(diff.Columns, diff.Rows, showHeader)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s%s\n", table, display.RowDiffSummaryString(diff.Summary))
+		return err
+	}
+}
+
+func writeIndentedJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(v)
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pqueryresult "github.com/turbot/pipe-fittings/v2/queryresult"
+	"github.com/turbot/steampipe/v2/pkg/constants"
+	"github.com/turbot/steampipe/v2/pkg/snapshot"
+)
+
+func testSnapshotDiff() *snapshot.SnapshotDiff {
+	rows := []snapshot.RowDiff{
+		{Status: snapshot.RowAdded, Row: map[string]any{"id": float64(2)}},
+		{Status: snapshot.RowRemoved, Row: map[string]any{"id": float64(1)}},
+	}
+	return &snapshot.SnapshotDiff{
+		Columns: []*pqueryresult.ColumnDef{{Name: "id", DataType: "INT8"}},
+		Rows:    rows,
+		Summary: snapshot.SummariseRowDiffs(rows),
+	}
+}
+
+func TestWriteSnapshotDiff_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeSnapshotDiff(&buf, testSnapshotDiff(), constants.OutputFormatJSON, true, time.Now()))
+
+	var res struct {
+		Rows []struct {
+			Status string         `json:"status"`
+			Row    map[string]any `json:"row"`
+		} `json:"rows"`
+		Summary snapshot.RowDiffSummary `json:"summary"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &res))
+	require.Len(t, res.Rows, 2)
+	assert.Equal(t, "added", res.Rows[0].Status)
+	assert.Equal(t, "removed", res.Rows[1].Status)
+	assert.Equal(t, snapshot.RowDiffSummary{Added: 1, Removed: 1}, res.Summary)
+}
+
+func TestWriteSnapshotDiff_Table(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeSnapshotDiff(&buf, testSnapshotDiff(), constants.OutputFormatTable, true, time.Now()))
+
+	out := buf.String()
+	assert.Contains(t, out, "| + |")
+	assert.Contains(t, out, "| - |")
+	assert.Contains(t, out, "1 row")
+}
+
+func TestSnapshotDiffExitCode(t *testing.T) {
+	_, err := diffSnapshotFiles(filepath.Join(t.TempDir(), "missing.json"), filepath.Join(t.TempDir(), "missing.json"), nil)
+	require.Error(t, err)
+	assert.Equal(t, constants.ExitCodeFileSystemAccessFailure, snapshotDiffExitCode(err))
+
+	invalid := filepath.Join(t.TempDir(), "invalid.json")
+	require.NoError(t, os.WriteFile(invalid, []byte("not a snapshot"), 0600))
+	_, err = diffSnapshotFiles(invalid, invalid, nil)
+	require.Error(t, err)
+	assert.Equal(t, constants.ExitCodeInsufficientOrWrongInputs, snapshotDiffExitCode(err))
+}
@@ -114,6 +114,10 @@ func setBaseDefaults() error {
 		// workspace profile
 		pconstants.ArgAutoComplete: true,
 
+		// interactive prompt
+		constants.ArgKeyBindMode:  constants.KeyBindModeEmacs,
+		constants.ArgResultViewer: false,
+
 		// from global database options
 		pconstants.ArgDatabasePort:         constants.DatabaseDefaultPort,
 		pconstants.ArgDatabaseStartTimeout: constants.DBStartTimeout.Seconds(),
@@ -184,6 +188,8 @@ func setDefaultsFromEnv() {
 		constants.EnvMemoryMaxMb:           {[]string{pconstants.ArgMemoryMaxMb}, Int},
 		constants.EnvMemoryMaxMbPlugin:     {[]string{pconstants.ArgMemoryMaxMbPlugin}, Int},
 		constants.EnvPluginStartTimeout:    {[]string{pconstants.ArgPluginStartTimeout}, Int},
+		constants.EnvKeyBindMode:           {[]string{constants.ArgKeyBindMode}, String},
+		constants.EnvResultViewer:          {[]string{constants.ArgResultViewer}, Bool},
 
 		// we need this value to go into different locations
 		constants.EnvCacheEnabled: {[]string{
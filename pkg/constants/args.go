@@ -0,0 +1,35 @@
+package constants
+
+// steampipe specific command line args - shared args are defined in pipe-fittings
+const (
+	ArgOnError      = "on-error"
+	ArgWatch        = "watch"
+	ArgWatchKey     = "watch-key"
+	ArgKey          = "key"
+	ArgFromSnapshot = "from-snapshot"
+	ArgFailIfRows   = "fail-if-rows"
+	ArgExpectRows   = "expect-rows"
+	ArgTimingFile   = "timing-file"
+	ArgKeyBindMode  = "key-bind-mode"
+	ArgKeyBindings  = "key-bindings"
+	ArgResultViewer = "result-viewer"
+	ArgReplay       = "replay"
+)
+
+// values for the on-error arg
+const (
+	OnErrorContinue = "continue"
+	OnErrorStop     = "stop"
+)
+
+// values for the timing arg, in addition to off, on and verbose - display the timing as json
+const (
+	TimingJSON        = "json"
+	TimingJSONVerbose = "json-verbose"
+)
+
+// values for the key-bind-mode arg
+const (
+	KeyBindModeEmacs = "emacs"
+	KeyBindModeVi    = "vi"
+)
@@ -10,4 +10,5 @@ const (
 	ConfigKeyServerSearchPath            = "server-search-path"
 	ConfigKeyServerSearchPathPrefix      = "server-search-path-prefix"
 	ConfigKeyBypassHomeDirModfileWarning = "bypass-home-dir-modfile-warning"
+	ConfigKeyOutputTemplate              = "output-template"
 )
@@ -24,6 +24,9 @@ const DefaultConnectionConfigContent = `
 #   telemetry    = "info"  		# info, none
 #   log_level    = "info"  		# trace, debug, info, warn, error
 #   memory_max_mb    = "1024"	# the maximum memory to allow the CLI process in MB 
+#   key_bind_mode = "emacs"	# emacs, vi - the key bindings of the interactive prompt
+#   key_bindings  = { execute = "enter", newline = "alt+enter", clear = "ctrl+c", history_search = "ctrl+r" }
+#   result_viewer = false	# true, false - show interactive query results which do not fit on the screen in a full-screen viewer
 # }
 
 # options "plugin" {
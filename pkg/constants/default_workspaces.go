@@ -23,7 +23,7 @@ const DefaultWorkspaceContent = `
 #   introspection      = false
 #   input              = true
 #   progress           = true
-#   theme              = "dark"  # light, dark, plain 
+#   theme              = "dark"  # light, dark, plain, a theme file in ~/.steampipe/config/themes or a chroma style
 #   cache              = true
 #   cache_ttl          = 300
 # 
@@ -43,4 +43,7 @@ const (
 	EnvMemoryMaxMbPlugin = "STEAMPIPE_PLUGIN_MEMORY_MAX_MB"
 
 	EnvPluginStartTimeout = "STEAMPIPE_PLUGIN_START_TIMEOUT"
+
+	EnvKeyBindMode  = "STEAMPIPE_KEY_BIND_MODE"
+	EnvResultViewer = "STEAMPIPE_RESULT_VIEWER"
 )
@@ -14,6 +14,8 @@ const (
 	ExitCodeServiceStartupFailure       = 32  // service - start failed
 	ExitCodeServiceStopFailure          = 33  // service - stop failed
 	ExitCodeQueryExecutionFailed        = 41  // query - 1 or more queries failed - change in behavior(previously the exitCode used to be the number of queries that failed)
+	ExitCodeQueryAssertionFailed        = 42  // query - 1 or more queries returned an unexpected number of rows
+	ExitCodeReplayDifferences           = 43  // query - 1 or more replayed queries returned a different result to the recording
 	ExitCodeLoginCloudConnectionFailed  = 51  // login - connecting to cloud failed
 	ExitCodeModInitFailed               = 61  // mod - init failed
 	ExitCodeModInstallFailed            = 62  // mod - install failed
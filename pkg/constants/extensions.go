@@ -7,6 +7,11 @@ var AutoVariablesExtensions = []string{".auto.spvars"}
 const (
 	ConfigExtension      = ".spc"
 	SnapshotExtension    = ".sps"
+	CsvExtension         = ".csv"
+	JsonExtension        = ".json"
+	JsonlExtension       = ".jsonl"
+	ParquetExtension     = ".parquet"
+	SqliteExtension      = ".sqlite"
 	TokenExtension       = ".tptt"
 	LegacyTokenExtension = ".sptt"
 )
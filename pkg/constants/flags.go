@@ -14,6 +14,7 @@ const (
 	QueryOutputModeSnapshot
 	QueryOutputModeSnapshotShort
 	QueryOutputModeTable
+	QueryOutputModeTemplate
 )
 
 // steampipe snapshot
@@ -26,6 +27,7 @@ var QueryOutputModeIds = map[QueryOutputMode][]string{
 	QueryOutputModeSnapshot:      {constants.OutputFormatSnapshot},
 	QueryOutputModeSnapshotShort: {OutputFormatSpSnapshotShort},
 	QueryOutputModeTable:         {constants.OutputFormatTable},
+	QueryOutputModeTemplate:      {OutputFormatTemplate},
 }
 
 type QueryTimingMode enumflag.Flag
@@ -34,15 +36,19 @@ const (
 	QueryTimingModeOff QueryTimingMode = iota
 	QueryTimingModeOn
 	QueryTimingModeVerbose
+	QueryTimingModeJSON
+	QueryTimingModeJSONVerbose
 	// support legacy values
 	QueryTimingModeTrue
 	QueryTimingModeFalse
 )
 
 var QueryTimingModeIds = map[QueryTimingMode][]string{
-	QueryTimingModeOff:     {constants.ArgOff},
-	QueryTimingModeOn:      {constants.ArgOn},
-	QueryTimingModeVerbose: {constants.ArgVerbose},
+	QueryTimingModeOff:         {constants.ArgOff},
+	QueryTimingModeOn:          {constants.ArgOn},
+	QueryTimingModeVerbose:     {constants.ArgVerbose},
+	QueryTimingModeJSON:        {TimingJSON},
+	QueryTimingModeJSONVerbose: {TimingJSONVerbose},
 	// support legacy values
 	QueryTimingModeTrue:  {"true"},
 	QueryTimingModeFalse: {"false"},
@@ -52,10 +58,40 @@ var QueryTimingValueLookup = map[string]struct{}{
 	constants.ArgOff:     {},
 	constants.ArgOn:      {},
 	constants.ArgVerbose: {},
+	TimingJSON:           {},
+	TimingJSONVerbose:    {},
 	"true":               {},
 	"false":              {},
 }
 
+type QueryOnErrorMode enumflag.Flag
+
+const (
+	QueryOnErrorModeContinue QueryOnErrorMode = iota
+	QueryOnErrorModeStop
+)
+
+var QueryOnErrorModeIds = map[QueryOnErrorMode][]string{
+	QueryOnErrorModeContinue: {OnErrorContinue},
+	QueryOnErrorModeStop:     {OnErrorStop},
+}
+
+type SnapshotDiffOutputMode enumflag.Flag
+
+const (
+	SnapshotDiffOutputModeTable SnapshotDiffOutputMode = iota
+	SnapshotDiffOutputModeJSON
+	SnapshotDiffOutputModeSnapshot
+	SnapshotDiffOutputModeSnapshotShort
+)
+
+var SnapshotDiffOutputModeIds = map[SnapshotDiffOutputMode][]string{
+	SnapshotDiffOutputModeTable:         {constants.OutputFormatTable},
+	SnapshotDiffOutputModeJSON:          {constants.OutputFormatJSON},
+	SnapshotDiffOutputModeSnapshot:      {constants.OutputFormatSnapshot},
+	SnapshotDiffOutputModeSnapshotShort: {OutputFormatSpSnapshotShort},
+}
+
 type CheckTimingMode enumflag.Flag
 
 const (
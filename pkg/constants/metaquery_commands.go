@@ -20,4 +20,17 @@ const (
 	CmdCache            = ".cache"              // cache control
 	CmdCacheTtl         = ".cache_ttl"          // set cache ttl
 	CmdAutoComplete     = ".autocomplete"       // enable or disable auto complete
+	CmdExplain          = ".explain"            // show the query plan and the quals pushed down to plugins
+	CmdHistory          = ".history"            // show the query history
+	CmdSave             = ".save"               // save a query by name
+	CmdRun              = ".run"                // run a saved query
+	CmdQueries          = ".queries"            // list saved queries
+	CmdOut              = ".out"                // write query results to a file
+	CmdTee              = ".tee"                // write query results to a file and the screen
+	CmdEdit             = ".edit"               // edit the query in an external editor
+	CmdExport           = ".export"             // export the last query result
+	CmdSource           = ".source"             // execute the sql and metaqueries in a file
+	CmdTheme            = ".theme"              // show or set the colour theme
+	CmdViewer           = ".viewer"             // enable or disable the full-screen result viewer
+	CmdRecord           = ".record"             // record the session to a transcript file
 )
@@ -3,6 +3,9 @@ package constants
 const (
 	OutputFormatCSV           = "csv"
 	OutputFormatJSON          = "json"
+	OutputFormatJSONL         = "jsonl"
+	OutputFormatParquet       = "parquet"
+	OutputFormatSqlite        = "sqlite"
 	OutputFormatTable         = "table"
 	OutputFormatLine          = "line"
 	OutputFormatNone          = "none"
@@ -10,4 +13,5 @@ const (
 	OutputFormatBrief         = "brief"
 	OutputFormatSnapshot      = "snapshot"
 	OutputFormatSnapshotShort = "sps"
+	OutputFormatTemplate      = "template"
 )
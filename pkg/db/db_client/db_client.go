@@ -143,14 +143,17 @@ func (c *DbClient) shouldFetchTiming() bool {
 	if c.disableTiming.Load() {
 		return false
 	}
-	// only fetch timing if timing flag is set, or output is JSON
+	// only fetch timing if timing flag is set, or output is JSON or a template (which are passed the timing)
 	return (viper.GetString(pconstants.ArgTiming) != pconstants.ArgOff) ||
-		(viper.GetString(pconstants.ArgOutput) == constants.OutputFormatJSON)
+		(viper.GetString(pconstants.ArgOutput) == constants.OutputFormatJSON) ||
+		(viper.GetString(pconstants.ArgOutput) == constants.OutputFormatTemplate)
 
 }
 func (c *DbClient) shouldFetchVerboseTiming() bool {
 	return (viper.GetString(pconstants.ArgTiming) == pconstants.ArgVerbose) ||
-		(viper.GetString(pconstants.ArgOutput) == constants.OutputFormatJSON)
+		(viper.GetString(pconstants.ArgTiming) == constants.TimingJSONVerbose) ||
+		(viper.GetString(pconstants.ArgOutput) == constants.OutputFormatJSON) ||
+		(viper.GetString(pconstants.ArgOutput) == constants.OutputFormatTemplate)
 }
 
 // lockSessions acquires the sessionsMutex and tracks ownership for tryLock compatibility.
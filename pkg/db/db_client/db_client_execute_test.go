@@ -1,12 +1,19 @@
 package db_client
 
 import (
+	"context"
+	"database/sql"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	pqueryresult "github.com/turbot/pipe-fittings/v2/queryresult"
+	"github.com/turbot/steampipe/v2/pkg/export"
+	"github.com/turbot/steampipe/v2/pkg/snapshot"
 )
 
 // TestTimestamptzTextFormatImplemented verifies that the timestamptz wire protocol fix is in place.
@@ -157,3 +164,36 @@ func TestTimestamptzFormatDoesNotAffectOtherTypes(t *testing.T) {
 	assert.Equal(t, 1, oidCount,
 		"QueryResultFormatsByOID should have exactly one entry (TimestamptzOID)")
 }
+
+// TestPopulateRow_ArraysExport verifies that the array values returned by populateRow can be exported -
+// text arrays are joined with commas, so they are exported as strings
+func TestPopulateRow_ArraysExport(t *testing.T) {
+	cols := []*pqueryresult.ColumnDef{
+		{Name: "aliases", DataType: "_TEXT"},
+		{Name: "ports", DataType: "_INT4"},
+		{Name: "days", DataType: "_DATE"},
+	}
+	day := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	row, err := populateRow([]any{
+		[]any{"a", "b,c"},
+		[]any{int32(80), int32(443)},
+		[]any{day, day.AddDate(0, 0, 1)},
+	}, cols)
+	require.NoError(t, err)
+
+	rows := make(chan map[string]any, 1)
+	rows <- snapshot.RowData(row, cols)
+	close(rows)
+	target := filepath.Join(t.TempDir(), "out.sqlite")
+	stream := &export.RowStream{Columns: cols, SQL: "select 1", Rows: rows}
+	require.NoError(t, (&export.SqliteExporter{}).ExportStream(context.Background(), stream, target))
+
+	db, err := sql.Open("sqlite", target)
+	require.NoError(t, err)
+	defer db.Close()
+	var aliases, ports, days string
+	require.NoError(t, db.QueryRow(`select aliases, ports, days from query_1`).Scan(&aliases, &ports, &days))
+	assert.Equal(t, "a,b,c", aliases)
+	assert.Equal(t, "[80,443]", ports)
+	assert.Equal(t, `["2024-01-02","2024-01-03"]`, days)
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/turbot/steampipe/v2/pkg/constants"
+	"github.com/turbot/steampipe/v2/pkg/query/queryresult"
 )
 
 // SetCacheTtl set the cache ttl on the client
@@ -22,6 +23,38 @@ func CacheClear(ctx context.Context, connection *pgx.Conn) error {
 	return executeCacheSetFunction(ctx, "clear", connection)
 }
 
+// ConnectionCacheClear clears the cache of a single connection
+func ConnectionCacheClear(ctx context.Context, connectionName string, connection *pgx.Conn) error {
+	return ExecuteSystemClientCall(ctx, connection, func(ctx context.Context, tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, fmt.Sprintf(
+			"select %s.%s($1)",
+			constants.InternalSchema,
+			constants.FunctionConnectionCacheClear,
+		), connectionName)
+		return err
+	})
+}
+
+// LoadConnectionScanSummaries reads the scan metadata of the last query executed on the connection,
+// totalled by connection and by whether the scans were served from the cache
+func LoadConnectionScanSummaries(ctx context.Context, connection *pgx.Conn) ([]queryresult.ConnectionScanSummary, error) {
+	var summaries []queryresult.ConnectionScanSummary
+	err := ExecuteSystemClientCall(ctx, connection, func(ctx context.Context, tx pgx.Tx) error {
+		query := fmt.Sprintf(`select connection,
+cache_hit,
+coalesce(sum(rows_fetched), 0)::bigint as rows_fetched,
+count(*) as scan_count
+from %s.%s group by connection, cache_hit`, constants.InternalSchema, constants.ForeignTableScanMetadata)
+		rows, err := tx.Query(ctx, query)
+		if err != nil {
+			return err
+		}
+		summaries, err = pgx.CollectRows(rows, pgx.RowToStructByName[queryresult.ConnectionScanSummary])
+		return err
+	})
+	return summaries, err
+}
+
 // SetCacheEnabled enables/disables the cache
 func SetCacheEnabled(ctx context.Context, enabled bool, connection *pgx.Conn) error {
 	value := "off"
@@ -0,0 +1,119 @@
+package display
+
+import (
+	"io"
+	"os"
+	"sync"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/turbot/steampipe-plugin-sdk/v5/sperr"
+)
+
+// outputRedirect is the file which interactive query results are written to
+type outputRedirect struct {
+	path string
+	file *os.File
+	// should the results also be shown on screen
+	tee bool
+}
+
+var (
+	redirect     *outputRedirect
+	redirectLock sync.Mutex
+)
+
+// RedirectOutput writes the output of subsequent query results to the file at path, which is created or truncated.
+// If tee is set, the results are also shown on screen. Any existing redirection is stopped
+func RedirectOutput(path string, tee bool) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return sperr.WrapWithMessage(err, "failed to open output file")
+	}
+
+	redirectLock.Lock()
+	defer redirectLock.Unlock()
+	if err := closeRedirect(); err != nil {
+		file.Close()
+		return err
+	}
+	redirect = &outputRedirect{path: path, file: file, tee: tee}
+	return nil
+}
+
+// StopOutputRedirect stops redirecting query results, closing the output file
+func StopOutputRedirect() error {
+	redirectLock.Lock()
+	defer redirectLock.Unlock()
+	return closeRedirect()
+}
+
+// OutputRedirectTarget returns the path of the file query results are written to, and whether they are also shown on screen.
+// The path is empty if the output is not redirected
+func OutputRedirectTarget() (string, bool) {
+	redirectLock.Lock()
+	defer redirectLock.Unlock()
+	if redirect == nil {
+		return "", false
+	}
+	return redirect.path, redirect.tee
+}
+
+// WithOutputRedirect calls show, writing anything it writes to stdout to the output file if the output is redirected.
+// The display code writes directly to stdout, so stdout is replaced by a pipe while show executes.
+// The table colours of the theme are not written to the output file
+func WithOutputRedirect(show func()) error {
+	redirectLock.Lock()
+	defer redirectLock.Unlock()
+	if redirect == nil {
+		show()
+		return nil
+	}
+	table.StyleDefault.Color = table.ColorOptions{}
+	defer func() { table.StyleDefault.Color = tableColors }()
+
+	var w io.Writer = redirect.file
+	if redirect.tee {
+		w = io.MultiWriter(redirect.file, os.Stdout)
+	}
+	pipeReader, pipeWriter, err := os.Pipe()
+	if err != nil {
+		return sperr.WrapWithMessage(err, "failed to redirect output")
+	}
+	copyErr := make(chan error, 1)
+	go func() {
+		_, err := io.Copy(w, pipeReader)
+		if err != nil {
+			// keep reading, so writes to the pipe do not block
+			_, _ = io.Copy(io.Discard, pipeReader)
+		}
+		pipeReader.Close()
+		copyErr <- err
+	}()
+
+	stdout := os.Stdout
+	func() {
+		// restore stdout even if show panics
+		defer func() { os.Stdout = stdout }()
+		os.Stdout = pipeWriter
+		show()
+	}()
+	pipeWriter.Close()
+
+	if err := <-copyErr; err != nil {
+		return sperr.WrapWithMessage(err, "failed to write output to %s", redirect.path)
+	}
+	return nil
+}
+
+// closeRedirect closes the current output file, if there is one - the lock must be held
+func closeRedirect() error {
+	if redirect == nil {
+		return nil
+	}
+	err := redirect.file.Close()
+	redirect = nil
+	if err != nil {
+		return sperr.WrapWithMessage(err, "failed to close output file")
+	}
+	return nil
+}
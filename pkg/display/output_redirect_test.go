@@ -0,0 +1,63 @@
+package display
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captureStdout returns what f writes to stdout
+func captureStdout(t *testing.T, f func()) string {
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	stdout := os.Stdout
+	os.Stdout = w
+	f()
+	os.Stdout = stdout
+	w.Close()
+	out, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(out)
+}
+
+func TestWithOutputRedirect(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.txt")
+	show := func() { fmt.Println("result") }
+
+	// not redirected
+	assert.Equal(t, "result\n", captureStdout(t, func() { require.NoError(t, WithOutputRedirect(show)) }))
+
+	// redirected to the file only
+	require.NoError(t, RedirectOutput(path, false))
+	target, tee := OutputRedirectTarget()
+	assert.Equal(t, path, target)
+	assert.False(t, tee)
+	assert.Equal(t, "", captureStdout(t, func() { require.NoError(t, WithOutputRedirect(show)) }))
+	assert.Equal(t, "", captureStdout(t, func() { require.NoError(t, WithOutputRedirect(show)) }))
+	require.NoError(t, StopOutputRedirect())
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "result\nresult\n", string(contents))
+
+	// redirecting to the same file again replaces it, and tee also shows the result
+	require.NoError(t, RedirectOutput(path, true))
+	assert.Equal(t, "result\n", captureStdout(t, func() { require.NoError(t, WithOutputRedirect(show)) }))
+	require.NoError(t, StopOutputRedirect())
+	contents, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "result\n", string(contents))
+
+	target, _ = OutputRedirectTarget()
+	assert.Empty(t, target)
+}
+
+func TestRedirectOutput_InvalidPath(t *testing.T) {
+	assert.Error(t, RedirectOutput(filepath.Join(t.TempDir(), "missing", "out.txt"), false))
+	target, _ := OutputRedirectTarget()
+	assert.Empty(t, target)
+}
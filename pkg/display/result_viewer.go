@@ -0,0 +1,604 @@
+package display
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/turbot/pipe-fittings/v2/utils"
+)
+
+// the maximum width of a column in the viewer table - wider values are truncated, and shown in full in the row detail view
+const maxViewerColumnWidth = 60
+
+// the number of screen lines used by the header, the header separator and the status line
+const viewerChromeLines = 3
+
+// the separator between the columns of the viewer table
+const viewerColumnSeparator = " │ "
+
+// terminal escape codes used to style the viewer
+const (
+	styleReset   = "\x1b[0m"
+	styleBold    = "\x1b[1m"
+	styleReverse = "\x1b[7m"
+	styleUnder   = "\x1b[4m"
+)
+
+type viewerMode int
+
+const (
+	viewerModeTable viewerMode = iota
+	viewerModeDetail
+	viewerModeSearch
+	viewerModeHelp
+)
+
+// the names of the special keys the viewer handles, as returned by parseViewerKey - other keys are the typed text
+const (
+	keyUp        = "<up>"
+	keyDown      = "<down>"
+	keyLeft      = "<left>"
+	keyRight     = "<right>"
+	keyPageUp    = "<pgup>"
+	keyPageDown  = "<pgdn>"
+	keyHome      = "<home>"
+	keyEnd       = "<end>"
+	keyEnter     = "<enter>"
+	keyEscape    = "<esc>"
+	keyBackspace = "<backspace>"
+	keyCtrlC     = "<ctrl+c>"
+	keyUnknown   = "<unknown>"
+)
+
+var viewerHelp = []string{
+	"Result viewer keys",
+	"",
+	"  ↑ ↓ j k         move the selected row",
+	"  PgUp PgDn b ␣   page up and down",
+	"  Home End g G    first and last row",
+	"  ← → h l         select a column, panning the table",
+	"  s               sort by the selected column - ascending, descending, then unsorted",
+	"  /               search the rows",
+	"  n N             next and previous search match",
+	"  Enter           show the selected row in detail",
+	"  q Esc           close the detail view, or exit the viewer",
+	"",
+	"Press any key to return",
+}
+
+// resultViewer is the state of the full-screen viewer of a query result.
+// It handles key presses and renders the screen, but does not read or write the terminal
+type resultViewer struct {
+	headers []string
+	// the row data, used to sort by value
+	data [][]any
+	// the display value of each cell
+	cells [][]string
+	// the display width of each column
+	widths []int
+
+	// the indices of the rows, in display order
+	order []int
+	// the position in order of the selected row, and the first row shown
+	cursor int
+	top    int
+	// the selected column, and the first column shown
+	col     int
+	leftCol int
+	// the column the rows are sorted by, or -1 if the rows are unsorted
+	sortCol  int
+	sortDesc bool
+
+	mode viewerMode
+	// the search text, and the text being entered in search mode
+	search string
+	input  string
+	// the first line shown in the detail view
+	detailTop int
+	// a message shown in the status line until the next key press
+	message string
+
+	width  int
+	height int
+}
+
+func newResultViewer(headers []string, data [][]any, cells [][]string) *resultViewer {
+	v := &resultViewer{
+		headers: headers,
+		data:    data,
+		cells:   cells,
+		sortCol: -1,
+		width:   80,
+		height:  24,
+	}
+	v.widths = make([]int, len(headers))
+	for i, h := range headers {
+		v.widths[i] = runewidth.StringWidth(h)
+	}
+	for _, row := range cells {
+		for i, cell := range row {
+			v.widths[i] = max(v.widths[i], min(maxViewerColumnWidth, runewidth.StringWidth(tableCellValue(cell))))
+		}
+	}
+	// leave room for the sort marker
+	for i := range v.widths {
+		v.widths[i] += 2
+	}
+	v.order = make([]int, len(cells))
+	for i := range v.order {
+		v.order[i] = i
+	}
+	return v
+}
+
+// resize sets the size of the screen
+func (v *resultViewer) resize(width, height int) {
+	v.width = max(width, 20)
+	v.height = max(height, viewerChromeLines+1)
+	v.scrollToCursor()
+	v.panToColumn()
+}
+
+// pageSize returns the number of rows shown on a screen
+func (v *resultViewer) pageSize() int {
+	return v.height - viewerChromeLines
+}
+
+// handleKey updates the state for a key press, returning whether the viewer should exit
+func (v *resultViewer) handleKey(key string) bool {
+	v.message = ""
+	if key == keyCtrlC {
+		return true
+	}
+	switch v.mode {
+	case viewerModeSearch:
+		v.handleSearchKey(key)
+	case viewerModeDetail:
+		v.handleDetailKey(key)
+	case viewerModeHelp:
+		v.mode = viewerModeTable
+	default:
+		return v.handleTableKey(key)
+	}
+	return false
+}
+
+func (v *resultViewer) handleTableKey(key string) bool {
+	switch key {
+	case "q", keyEscape:
+		return true
+	case keyUp, "k":
+		v.moveCursor(-1)
+	case keyDown, "j":
+		v.moveCursor(1)
+	case keyPageUp, "b":
+		v.moveCursor(-v.pageSize())
+	case keyPageDown, " ":
+		v.moveCursor(v.pageSize())
+	case keyHome, "g":
+		v.moveCursor(-len(v.order))
+	case keyEnd, "G":
+		v.moveCursor(len(v.order))
+	case keyLeft, "h":
+		v.moveColumn(-1)
+	case keyRight, "l":
+		v.moveColumn(1)
+	case "s":
+		v.toggleSort()
+	case "/":
+		v.mode = viewerModeSearch
+		v.input = ""
+	case "n":
+		v.findNext(1)
+	case "N":
+		v.findNext(-1)
+	case keyEnter:
+		if len(v.order) > 0 {
+			v.mode = viewerModeDetail
+			v.detailTop = 0
+		}
+	case "?":
+		v.mode = viewerModeHelp
+	}
+	return false
+}
+
+func (v *resultViewer) handleSearchKey(key string) {
+	switch key {
+	case keyEnter:
+		v.mode = viewerModeTable
+		if v.input != "" {
+			v.search = v.input
+		}
+		// include the selected row, so a new search finds a match on the selected row
+		v.find(v.cursor, 1)
+	case keyEscape:
+		v.mode = viewerModeTable
+	case keyBackspace:
+		if r := []rune(v.input); len(r) > 0 {
+			v.input = string(r[:len(r)-1])
+		}
+	default:
+		// add typed (or pasted) text, ignoring other special keys
+		if !strings.HasPrefix(key, "<") || !strings.HasSuffix(key, ">") || len(key) == 1 {
+			v.input += strings.Map(func(r rune) rune {
+				if unicode.IsPrint(r) {
+					return r
+				}
+				return -1
+			}, key)
+		}
+	}
+}
+
+func (v *resultViewer) handleDetailKey(key string) {
+	lines := len(v.detailLines())
+	switch key {
+	case "q", keyEscape, keyEnter, keyBackspace:
+		v.mode = viewerModeTable
+	case keyUp, "k":
+		v.detailTop--
+	case keyDown, "j":
+		v.detailTop++
+	case keyPageUp, "b":
+		v.detailTop -= v.pageSize()
+	case keyPageDown, " ":
+		v.detailTop += v.pageSize()
+	// show the previous and next rows
+	case keyLeft, "h":
+		v.moveCursor(-1)
+		v.detailTop = 0
+	case keyRight, "l":
+		v.moveCursor(1)
+		v.detailTop = 0
+	}
+	v.detailTop = max(0, min(v.detailTop, lines-v.pageSize()))
+}
+
+func (v *resultViewer) moveCursor(delta int) {
+	v.cursor = max(0, min(v.cursor+delta, len(v.order)-1))
+	v.scrollToCursor()
+}
+
+// scrollToCursor scrolls the table so the selected row is shown
+func (v *resultViewer) scrollToCursor() {
+	if v.cursor < v.top {
+		v.top = v.cursor
+	}
+	if v.cursor >= v.top+v.pageSize() {
+		v.top = v.cursor - v.pageSize() + 1
+	}
+	v.top = max(0, min(v.top, len(v.order)-v.pageSize()))
+}
+
+func (v *resultViewer) moveColumn(delta int) {
+	v.col = max(0, min(v.col+delta, len(v.headers)-1))
+	v.panToColumn()
+}
+
+// panToColumn pans the table so the selected column is shown
+func (v *resultViewer) panToColumn() {
+	if v.col < v.leftCol {
+		v.leftCol = v.col
+	}
+	for v.leftCol < v.col && v.columnsWidth(v.leftCol, v.col) > v.width {
+		v.leftCol++
+	}
+}
+
+// columnsWidth returns the screen width of the columns from first to last, inclusive
+func (v *resultViewer) columnsWidth(first, last int) int {
+	width := 0
+	for i := first; i <= last; i++ {
+		width += v.widths[i]
+	}
+	return width + (last-first)*runewidth.StringWidth(viewerColumnSeparator)
+}
+
+// toggleSort sorts by the selected column - ascending, then descending, then in the original order
+func (v *resultViewer) toggleSort() {
+	if len(v.headers) == 0 {
+		return
+	}
+	selected := -1
+	if len(v.order) > 0 {
+		selected = v.order[v.cursor]
+	}
+	switch {
+	case v.sortCol != v.col:
+		v.sortCol, v.sortDesc = v.col, false
+	case !v.sortDesc:
+		v.sortDesc = true
+	default:
+		v.sortCol = -1
+	}
+	v.sortRows()
+
+	// keep the same row selected
+	for i, idx := range v.order {
+		if idx == selected {
+			v.cursor = i
+		}
+	}
+	v.scrollToCursor()
+}
+
+func (v *resultViewer) sortRows() {
+	for i := range v.order {
+		v.order[i] = i
+	}
+	if v.sortCol < 0 {
+		return
+	}
+	sort.SliceStable(v.order, func(i, j int) bool {
+		a, b := v.data[v.order[i]][v.sortCol], v.data[v.order[j]][v.sortCol]
+		if v.sortDesc {
+			return compareValues(b, a) < 0
+		}
+		return compareValues(a, b) < 0
+	})
+}
+
+// findNext selects the next (or previous, if direction is negative) row after the selected row which matches the search
+func (v *resultViewer) findNext(direction int) {
+	if v.search == "" {
+		v.message = "no search - press / to search"
+		return
+	}
+	v.find(v.cursor+direction, direction)
+}
+
+// find selects the first row matching the search, starting at the given position and wrapping around
+func (v *resultViewer) find(start, direction int) {
+	if v.search == "" || len(v.order) == 0 {
+		return
+	}
+	search := strings.ToLower(v.search)
+	count := len(v.order)
+	for i := 0; i < count; i++ {
+		pos := ((start+i*direction)%count + count) % count
+		for _, cell := range v.cells[v.order[pos]] {
+			if strings.Contains(strings.ToLower(cell), search) {
+				v.cursor = pos
+				v.scrollToCursor()
+				return
+			}
+		}
+	}
+	v.message = fmt.Sprintf("no rows match '%s'", v.search)
+}
+
+// render returns the lines of the screen
+func (v *resultViewer) render() []string {
+	var lines []string
+	switch v.mode {
+	case viewerModeDetail:
+		lines = v.renderDetail()
+	case viewerModeHelp:
+		lines = v.renderHelp()
+	default:
+		lines = v.renderTable()
+	}
+	for len(lines) < v.height-1 {
+		lines = append(lines, "")
+	}
+	return append(lines, v.renderStatus())
+}
+
+func (v *resultViewer) renderTable() []string {
+	var header, separator viewerLine
+	for i := v.leftCol; i < len(v.headers) && header.width < v.width; i++ {
+		if i > v.leftCol {
+			header.add(viewerColumnSeparator, "", v.width)
+			separator.add("─┼─", "", v.width)
+		}
+		title := v.headers[i]
+		if i == v.sortCol && v.sortDesc {
+			title += " ▼"
+		} else if i == v.sortCol {
+			title += " ▲"
+		}
+		style := styleBold
+		if i == v.col {
+			style += styleUnder
+		}
+		header.add(padCell(title, v.widths[i]), style, v.width)
+		separator.add(strings.Repeat("─", v.widths[i]), "", v.width)
+	}
+	lines := []string{header.String(), separator.String()}
+
+	for pos := v.top; pos < len(v.order) && pos < v.top+v.pageSize(); pos++ {
+		var line viewerLine
+		style := ""
+		if pos == v.cursor {
+			style = styleReverse
+		}
+		row := v.cells[v.order[pos]]
+		for i := v.leftCol; i < len(row) && line.width < v.width; i++ {
+			if i > v.leftCol {
+				line.add(viewerColumnSeparator, style, v.width)
+			}
+			line.add(padCell(tableCellValue(row[i]), v.widths[i]), style, v.width)
+		}
+		lines = append(lines, line.String())
+	}
+	return lines
+}
+
+// detailLines returns the lines of the detail view of the selected row - each column name and value,
+// with multi-line and long values wrapped
+func (v *resultViewer) detailLines() []string {
+	if len(v.order) == 0 {
+		return nil
+	}
+	nameWidth := 0
+	for _, h := range v.headers {
+		nameWidth = max(nameWidth, runewidth.StringWidth(h))
+	}
+	valueWidth := max(10, v.width-nameWidth-2)
+
+	var lines []string
+	for i, cell := range v.cells[v.order[v.cursor]] {
+		prefix := runewidth.FillRight(v.headers[i], nameWidth) + ": "
+		for _, valueLine := range strings.Split(cell, "\n") {
+			for _, part := range wrapText(valueLine, valueWidth) {
+				lines = append(lines, styleBold+prefix+styleReset+part)
+				prefix = strings.Repeat(" ", nameWidth+2)
+			}
+		}
+	}
+	return lines
+}
+
+func (v *resultViewer) renderDetail() []string {
+	title := fmt.Sprintf("Row %s of %s", utils.HumanizeNumber(v.cursor+1), utils.HumanizeNumber(len(v.order)))
+	lines := []string{styleBold + title + styleReset, ""}
+	detail := v.detailLines()
+	end := min(len(detail), v.detailTop+v.height-viewerChromeLines)
+	return append(lines, detail[min(v.detailTop, end):end]...)
+}
+
+func (v *resultViewer) renderHelp() []string {
+	lines := make([]string, len(viewerHelp))
+	for i, l := range viewerHelp {
+		lines[i] = runewidth.Truncate(l, v.width, "")
+	}
+	return lines
+}
+
+func (v *resultViewer) renderStatus() string {
+	var status string
+	switch {
+	case v.mode == viewerModeSearch:
+		status = "/" + v.input
+	case v.message != "":
+		status = v.message
+	case v.mode == viewerModeDetail:
+		status = "↑↓ scroll  ←→ previous/next row  q back"
+	default:
+		status = fmt.Sprintf("row %s of %s", utils.HumanizeNumber(min(v.cursor+1, len(v.order))), utils.HumanizeNumber(len(v.order)))
+		if len(v.headers) > 0 {
+			status += fmt.Sprintf("  column %d of %d", v.col+1, len(v.headers))
+		}
+		status += "  ? help  q quit"
+	}
+	return styleReverse + runewidth.FillRight(runewidth.Truncate(status, v.width, "…"), v.width) + styleReset
+}
+
+// viewerLine builds a styled line of the screen, truncated to the screen width
+type viewerLine struct {
+	b     strings.Builder
+	width int
+}
+
+func (l *viewerLine) add(s, style string, maxWidth int) {
+	if l.width >= maxWidth {
+		return
+	}
+	s = runewidth.Truncate(s, maxWidth-l.width, "…")
+	l.width += runewidth.StringWidth(s)
+	if style == "" {
+		l.b.WriteString(s)
+		return
+	}
+	l.b.WriteString(style + s + styleReset)
+}
+
+func (l *viewerLine) String() string {
+	return l.b.String()
+}
+
+// tableCellValue returns a value to show in a single line table cell
+func tableCellValue(value string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return ' '
+		}
+		if !unicode.IsGraphic(r) {
+			return -1
+		}
+		return r
+	}, value)
+}
+
+// padCell truncates or pads the value to the given width
+func padCell(value string, width int) string {
+	return runewidth.FillRight(runewidth.Truncate(value, width, "…"), width)
+}
+
+// wrapText splits the text into lines of at most width columns
+func wrapText(s string, width int) []string {
+	s = strings.Map(func(r rune) rune {
+		if r == '\t' {
+			return ' '
+		}
+		if !unicode.IsGraphic(r) {
+			return -1
+		}
+		return r
+	}, s)
+	var lines []string
+	for runewidth.StringWidth(s) > width {
+		line := runewidth.Truncate(s, width, "")
+		lines = append(lines, line)
+		s = s[len(line):]
+	}
+	return append(lines, s)
+}
+
+// compareValues compares two column values, returning a negative number if a sorts before b.
+// Nulls sort first, numbers and times are compared by value, and other values by their string representation
+func compareValues(a, b any) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	if x, ok := toNumber(a); ok {
+		if y, ok := toNumber(b); ok {
+			return x.Cmp(y)
+		}
+	}
+	if x, ok := a.(time.Time); ok {
+		if y, ok := b.(time.Time); ok {
+			return x.Compare(y)
+		}
+	}
+	if x, ok := a.(bool); ok {
+		if y, ok := b.(bool); ok && x != y {
+			if y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// toNumber converts a numeric value to a big float, so values of different numeric types can be compared
+func toNumber(v any) (*big.Float, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return new(big.Float).SetInt64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Float).SetUint64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		if f != f {
+			// NaN is not comparable
+			return nil, false
+		}
+		return big.NewFloat(f), true
+	}
+	return nil, false
+}
@@ -0,0 +1,213 @@
+package display
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+	"github.com/mattn/go-runewidth"
+	"github.com/spf13/viper"
+	pconstants "github.com/turbot/pipe-fittings/v2/constants"
+	"github.com/turbot/pipe-fittings/v2/querydisplay"
+	pqueryresult "github.com/turbot/pipe-fittings/v2/queryresult"
+	"github.com/turbot/pipe-fittings/v2/utils"
+	"github.com/turbot/steampipe/v2/pkg/constants"
+	"golang.org/x/term"
+)
+
+// terminal escape codes to switch to and from the alternate screen, hiding the cursor while it is shown
+const (
+	enterAlternateScreen = "\x1b[?1049h\x1b[?25l"
+	exitAlternateScreen  = "\x1b[?25h\x1b[?1049l"
+)
+
+// the input of the special keys handled by the viewer
+var viewerKeyInput = map[string]string{
+	"\x1b[A":  keyUp,
+	"\x1bOA":  keyUp,
+	"\x1b[B":  keyDown,
+	"\x1bOB":  keyDown,
+	"\x1b[C":  keyRight,
+	"\x1bOC":  keyRight,
+	"\x1b[D":  keyLeft,
+	"\x1bOD":  keyLeft,
+	"\x1b[5~": keyPageUp,
+	"\x02":    keyPageUp, // ctrl+b
+	"\x15":    keyPageUp, // ctrl+u
+	"\x1b[6~": keyPageDown,
+	"\x06":    keyPageDown, // ctrl+f
+	"\x04":    keyPageDown, // ctrl+d
+	"\x1b[H":  keyHome,
+	"\x1bOH":  keyHome,
+	"\x1b[1~": keyHome,
+	"\x1b[7~": keyHome,
+	"\x1b[F":  keyEnd,
+	"\x1bOF":  keyEnd,
+	"\x1b[4~": keyEnd,
+	"\x1b[8~": keyEnd,
+	"\r":      keyEnter,
+	"\n":      keyEnter,
+	"\x1b":    keyEscape,
+	"\x7f":    keyBackspace,
+	"\x08":    keyBackspace,
+	"\x03":    keyCtrlC,
+}
+
+// UseResultViewer returns whether interactive query results are shown in the full-screen result viewer - it must be
+// enabled, the output format must be table, and the results must be shown on a terminal rather than written to a file
+func UseResultViewer() bool {
+	if !viper.GetBool(constants.ArgResultViewer) || viper.GetString(pconstants.ArgOutput) != constants.OutputFormatTable {
+		return false
+	}
+	if path, _ := OutputRedirectTarget(); path != "" {
+		return false
+	}
+	return isatty.IsTerminal(os.Stdin.Fd()) && isatty.IsTerminal(os.Stdout.Fd())
+}
+
+// ViewResult shows the result in the full-screen result viewer, returning the number of rows.
+// Results which fit on the screen, or which have a row error, are shown with the standard display
+func ViewResult[T pqueryresult.TimingContainer](ctx context.Context, result *pqueryresult.Result[T]) int {
+	var data [][]any
+	var rowErr error
+	for row := range result.RowChan {
+		if row.Error != nil {
+			if rowErr == nil {
+				rowErr = row.Error
+			}
+			continue
+		}
+		data = append(data, row.Data)
+	}
+
+	headers := make([]string, len(result.Cols))
+	for i, c := range result.Cols {
+		headers[i] = c.Name
+		if c.OriginalName != "" {
+			headers[i] = c.OriginalName
+		}
+	}
+	cells := make([][]string, len(data))
+	for i, row := range data {
+		// use the same humanised values as the table display
+		cells[i], _ = querydisplay.ColumnValuesAsString(row, result.Cols, querydisplay.WithHumanisedString(true))
+	}
+
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || rowErr != nil || tableFits(headers, cells, width, height) {
+		rowCount, _ := querydisplay.ShowOutput(ctx, replayResult(result, data, rowErr))
+		return rowCount
+	}
+
+	v := newResultViewer(headers, data, cells)
+	v.resize(width, height)
+	if err := runResultViewer(v); err != nil {
+		// the viewer could not use the terminal - show the rows with the standard display
+		rowCount, _ := querydisplay.ShowOutput(ctx, replayResult(result, data, nil))
+		return rowCount
+	}
+	fmt.Printf("%s %s\n", utils.HumanizeNumber(len(data)), utils.Pluralize("row", len(data)))
+	return len(data)
+}
+
+// replayResult returns a result which streams the given rows, followed by the row error if there is one
+func replayResult[T pqueryresult.TimingContainer](result *pqueryresult.Result[T], data [][]any, rowErr error) *pqueryresult.Result[T] {
+	replay := pqueryresult.NewResult(result.Cols, result.Timing)
+	go func() {
+		defer replay.Close()
+		for _, row := range data {
+			replay.StreamRow(row)
+		}
+		if rowErr != nil {
+			replay.StreamError(rowErr)
+		}
+	}()
+	return replay
+}
+
+// tableFits returns whether the standard table display of the rows fits on a screen of the given size
+func tableFits(headers []string, cells [][]string, width, height int) bool {
+	widths := make([]int, len(headers))
+	for i, h := range headers {
+		widths[i] = runewidth.StringWidth(h)
+	}
+	// the borders, header, header separator and row count
+	lines := 5
+	for _, row := range cells {
+		rowLines := 1
+		for i, cell := range row {
+			cellLines := strings.Split(cell, "\n")
+			rowLines = max(rowLines, len(cellLines))
+			for _, l := range cellLines {
+				widths[i] = max(widths[i], runewidth.StringWidth(l))
+			}
+		}
+		lines += rowLines
+		if lines > height {
+			return false
+		}
+	}
+	tableWidth := 1
+	for _, w := range widths {
+		tableWidth += w + 3
+	}
+	return tableWidth <= width
+}
+
+// runResultViewer shows the viewer on the alternate screen, handling key presses until the viewer exits
+func runResultViewer(v *resultViewer) error {
+	in, out := int(os.Stdin.Fd()), int(os.Stdout.Fd())
+	state, err := term.MakeRaw(in)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = term.Restore(in, state) }()
+
+	w := bufio.NewWriter(os.Stdout)
+	fmt.Fprint(w, enterAlternateScreen)
+	defer func() {
+		fmt.Fprint(w, exitAlternateScreen)
+		w.Flush()
+	}()
+
+	buf := make([]byte, 256)
+	for {
+		// read the size before each render, so the viewer follows changes to the terminal size
+		if width, height, err := term.GetSize(out); err == nil {
+			v.resize(width, height)
+		}
+		fmt.Fprint(w, "\x1b[H")
+		for i, line := range v.render() {
+			if i > 0 {
+				fmt.Fprint(w, "\r\n")
+			}
+			fmt.Fprint(w, line, "\x1b[K")
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return err
+		}
+		if v.handleKey(parseViewerKey(buf[:n])) {
+			return nil
+		}
+	}
+}
+
+// parseViewerKey returns the name of the special key of the given input, or the input text
+func parseViewerKey(input []byte) string {
+	if key, ok := viewerKeyInput[string(input)]; ok {
+		return key
+	}
+	// ignore other control keys and escape sequences
+	if len(input) == 0 || input[0] < 0x20 {
+		return keyUnknown
+	}
+	return string(input)
+}
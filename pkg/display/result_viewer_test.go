@@ -0,0 +1,181 @@
+package display
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testViewer returns a viewer of rows with an id, a name and a long description
+func testViewer(rowCount int) *resultViewer {
+	headers := []string{"id", "name", "description"}
+	var data [][]any
+	var cells [][]string
+	for i := 0; i < rowCount; i++ {
+		// names sort in the reverse order of the ids
+		name := fmt.Sprintf("name_%03d", rowCount-i)
+		description := strings.Repeat("x", 100)
+		data = append(data, []any{int64(i + 1), name, description})
+		cells = append(cells, []string{fmt.Sprint(i + 1), name, description})
+	}
+	v := newResultViewer(headers, data, cells)
+	v.resize(40, 13)
+	return v
+}
+
+// selectedID returns the id of the selected row
+func selectedID(v *resultViewer) int64 {
+	return v.data[v.order[v.cursor]][0].(int64)
+}
+
+func TestResultViewer_Scroll(t *testing.T) {
+	v := testViewer(100)
+	assert.Equal(t, 10, v.pageSize())
+
+	v.handleKey(keyPageDown)
+	assert.Equal(t, int64(11), selectedID(v))
+	assert.Equal(t, 1, v.top)
+
+	v.handleKey(keyEnd)
+	assert.Equal(t, int64(100), selectedID(v))
+	assert.Equal(t, 90, v.top)
+
+	v.handleKey("k")
+	v.handleKey(keyHome)
+	assert.Equal(t, int64(1), selectedID(v))
+	assert.Equal(t, 0, v.top)
+
+	lines := v.render()
+	assert.Len(t, lines, 13)
+	assert.Contains(t, lines[len(lines)-1], "row 1 of 100")
+}
+
+func TestResultViewer_Pan(t *testing.T) {
+	v := testViewer(5)
+	v.handleKey(keyRight)
+	v.handleKey(keyRight)
+	// the description column is 62 wide, so it is shown on its own
+	assert.Equal(t, 2, v.col)
+	assert.Equal(t, 2, v.leftCol)
+	assert.True(t, strings.HasPrefix(v.render()[0], styleBold+styleUnder+"description"))
+
+	v.handleKey("h")
+	assert.Equal(t, 1, v.leftCol)
+	v.handleKey("h")
+	v.handleKey("h")
+	assert.Equal(t, 0, v.col)
+	assert.Equal(t, 0, v.leftCol)
+}
+
+func TestResultViewer_Sort(t *testing.T) {
+	v := testViewer(20)
+	v.handleKey("j")
+	assert.Equal(t, int64(2), selectedID(v))
+
+	// sort by name - ascending, then descending, then unsorted
+	v.handleKey(keyRight)
+	v.handleKey("s")
+	assert.Equal(t, "name_001", v.cells[v.order[0]][1])
+	// the selected row stays selected
+	assert.Equal(t, int64(2), selectedID(v))
+	assert.Contains(t, v.render()[0], "name ▲")
+
+	v.handleKey("s")
+	assert.Equal(t, "name_020", v.cells[v.order[0]][1])
+	v.handleKey("s")
+	assert.Equal(t, -1, v.sortCol)
+	assert.Equal(t, int64(1), v.data[v.order[0]][0])
+
+	// ids are sorted as numbers, not strings
+	v.handleKey(keyLeft)
+	v.handleKey("s")
+	v.handleKey("s")
+	assert.Equal(t, int64(20), v.data[v.order[0]][0])
+	assert.Equal(t, int64(19), v.data[v.order[1]][0])
+}
+
+func TestResultViewer_Search(t *testing.T) {
+	v := testViewer(30)
+	for _, key := range []string{"/", "N", "a", "m", "e", "_", "0", "1", "x", keyBackspace, keyEnter} {
+		v.handleKey(key)
+	}
+	assert.Equal(t, "Name_01", v.search)
+	// name_019 is the first match - names are in reverse order
+	assert.Equal(t, int64(12), selectedID(v))
+
+	v.handleKey("n")
+	assert.Equal(t, int64(13), selectedID(v))
+	v.handleKey("N")
+	v.handleKey("N")
+	// the search wraps around
+	assert.Equal(t, int64(21), selectedID(v))
+
+	v.handleKey("/")
+	for _, r := range "missing" {
+		v.handleKey(string(r))
+	}
+	v.handleKey(keyEnter)
+	assert.Equal(t, int64(21), selectedID(v))
+	assert.Contains(t, v.render()[12], "no rows match 'missing'")
+}
+
+func TestResultViewer_Detail(t *testing.T) {
+	v := testViewer(3)
+	v.handleKey("j")
+	v.handleKey(keyEnter)
+	assert.Equal(t, viewerModeDetail, v.mode)
+
+	lines := v.detailLines()
+	// the description is wrapped at the screen width
+	assert.Equal(t, []string{
+		styleBold + "id         : " + styleReset + "2",
+		styleBold + "name       : " + styleReset + "name_002",
+		styleBold + "description: " + styleReset + strings.Repeat("x", 27),
+		styleBold + "             " + styleReset + strings.Repeat("x", 27),
+		styleBold + "             " + styleReset + strings.Repeat("x", 27),
+		styleBold + "             " + styleReset + strings.Repeat("x", 19),
+	}, lines)
+	assert.Equal(t, styleBold+"Row 2 of 3"+styleReset, v.render()[0])
+
+	// the next row
+	v.handleKey("l")
+	assert.Equal(t, int64(3), selectedID(v))
+
+	v.handleKey("q")
+	assert.Equal(t, viewerModeTable, v.mode)
+	assert.True(t, v.handleKey("q"))
+}
+
+func TestCompareValues(t *testing.T) {
+	now := time.Now()
+	assert.Negative(t, compareValues(nil, 1))
+	assert.Negative(t, compareValues(int64(9), int64(10)))
+	assert.Negative(t, compareValues(int32(9), 9.5))
+	assert.Positive(t, compareValues(uint64(10), int64(-1)))
+	assert.Negative(t, compareValues(now, now.Add(time.Second)))
+	assert.Negative(t, compareValues(false, true))
+	assert.Zero(t, compareValues("a", "a"))
+	assert.Negative(t, compareValues("a", "b"))
+}
+
+func TestTableFits(t *testing.T) {
+	headers := []string{"id", "name"}
+	cells := [][]string{{"1", "a"}, {"2", "multi\nline"}}
+	// the top border, header and separator, 3 row lines, the bottom border and the row count
+	assert.True(t, tableFits(headers, cells, 80, 8))
+	assert.False(t, tableFits(headers, cells, 80, 7))
+	// | id | name  |
+	assert.True(t, tableFits(headers, cells, 14, 8))
+	assert.False(t, tableFits(headers, cells, 13, 8))
+}
+
+func TestParseViewerKey(t *testing.T) {
+	assert.Equal(t, keyUp, parseViewerKey([]byte("\x1b[A")))
+	assert.Equal(t, keyEnter, parseViewerKey([]byte{0x0d}))
+	assert.Equal(t, keyEscape, parseViewerKey([]byte{0x1b}))
+	assert.Equal(t, "q", parseViewerKey([]byte("q")))
+	assert.Equal(t, keyUnknown, parseViewerKey([]byte("\x1b[15~")))
+}
@@ -0,0 +1,121 @@
+package display
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/fatih/color"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/turbot/pipe-fittings/v2/querydisplay"
+	"github.com/turbot/pipe-fittings/v2/queryresult"
+	"github.com/turbot/pipe-fittings/v2/utils"
+	"github.com/turbot/steampipe/v2/pkg/constants"
+	"github.com/turbot/steampipe/v2/pkg/snapshot"
+)
+
+var (
+	addedColor   = color.New(color.FgGreen)
+	removedColor = color.New(color.FgRed)
+	changedColor = color.New(color.FgYellow, color.Bold)
+)
+
+// rowDiffMarkers are shown in the first column of a diff table to indicate the status of each row
+var rowDiffMarkers = map[snapshot.RowDiffStatus]string{
+	snapshot.RowUnchanged: " ",
+	snapshot.RowAdded:     "+",
+	snapshot.RowRemoved:   "-",
+	snapshot.RowChanged:   "~",
+}
+
+// BuildRowDiffTable renders a query result diff as a table.
+// The first column shows whether each row was added (+), removed (-) or changed (~) and,
+// if colour is enabled, added and removed rows and changed values are highlighted
+func BuildRowDiffTable(columns []*queryresult.ColumnDef, diffs []snapshot.RowDiff, showHeader bool) (string, error) {
+	var buf bytes.Buffer
+	t := table.NewWriter()
+	t.SetOutputMirror(&buf)
+	t.SetStyle(table.StyleDefault)
+	t.Style().Format.Header = text.FormatDefault
+
+	colConfigs := []table.ColumnConfig{{Number: 1}}
+	headers := table.Row{""}
+	for idx, c := range columns {
+		name := c.Name
+		if c.OriginalName != "" {
+			name = c.OriginalName
+		}
+		headers = append(headers, name)
+		colConfigs = append(colConfigs, table.ColumnConfig{
+			Name:     name,
+			Number:   idx + 2,
+			WidthMax: constants.MaxColumnWidth,
+		})
+	}
+	t.SetColumnConfigs(colConfigs)
+	if showHeader {
+		t.AppendHeader(headers)
+	}
+
+	for _, d := range diffs {
+		values := make([]any, len(columns))
+		for i, c := range columns {
+			values[i] = d.Row[c.Name]
+		}
+		valuesAsString, err := querydisplay.ColumnValuesAsString(values, columns, querydisplay.WithHumanisedString(true))
+		if err != nil {
+			return "", err
+		}
+
+		row := table.Row{rowDiffMarkers[d.Status]}
+		for i, value := range valuesAsString {
+			value = displayableString(value)
+			switch {
+			case d.Status == snapshot.RowAdded:
+				value = addedColor.Sprint(value)
+			case d.Status == snapshot.RowRemoved:
+				value = removedColor.Sprint(value)
+			case d.Status == snapshot.RowChanged && isChangedColumn(d, columns[i].Name):
+				value = changedColor.Sprint(value)
+			}
+			row = append(row, value)
+		}
+		t.AppendRow(row)
+	}
+	t.Render()
+	return buf.String(), nil
+}
+
+// RowDiffSummaryString returns a description of the number of rows and changes in a diff, e.g. "10 rows (+1 added -2 removed ~3 changed)"
+func RowDiffSummaryString(summary snapshot.RowDiffSummary) string {
+	rowCount := summary.Added + summary.Changed + summary.Unchanged
+	res := fmt.Sprintf("%d %s", rowCount, utils.Pluralize("row", rowCount))
+	if summary.HasChanges() {
+		res += fmt.Sprintf(" (%s %s %s)",
+			addedColor.Sprintf("+%d added", summary.Added),
+			removedColor.Sprintf("-%d removed", summary.Removed),
+			changedColor.Sprintf("~%d changed", summary.Changed))
+	}
+	return res
+}
+
+func isChangedColumn(d snapshot.RowDiff, name string) bool {
+	for _, c := range d.ChangedColumns {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+// displayableString removes non-displayable code-points from a string, except white space
+func displayableString(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || unicode.IsGraphic(r) {
+			return r
+		}
+		return -1
+	}, s)
+}
@@ -0,0 +1,101 @@
+package display
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/viper"
+	pqueryresult "github.com/turbot/pipe-fittings/v2/queryresult"
+	"github.com/turbot/steampipe-plugin-sdk/v5/sperr"
+	"github.com/turbot/steampipe/v2/pkg/constants"
+	"github.com/turbot/steampipe/v2/pkg/error_helpers"
+	"github.com/turbot/steampipe/v2/pkg/query/queryresult"
+	"github.com/turbot/steampipe/v2/pkg/snapshot"
+)
+
+// OutputTemplateData is the data passed to an output template
+type OutputTemplateData struct {
+	Columns []*pqueryresult.ColumnDef
+	// the rows of the result, as a map of column name to value
+	Rows []map[string]any
+	// the timing of the query - this may be nil
+	Timing *queryresult.TimingResult
+}
+
+// outputTemplateFuncs are the functions available to output templates, in addition to the text/template builtins
+var outputTemplateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"join": func(sep string, values []any) string {
+		res := make([]string, len(values))
+		for i, v := range values {
+			res[i] = fmt.Sprint(v)
+		}
+		return strings.Join(res, sep)
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"trim":  strings.TrimSpace,
+}
+
+// LoadOutputTemplate parses an output template file
+func LoadOutputTemplate(path string) (*template.Template, error) {
+	t, err := template.New(filepath.Base(path)).Funcs(outputTemplateFuncs).ParseFiles(path)
+	if err != nil {
+		return nil, sperr.WrapWithMessage(err, "failed to load output template")
+	}
+	return t, nil
+}
+
+// RenderOutputTemplate executes an output template, only writing the output if the template executes successfully
+func RenderOutputTemplate(w io.Writer, t *template.Template, data *OutputTemplateData) error {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return sperr.WrapWithMessage(err, "failed to render output template")
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// ShowTemplateOutput displays a query result using the template file set by the template arg.
+// The result is rendered once all rows have been read - if a row returns an error, the error is shown instead.
+// getTiming is called after all rows have been read and returns the timing of the query
+func ShowTemplateOutput[T pqueryresult.TimingContainer](ctx context.Context, result *pqueryresult.Result[T], getTiming func() *queryresult.TimingResult) (rowCount, rowErrors int) {
+	data := &OutputTemplateData{Columns: snapshot.ColumnDefs(result.Cols)}
+	for row := range result.RowChan {
+		if row.Error != nil {
+			error_helpers.ShowError(ctx, row.Error)
+			rowErrors++
+			continue
+		}
+		data.Rows = append(data.Rows, snapshot.RowData(row.Data, result.Cols))
+	}
+	rowCount = len(data.Rows)
+	if rowErrors > 0 {
+		return rowCount, rowErrors
+	}
+	data.Timing = getTiming()
+	if data.Timing != nil {
+		data.Timing.RowsReturned = int64(rowCount)
+	}
+
+	t, err := LoadOutputTemplate(viper.GetString(constants.ConfigKeyOutputTemplate))
+	if err == nil {
+		err = RenderOutputTemplate(os.Stdout, t, data)
+	}
+	if err != nil {
+		error_helpers.ShowError(ctx, err)
+		// count the failure to render as an error, so the command fails
+		rowErrors++
+	}
+	return rowCount, rowErrors
+}
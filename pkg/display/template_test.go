@@ -0,0 +1,58 @@
+package display
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pqueryresult "github.com/turbot/pipe-fittings/v2/queryresult"
+	"github.com/turbot/steampipe/v2/pkg/query/queryresult"
+)
+
+func writeTemplate(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "output.tmpl")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestRenderOutputTemplate(t *testing.T) {
+	tmpl, err := LoadOutputTemplate(writeTemplate(t, `{{range .Columns}}{{upper .Name}} {{end}}
+{{range .Rows}}- {{.name}}: {{json .tags}}
+{{end}}{{len .Rows}} buckets in {{.Timing.DurationMs}}ms`))
+	require.NoError(t, err)
+
+	data := &OutputTemplateData{
+		Columns: []*pqueryresult.ColumnDef{{Name: "name"}, {Name: "tags"}},
+		Rows: []map[string]any{
+			{"name": "logs", "tags": map[string]any{"env": "prod"}},
+			{"name": "backups", "tags": nil},
+		},
+		Timing: &queryresult.TimingResult{DurationMs: 12},
+	}
+	var buf bytes.Buffer
+	require.NoError(t, RenderOutputTemplate(&buf, tmpl, data))
+	assert.Equal(t, "NAME TAGS \n- logs: {\"env\":\"prod\"}\n- backups: null\n2 buckets in 12ms", buf.String())
+}
+
+func TestRenderOutputTemplate_ExecutionError(t *testing.T) {
+	tmpl, err := LoadOutputTemplate(writeTemplate(t, `partial {{.Missing}}`))
+	require.NoError(t, err)
+
+	// nothing is written if the template fails
+	var buf bytes.Buffer
+	err = RenderOutputTemplate(&buf, tmpl, &OutputTemplateData{})
+	assert.ErrorContains(t, err, "failed to render output template")
+	assert.Empty(t, buf.String())
+}
+
+func TestLoadOutputTemplate_Invalid(t *testing.T) {
+	_, err := LoadOutputTemplate(writeTemplate(t, `{{range .Rows}}`))
+	assert.ErrorContains(t, err, "failed to load output template")
+
+	_, err = LoadOutputTemplate(filepath.Join(t.TempDir(), "missing.tmpl"))
+	assert.ErrorContains(t, err, "failed to load output template")
+}
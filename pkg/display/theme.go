@@ -0,0 +1,220 @@
+package display
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/alecthomas/chroma"
+	"github.com/alecthomas/chroma/styles"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/turbot/steampipe-plugin-sdk/v5/sperr"
+	"github.com/turbot/steampipe/v2/pkg/filepaths"
+)
+
+// DefaultThemeName is the theme used if no theme is set
+const DefaultThemeName = "dark"
+
+// the file extension of theme files
+const themeFileExtension = ".hcl"
+
+// map of built in theme name to the chroma style used to highlight sql
+var builtinThemes = map[string]string{
+	"dark":  "native",
+	"light": "solarized-light",
+	"plain": "bw",
+}
+
+// Theme is the colours used to highlight sql in the interactive prompt, and to show table output
+type Theme struct {
+	Name        string
+	Style       *chroma.Style
+	TableColors table.ColorOptions
+}
+
+// themeFile is the content of a user theme file
+type themeFile struct {
+	// the built in theme or chroma style to base the theme on
+	Base *string `hcl:"base"`
+	// map of chroma token type (e.g. keyword, literal_string) to style entry (e.g. "bold #00aa00")
+	Syntax map[string]string `hcl:"syntax,optional"`
+	Table  *themeTableColors `hcl:"table,block"`
+}
+
+// themeTableColors is the colours of table output - each is a space separated list of colours, e.g. "bold blue"
+type themeTableColors struct {
+	Border       *string `hcl:"border"`
+	Header       *string `hcl:"header"`
+	Row          *string `hcl:"row"`
+	RowAlternate *string `hcl:"row_alternate"`
+}
+
+// DefaultTheme returns the default theme
+func DefaultTheme() *Theme {
+	theme, _ := LoadTheme(DefaultThemeName)
+	return theme
+}
+
+// LoadTheme returns the theme with the given name - a built in theme, a theme file in the themes directory
+// or a chroma style. An empty name is the default theme
+func LoadTheme(name string) (*Theme, error) {
+	if name == "" {
+		name = DefaultThemeName
+	}
+	if theme := builtinTheme(name); theme != nil {
+		return theme, nil
+	}
+	path := filepath.Join(filepaths.ThemesDir(), name+themeFileExtension)
+	if _, err := os.Stat(path); err == nil {
+		return loadThemeFile(name, path)
+	}
+	return nil, fmt.Errorf("theme '%s' not found - add a theme file to %s, or use one of: %s", name, filepaths.ThemesDir(), strings.Join(ThemeNames(), ", "))
+}
+
+// ThemeNames returns the names of the built in themes and the theme files, sorted by name
+func ThemeNames() []string {
+	var names []string
+	for name := range builtinThemes {
+		names = append(names, name)
+	}
+	entries, _ := os.ReadDir(filepaths.ThemesDir())
+	for _, entry := range entries {
+		if name, ok := strings.CutSuffix(entry.Name(), themeFileExtension); ok && !entry.IsDir() && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// builtinTheme returns the built in theme, or a theme using the chroma style, with the given name
+func builtinTheme(name string) *Theme {
+	styleName, ok := builtinThemes[name]
+	if !ok {
+		styleName = name
+	}
+	style, ok := styles.Registry[styleName]
+	if !ok {
+		return nil
+	}
+	return &Theme{Name: name, Style: style}
+}
+
+func loadThemeFile(name, path string) (*Theme, error) {
+	file, diags := hclparse.NewParser().ParseHCLFile(path)
+	if diags.HasErrors() {
+		return nil, sperr.WrapWithMessage(diags, "failed to parse theme file %s", path)
+	}
+	var content themeFile
+	if diags := gohcl.DecodeBody(file.Body, &hcl.EvalContext{}, &content); diags.HasErrors() {
+		return nil, sperr.WrapWithMessage(diags, "failed to parse theme file %s", path)
+	}
+
+	base := DefaultThemeName
+	if content.Base != nil {
+		base = *content.Base
+	}
+	baseTheme := builtinTheme(base)
+	if baseTheme == nil {
+		return nil, fmt.Errorf("theme file %s: unknown base '%s' - the base must be a built in theme or a chroma style", path, base)
+	}
+
+	builder := baseTheme.Style.Builder()
+	for tokenName, entry := range content.Syntax {
+		tokenType, ok := chromaTokenType(tokenName)
+		if !ok {
+			return nil, fmt.Errorf("theme file %s: unknown syntax token type '%s'", path, tokenName)
+		}
+		if _, err := chroma.ParseStyleEntry(entry); err != nil {
+			return nil, fmt.Errorf("theme file %s: invalid style for %s: %s", path, tokenName, err.Error())
+		}
+		builder.Add(tokenType, entry)
+	}
+	style, err := builder.Build()
+	if err != nil {
+		return nil, sperr.WrapWithMessage(err, "theme file %s: invalid syntax style", path)
+	}
+
+	theme := &Theme{Name: name, Style: style}
+	if content.Table != nil {
+		colors := []struct {
+			spec   *string
+			target *text.Colors
+		}{
+			{content.Table.Border, &theme.TableColors.Border},
+			{content.Table.Header, &theme.TableColors.Header},
+			{content.Table.Row, &theme.TableColors.Row},
+			{content.Table.RowAlternate, &theme.TableColors.RowAlternate},
+		}
+		for _, c := range colors {
+			if c.spec == nil {
+				continue
+			}
+			if *c.target, err = parseTextColors(*c.spec); err != nil {
+				return nil, fmt.Errorf("theme file %s: %s", path, err.Error())
+			}
+		}
+	}
+	return theme, nil
+}
+
+// chromaTokenType returns the chroma token type with the given name, ignoring case and underscores,
+// e.g. literal_string is LiteralString
+func chromaTokenType(name string) (chroma.TokenType, bool) {
+	name = strings.ToLower(strings.ReplaceAll(name, "_", ""))
+	for tokenType := range chroma.StandardTypes {
+		if strings.ToLower(tokenType.String()) == name {
+			return tokenType, true
+		}
+	}
+	return 0, false
+}
+
+// map of colour name to text colour, e.g. blue, hi_blue, bg_blue, bg_hi_blue and the text attributes
+var textColors = func() map[string]text.Color {
+	res := map[string]text.Color{
+		"bold":      text.Bold,
+		"faint":     text.Faint,
+		"italic":    text.Italic,
+		"underline": text.Underline,
+		"reverse":   text.ReverseVideo,
+	}
+	for i, name := range []string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"} {
+		res[name] = text.FgBlack + text.Color(i)
+		res["hi_"+name] = text.FgHiBlack + text.Color(i)
+		res["bg_"+name] = text.BgBlack + text.Color(i)
+		res["bg_hi_"+name] = text.BgHiBlack + text.Color(i)
+	}
+	return res
+}()
+
+// parseTextColors parses a space separated list of colours, e.g. "bold blue"
+func parseTextColors(spec string) (text.Colors, error) {
+	var res text.Colors
+	for _, name := range strings.Fields(strings.ToLower(spec)) {
+		color, ok := textColors[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown colour '%s'", name)
+		}
+		res = append(res, color)
+	}
+	return res, nil
+}
+
+// the table colours of the current theme - protected by redirectLock, as the colours are cleared while output is redirected
+var tableColors table.ColorOptions
+
+// SetTableColors sets the colours of table output.
+// The table display uses a copy of the default table style, so the colours are set on the default style
+func SetTableColors(colors table.ColorOptions) {
+	redirectLock.Lock()
+	defer redirectLock.Unlock()
+	tableColors = colors
+	table.StyleDefault.Color = colors
+}
@@ -0,0 +1,106 @@
+package display
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alecthomas/chroma"
+	"github.com/alecthomas/chroma/styles"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/turbot/pipe-fittings/v2/app_specific"
+)
+
+// setupThemesDir sets the install dir to a temp dir, and writes the given theme files to its themes directory
+func setupThemesDir(t *testing.T, themes map[string]string) {
+	installDir := app_specific.InstallDir
+	app_specific.InstallDir = t.TempDir()
+	t.Cleanup(func() { app_specific.InstallDir = installDir })
+
+	themesDir := filepath.Join(app_specific.InstallDir, "config", "themes")
+	require.NoError(t, os.MkdirAll(themesDir, 0755))
+	for name, content := range themes {
+		require.NoError(t, os.WriteFile(filepath.Join(themesDir, name+".hcl"), []byte(content), 0644))
+	}
+}
+
+func TestLoadTheme_Builtin(t *testing.T) {
+	theme, err := LoadTheme("")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultThemeName, theme.Name)
+	assert.Equal(t, styles.Native, theme.Style)
+
+	theme, err = LoadTheme("light")
+	require.NoError(t, err)
+	assert.Equal(t, styles.Get("solarized-light"), theme.Style)
+
+	// any chroma style can be used as a theme
+	theme, err = LoadTheme("monokai")
+	require.NoError(t, err)
+	assert.Equal(t, styles.Monokai, theme.Style)
+}
+
+func TestLoadTheme_File(t *testing.T) {
+	setupThemesDir(t, map[string]string{
+		"contrast": `
+base = "light"
+syntax = {
+  keyword        = "bold #005f87"
+  literal_string = "#008700"
+}
+table {
+  header        = "bold bg_blue hi_white"
+  row_alternate = "faint"
+}
+`,
+	})
+
+	theme, err := LoadTheme("contrast")
+	require.NoError(t, err)
+	assert.Equal(t, "contrast", theme.Name)
+
+	keyword := theme.Style.Get(chroma.Keyword)
+	assert.True(t, keyword.Bold == chroma.Yes)
+	assert.Equal(t, chroma.MustParseColour("#005f87"), keyword.Colour)
+	assert.Equal(t, chroma.MustParseColour("#008700"), theme.Style.Get(chroma.LiteralString).Colour)
+	// tokens which are not set use the base style
+	assert.Equal(t, styles.Get("solarized-light").Get(chroma.Comment), theme.Style.Get(chroma.Comment))
+
+	assert.Equal(t, text.Colors{text.Bold, text.BgBlue, text.FgHiWhite}, theme.TableColors.Header)
+	assert.Equal(t, text.Colors{text.Faint}, theme.TableColors.RowAlternate)
+	assert.Nil(t, theme.TableColors.Row)
+
+	assert.Equal(t, []string{"contrast", "dark", "light", "plain"}, ThemeNames())
+}
+
+func TestLoadTheme_Invalid(t *testing.T) {
+	setupThemesDir(t, map[string]string{
+		"bad_base":   `base = "unknown"`,
+		"bad_token":  `syntax = { not_a_token = "bold" }`,
+		"bad_style":  `syntax = { keyword = "#zzzzzz" }`,
+		"bad_colour": `table { header = "bold purple" }`,
+		"bad_hcl":    `syntax = {`,
+	})
+	for _, name := range []string{"bad_base", "bad_token", "bad_style", "bad_colour", "bad_hcl", "missing"} {
+		_, err := LoadTheme(name)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestWithOutputRedirect_TableColors(t *testing.T) {
+	colors := table.ColorOptions{Header: text.Colors{text.Bold}}
+	SetTableColors(colors)
+	t.Cleanup(func() { SetTableColors(table.ColorOptions{}) })
+	assert.Equal(t, colors, table.StyleDefault.Color)
+
+	// the colours are not written to an output file
+	require.NoError(t, RedirectOutput(filepath.Join(t.TempDir(), "out.txt"), false))
+	t.Cleanup(func() { _ = StopOutputRedirect() })
+	require.NoError(t, WithOutputRedirect(func() {
+		assert.Equal(t, table.ColorOptions{}, table.StyleDefault.Color)
+	}))
+	assert.Equal(t, colors, table.StyleDefault.Color)
+}
@@ -1,14 +1,18 @@
 package display
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 	pconstants "github.com/turbot/pipe-fittings/v2/constants"
+	"github.com/turbot/steampipe/v2/pkg/constants"
+	"github.com/turbot/steampipe/v2/pkg/error_helpers"
 	"github.com/turbot/steampipe/v2/pkg/query/queryresult"
 	"golang.org/x/text/language"
 	"golang.org/x/text/message"
@@ -17,14 +21,62 @@ import (
 func DisplayTiming(result *queryresult.Result, rowCount int) {
 	// show timing
 	timingResult := getTiming(result, rowCount)
-	if viper.GetString(pconstants.ArgTiming) != pconstants.ArgOff && timingResult != nil {
-		str := buildTimingString(timingResult)
-		if viper.GetBool(pconstants.ConfigKeyInteractive) {
-			fmt.Println(str)
-		} else {
-			fmt.Fprintln(os.Stderr, str)
+	if viper.GetString(pconstants.ArgTiming) == pconstants.ArgOff || timingResult == nil {
+		return
+	}
+	str, err := buildTiming(timingResult)
+	if err != nil {
+		error_helpers.ShowWarning(fmt.Sprintf("failed to build query timing: %v", err))
+		return
+	}
+
+	// if a timing file is set, append the timing to it rather than displaying it
+	if timingFile := viper.GetString(constants.ArgTimingFile); timingFile != "" {
+		if err := appendTimingFile(timingFile, str); err != nil {
+			error_helpers.ShowWarning(fmt.Sprintf("failed to write query timing to '%s': %v", timingFile, err))
 		}
+		return
+	}
+	if viper.GetBool(pconstants.ConfigKeyInteractive) {
+		fmt.Println(str)
+	} else {
+		fmt.Fprintln(os.Stderr, str)
+	}
+}
+
+// buildTiming returns the timing as json if the timing arg is 'json' or 'json-verbose', otherwise as text
+func buildTiming(timingResult *queryresult.TimingResult) (string, error) {
+	if timing := viper.GetString(pconstants.ArgTiming); timing == constants.TimingJSON || timing == constants.TimingJSONVerbose {
+		return buildTimingJSON(timingResult, time.Now())
+	}
+	return buildTimingString(timingResult), nil
+}
+
+// timingJSON is the JSON representation of the timing of a query
+type timingJSON struct {
+	Timestamp time.Time `json:"timestamp"`
+	*queryresult.TimingResult
+}
+
+// buildTimingJSON returns the timing as a single line of JSON, so a timing file contains a JSON object per line
+func buildTimingJSON(timingResult *queryresult.TimingResult, timestamp time.Time) (string, error) {
+	res, err := json.Marshal(timingJSON{Timestamp: timestamp, TimingResult: timingResult})
+	if err != nil {
+		return "", err
+	}
+	return string(res), nil
+}
+
+func appendTimingFile(path, timing string) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(f, strings.TrimPrefix(timing, "\n")); err != nil {
+		f.Close()
+		return err
 	}
+	return f.Close()
 }
 
 func getTiming(result *queryresult.Result, count int) *queryresult.TimingResult {
@@ -38,7 +90,7 @@ func getTiming(result *queryresult.Result, count int) *queryresult.TimingResult
 	// set rows returned
 	timingResult.RowsReturned = int64(count)
 
-	if timingConfig != pconstants.ArgVerbose {
+	if timingConfig != pconstants.ArgVerbose && timingConfig != constants.TimingJSONVerbose {
 		timingResult.Scans = nil
 	}
 	return timingResult
@@ -0,0 +1,90 @@
+package display
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pconstants "github.com/turbot/pipe-fittings/v2/constants"
+	"github.com/turbot/steampipe/v2/pkg/constants"
+	"github.com/turbot/steampipe/v2/pkg/query/queryresult"
+)
+
+func newTimingTestResult() *queryresult.Result {
+	r := queryresult.NewResult(nil)
+	r.Timing.SetTiming(&queryresult.TimingResult{
+		DurationMs:          120,
+		UncachedRowsFetched: 10,
+		CachedRowsFetched:   5,
+		HydrateCalls:        20,
+		Scans:               []*queryresult.ScanMetadataRow{{Connection: "aws", Table: "aws_s3_bucket", RowsFetched: 15}},
+	})
+	return r
+}
+
+func TestBuildTimingJSON(t *testing.T) {
+	timestamp := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	res, err := buildTimingJSON(&queryresult.TimingResult{DurationMs: 120, RowsReturned: 3, CachedRowsFetched: 5}, timestamp)
+	require.NoError(t, err)
+
+	assert.NotContains(t, res, "\n")
+	var timing map[string]any
+	require.NoError(t, json.Unmarshal([]byte(res), &timing))
+	assert.Equal(t, "2025-01-02T03:04:05Z", timing["timestamp"])
+	assert.Equal(t, float64(120), timing["duration_ms"])
+	assert.Equal(t, float64(3), timing["rows_returned"])
+	assert.Equal(t, float64(5), timing["cached_rows_fetched"])
+}
+
+func TestDisplayTiming_JSONFile(t *testing.T) {
+	tests := map[string]struct {
+		timing    string
+		wantScans int
+	}{
+		"json":         {timing: constants.TimingJSON, wantScans: 0},
+		"json-verbose": {timing: constants.TimingJSONVerbose, wantScans: 1},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer viper.Reset()
+			timingFile := filepath.Join(t.TempDir(), "timing.jsonl")
+			viper.Set(pconstants.ArgTiming, tc.timing)
+			viper.Set(constants.ArgTimingFile, timingFile)
+
+			// each query appends a line to the file
+			DisplayTiming(newTimingTestResult(), 3)
+			DisplayTiming(newTimingTestResult(), 4)
+
+			data, err := os.ReadFile(timingFile)
+			require.NoError(t, err)
+			lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+			require.Len(t, lines, 2)
+
+			var timing queryresult.TimingResult
+			require.NoError(t, json.Unmarshal([]byte(lines[1]), &timing))
+			assert.Equal(t, int64(4), timing.RowsReturned)
+			assert.Equal(t, int64(20), timing.HydrateCalls)
+			// only verbose timing includes the scans
+			assert.Len(t, timing.Scans, tc.wantScans)
+		})
+	}
+}
+
+func TestDisplayTiming_TextFile(t *testing.T) {
+	defer viper.Reset()
+	timingFile := filepath.Join(t.TempDir(), "timing.txt")
+	viper.Set(pconstants.ArgTiming, pconstants.ArgOn)
+	viper.Set(constants.ArgTimingFile, timingFile)
+
+	DisplayTiming(newTimingTestResult(), 3)
+
+	data, err := os.ReadFile(timingFile)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), "Time: 120ms. Rows returned: 3. Rows fetched: 15 (5 cached)."), string(data))
+}
@@ -0,0 +1,66 @@
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"unicode/utf8"
+
+	"github.com/spf13/viper"
+	pconstants "github.com/turbot/pipe-fittings/v2/constants"
+	"github.com/turbot/steampipe/v2/pkg/constants"
+)
+
+type CsvExporter struct {
+	ExporterBase
+}
+
+// Export writes the query result as CSV, respecting the --separator and --header settings
+func (e *CsvExporter) Export(ctx context.Context, input ExportSourceData, filePath string) error {
+	stream, err := getQueryRowStream(e.Name(), input)
+	if err != nil {
+		return err
+	}
+	return e.ExportStream(ctx, stream, filePath)
+}
+
+// ExportStream implements StreamingExporter
+func (e *CsvExporter) ExportStream(_ context.Context, stream *RowStream, filePath string) error {
+	return WriteStream(filePath, func(w io.Writer) error {
+		csvWriter := csv.NewWriter(w)
+		csvWriter.Comma = csvSeparator()
+
+		if !viper.IsSet(pconstants.ArgHeader) || viper.GetBool(pconstants.ArgHeader) {
+			if err := csvWriter.Write(columnNames(stream.Columns)); err != nil {
+				return err
+			}
+		}
+		for row := range stream.Rows {
+			rowAsString, err := rowAsStrings(row, stream.Columns)
+			if err != nil {
+				return err
+			}
+			if err := csvWriter.Write(rowAsString); err != nil {
+				return err
+			}
+		}
+		csvWriter.Flush()
+		return csvWriter.Error()
+	})
+}
+
+func (e *CsvExporter) FileExtension() string {
+	return constants.CsvExtension
+}
+
+func (e *CsvExporter) Name() string {
+	return constants.OutputFormatCSV
+}
+
+// csvSeparator returns the first rune of the configured separator, defaulting to a comma
+func csvSeparator() rune {
+	if r, _ := utf8.DecodeRuneInString(viper.GetString(pconstants.ArgSeparator)); r != utf8.RuneError {
+		return r
+	}
+	return ','
+}
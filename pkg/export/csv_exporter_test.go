@@ -0,0 +1,98 @@
+package export
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/turbot/pipe-fittings/v2/modconfig"
+	"github.com/turbot/pipe-fittings/v2/queryresult"
+	"github.com/turbot/pipe-fittings/v2/steampipeconfig"
+	"github.com/turbot/steampipe/v2/pkg/snapshot"
+)
+
+// newTestSnapshot builds a query snapshot from the given columns and rows
+func newTestSnapshot(t *testing.T, cols []*queryresult.ColumnDef, rows [][]any) *steampipeconfig.SteampipeSnapshot {
+	t.Helper()
+
+	result := queryresult.NewResult(cols, queryresult.QueryTimingMetadata{})
+	go func() {
+		defer result.Close()
+		for _, r := range rows {
+			result.StreamRow(r)
+		}
+	}()
+
+	q := &modconfig.ResolvedQuery{RawSQL: "select * from test", ExecuteSQL: "select * from test"}
+	snap, err := snapshot.QueryResultToSnapshot(context.Background(), result, q, nil, time.Now())
+	require.NoError(t, err)
+	return snap
+}
+
+func testExportCols() []*queryresult.ColumnDef {
+	return []*queryresult.ColumnDef{
+		{Name: "id", DataType: "INT8"},
+		{Name: "name", DataType: "TEXT"},
+		{Name: "tags", DataType: "JSONB"},
+	}
+}
+
+func testExportRows() [][]any {
+	return [][]any{
+		{int64(1), "alpha", map[string]any{"env": "prod"}},
+		{int64(2), "beta, gamma", nil},
+	}
+}
+
+func TestCsvExporter_Export(t *testing.T) {
+	snap := newTestSnapshot(t, testExportCols(), testExportRows())
+	target := filepath.Join(t.TempDir(), "out.csv")
+
+	err := (&CsvExporter{}).Export(context.Background(), snap, target)
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(target)
+	require.NoError(t, err)
+	expected := "id,name,tags\n1,alpha,\"{\"\"env\"\":\"\"prod\"\"}\"\n2,\"beta, gamma\",\n"
+	assert.Equal(t, expected, string(content))
+}
+
+func TestCsvExporter_Export_InvalidInput(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "out.csv")
+
+	err := (&CsvExporter{}).Export(context.Background(), &mockExportSourceData{}, target)
+	assert.Error(t, err)
+
+	_, statErr := os.Stat(target)
+	assert.True(t, os.IsNotExist(statErr), "no file should be written for invalid input")
+}
+
+func TestManager_RegisterFileExporters(t *testing.T) {
+	m := NewManager()
+	for _, e := range []Exporter{&SnapshotExporter{}, &CsvExporter{}, &JsonExporter{}, &JsonlExporter{}, &ParquetExporter{}, &SqliteExporter{}} {
+		require.NoError(t, m.Register(e))
+	}
+
+	tests := map[string]string{
+		"results.csv":     "csv",
+		"results.json":    "json",
+		"results.jsonl":   "jsonl",
+		"results.parquet": "parquet",
+		"parquet":         "parquet",
+		"results.sqlite":  "sqlite",
+		"results.sps":     "snapshot",
+		"jsonl":           "jsonl",
+	}
+	for export, expectedName := range tests {
+		target, err := m.getExportTarget(export, "query")
+		require.NoError(t, err, export)
+		assert.Equal(t, expectedName, target.exporter.Name(), export)
+	}
+
+	// multiple named exports of different formats are valid in a single run
+	assert.NoError(t, m.ValidateExportFormat([]string{"results.csv", "results.jsonl"}))
+}
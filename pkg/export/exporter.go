@@ -1,6 +1,10 @@
 package export
 
-import "context"
+import (
+	"context"
+
+	"github.com/turbot/pipe-fittings/v2/queryresult"
+)
 
 // ExportSourceData is an interface implemented by all types which can be used as an input to an exporter
 type ExportSourceData interface {
@@ -14,6 +18,35 @@ type Exporter interface {
 	Alias() string
 }
 
+// StreamingExporter is implemented by exporters which can write a query result as the rows are received,
+// so the full result never needs to be held in memory
+type StreamingExporter interface {
+	Exporter
+	// ExportStream must read from stream.Rows until it is closed, unless it returns an error
+	ExportStream(ctx context.Context, stream *RowStream, destPath string) error
+}
+
+// RunExporter is implemented by exporters which combine the exports of a run into a single file,
+// e.g. the sqlite exporter writes the result of each query of a batch run to a table of the same database
+type RunExporter interface {
+	Exporter
+	// StartRun clears the state of any previous run, so the next export to a file replaces it
+	StartRun()
+}
+
+// RowStream is a query result which is streamed to exporters one row at a time.
+// Rows are in the same format as query snapshot rows, i.e. a map of column name to value
+type RowStream struct {
+	Columns []*queryresult.ColumnDef
+	SQL     string
+	// the name of the query, if it is a named query (e.g. a saved query or a query file) - empty otherwise
+	Name string
+	Rows <-chan map[string]any
+}
+
+// IsExportSourceData implements ExportSourceData
+func (*RowStream) IsExportSourceData() {}
+
 type ExporterBase struct{}
 
 func (*ExporterBase) Alias() string {
@@ -15,6 +15,16 @@ func GenerateDefaultExportFileName(executionName, fileExtension string) string {
 }
 
 func Write(filePath string, exportData io.Reader) error {
+	return WriteStream(filePath, func(w io.Writer) error {
+		_, err := io.Copy(w, exportData)
+		return err
+	})
+}
+
+// WriteStream atomically writes a file, using writeFunc to write the content to a temporary file
+// which is then renamed to filePath. This allows exporters to write their output incrementally
+// without buffering it all in memory first.
+func WriteStream(filePath string, writeFunc func(w io.Writer) error) error {
 	// Create a temporary file in the same directory as the target file
 	// This ensures the temp file is on the same filesystem for atomic rename
 	dir := filepath.Dir(filePath)
@@ -33,8 +43,7 @@ func Write(filePath string, exportData io.Reader) error {
 	}()
 
 	// Write data to temp file
-	_, err = io.Copy(tmpFile, exportData)
-	if err != nil {
+	if err := writeFunc(tmpFile); err != nil {
 		return err
 	}
 
@@ -0,0 +1,93 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/turbot/steampipe/v2/pkg/constants"
+)
+
+type JsonExporter struct {
+	ExporterBase
+}
+
+// Export writes the query result as a single JSON document containing the column definitions and rows
+func (e *JsonExporter) Export(ctx context.Context, input ExportSourceData, filePath string) error {
+	stream, err := getQueryRowStream(e.Name(), input)
+	if err != nil {
+		return err
+	}
+	return e.ExportStream(ctx, stream, filePath)
+}
+
+// ExportStream implements StreamingExporter
+//
+// The document is written incrementally, one row at a time, but matches the `--output json` format, i.e.
+//
+//	{
+//	 "columns": [...],
+//	 "rows": [...]
+//	}
+func (e *JsonExporter) ExportStream(_ context.Context, stream *RowStream, filePath string) error {
+	return WriteStream(filePath, func(w io.Writer) error {
+		bw := bufio.NewWriter(w)
+
+		columns, err := marshalIndentedJSON(jsonColumnDefs(stream.Columns), " ")
+		if err != nil {
+			return err
+		}
+		if _, err := bw.WriteString("{\n \"columns\": " + columns + ",\n \"rows\": ["); err != nil {
+			return err
+		}
+
+		rowCount := 0
+		for row := range stream.Rows {
+			rowJSON, err := marshalIndentedJSON(row, "  ")
+			if err != nil {
+				return err
+			}
+			separator := ",\n  "
+			if rowCount == 0 {
+				separator = "\n  "
+			}
+			if _, err := bw.WriteString(separator + rowJSON); err != nil {
+				return err
+			}
+			rowCount++
+		}
+
+		// ensure we write an empty array rather than null
+		closing := "]\n}\n"
+		if rowCount > 0 {
+			closing = "\n ]\n}\n"
+		}
+		if _, err := bw.WriteString(closing); err != nil {
+			return err
+		}
+		return bw.Flush()
+	})
+}
+
+func (e *JsonExporter) FileExtension() string {
+	return constants.JsonExtension
+}
+
+func (e *JsonExporter) Name() string {
+	return constants.OutputFormatJSON
+}
+
+// marshalIndentedJSON marshals v using a single space indent and the given prefix, without escaping HTML
+func marshalIndentedJSON(v any, prefix string) (string, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetIndent(prefix, " ")
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return "", err
+	}
+	// Encode appends a newline
+	return string(bytes.TrimSuffix(buf.Bytes(), []byte("\n"))), nil
+}
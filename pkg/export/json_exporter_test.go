@@ -0,0 +1,97 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/turbot/pipe-fittings/v2/queryresult"
+)
+
+// jsonExport is the structure written by the JsonExporter - this matches the `--output json` format
+type jsonExport struct {
+	Columns []*queryresult.ColumnDef `json:"columns"`
+	Rows    []map[string]any         `json:"rows"`
+}
+
+func TestJsonExporter_Export(t *testing.T) {
+	snap := newTestSnapshot(t, testExportCols(), testExportRows())
+	target := filepath.Join(t.TempDir(), "out.json")
+
+	err := (&JsonExporter{}).Export(context.Background(), snap, target)
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(target)
+	require.NoError(t, err)
+
+	var output jsonExport
+	require.NoError(t, json.Unmarshal(content, &output))
+	require.Len(t, output.Columns, 3)
+	assert.Equal(t, "jsonb", output.Columns[2].DataType)
+	require.Len(t, output.Rows, 2)
+	assert.Equal(t, "alpha", output.Rows[0]["name"])
+	assert.Equal(t, map[string]any{"env": "prod"}, output.Rows[0]["tags"])
+	assert.Nil(t, output.Rows[1]["tags"])
+}
+
+// the streamed document should be identical to encoding the full result in one go
+func TestJsonExporter_Export_MatchesEncodedDocument(t *testing.T) {
+	for name, rows := range map[string][][]any{"rows": testExportRows(), "no rows": nil} {
+		t.Run(name, func(t *testing.T) {
+			snap := newTestSnapshot(t, testExportCols(), rows)
+			target := filepath.Join(t.TempDir(), "out.json")
+			require.NoError(t, (&JsonExporter{}).Export(context.Background(), snap, target))
+
+			panel, err := getQueryTablePanel("json", snap)
+			require.NoError(t, err)
+			expected := jsonExport{Columns: jsonColumnDefs(panel.Data.Columns), Rows: panel.Data.Rows}
+			if expected.Rows == nil {
+				expected.Rows = []map[string]any{}
+			}
+			var buf bytes.Buffer
+			encoder := json.NewEncoder(&buf)
+			encoder.SetIndent("", " ")
+			encoder.SetEscapeHTML(false)
+			require.NoError(t, encoder.Encode(expected))
+
+			content, err := os.ReadFile(target)
+			require.NoError(t, err)
+			assert.Equal(t, buf.String(), string(content))
+		})
+	}
+}
+
+func TestJsonExporter_Export_NoRows(t *testing.T) {
+	snap := newTestSnapshot(t, testExportCols(), nil)
+	target := filepath.Join(t.TempDir(), "out.json")
+
+	require.NoError(t, (&JsonExporter{}).Export(context.Background(), snap, target))
+
+	content, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"rows": []`)
+}
+
+func TestJsonlExporter_Export(t *testing.T) {
+	snap := newTestSnapshot(t, testExportCols(), testExportRows())
+	target := filepath.Join(t.TempDir(), "out.jsonl")
+
+	err := (&JsonlExporter{}).Export(context.Background(), snap, target)
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(target)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	require.Len(t, lines, 2)
+	var first map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+	assert.Equal(t, "alpha", first["name"])
+	assert.Equal(t, float64(1), first["id"])
+}
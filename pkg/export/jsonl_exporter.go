@@ -0,0 +1,45 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/turbot/steampipe/v2/pkg/constants"
+)
+
+type JsonlExporter struct {
+	ExporterBase
+}
+
+// Export writes the query result as newline-delimited JSON, with one object per row
+func (e *JsonlExporter) Export(ctx context.Context, input ExportSourceData, filePath string) error {
+	stream, err := getQueryRowStream(e.Name(), input)
+	if err != nil {
+		return err
+	}
+	return e.ExportStream(ctx, stream, filePath)
+}
+
+// ExportStream implements StreamingExporter
+func (e *JsonlExporter) ExportStream(_ context.Context, stream *RowStream, filePath string) error {
+	return WriteStream(filePath, func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+		for row := range stream.Rows {
+			// Encode appends a newline after each row
+			if err := encoder.Encode(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (e *JsonlExporter) FileExtension() string {
+	return constants.JsonlExtension
+}
+
+func (e *JsonlExporter) Name() string {
+	return constants.OutputFormatJSONL
+}
@@ -15,6 +15,9 @@ import (
 	"golang.org/x/exp/slices"
 )
 
+// the number of rows buffered for each target of a streaming export
+const streamingExportBufferSize = 100
+
 type Manager struct {
 	registeredExporters  map[string]Exporter
 	registeredExtensions map[string]Exporter
@@ -56,6 +59,18 @@ func (m *Manager) Register(exporter Exporter) error {
 	return nil
 }
 
+// StartRun starts a run of exports - exporters which combine the exports of a run into a single file
+// write a new file for the next export, rather than adding to the file written by a previous run
+func (m *Manager) StartRun() {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, exporter := range m.registeredExporters {
+		if runExporter, ok := exporter.(RunExporter); ok {
+			runExporter.StartRun()
+		}
+	}
+}
+
 func (m *Manager) registerExporterByExtension(exporter Exporter, ext string) {
 	// do we already have an exporter registered for this extension?
 	if existing, ok := m.registeredExtensions[ext]; ok {
@@ -170,6 +185,92 @@ func (m *Manager) DoExport(ctx context.Context, targetName string, source Export
 	return expLocation, error_helpers.CombineErrors(errors...)
 }
 
+// CanStreamExport returns true if all the export targets support streaming, i.e. the query result can be
+// exported using DoStreamingExport rather than by building a snapshot
+func (m *Manager) CanStreamExport(exports []string) bool {
+	for _, export := range exports {
+		export = strings.TrimSpace(export)
+		if len(export) == 0 {
+			continue
+		}
+		target, err := m.getExportTarget(export, "dummy_exec_name")
+		if err != nil {
+			return false
+		}
+		if _, ok := target.exporter.(StreamingExporter); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// DoStreamingExport exports the row stream to all the export targets concurrently, passing each row to every target
+// as it is received. All rows are read from the stream, even if exports fail
+func (m *Manager) DoStreamingExport(ctx context.Context, targetName string, stream *RowStream, exports []string) ([]string, error) {
+	if len(exports) == 0 {
+		// drain the stream so the sender is not blocked
+		for range stream.Rows {
+		}
+		return nil, nil
+	}
+
+	targets, err := m.resolveTargetsFromArgs(exports, targetName)
+	if err != nil {
+		for range stream.Rows {
+		}
+		return nil, err
+	}
+
+	type targetStream struct {
+		rows chan map[string]any
+		// closed when the target export completes
+		done chan struct{}
+		msg  string
+		err  error
+	}
+	targetStreams := make([]*targetStream, len(targets))
+	for i, target := range targets {
+		ts := &targetStream{
+			rows: make(chan map[string]any, streamingExportBufferSize),
+			done: make(chan struct{}),
+		}
+		targetStreams[i] = ts
+		go func(target *Target) {
+			defer close(ts.done)
+			ts.msg, ts.err = target.ExportStream(ctx, &RowStream{
+				Columns: stream.Columns,
+				SQL:     stream.SQL,
+				Name:    stream.Name,
+				Rows:    ts.rows,
+			})
+		}(target)
+	}
+
+	// pass each row to every target which has not yet completed
+	// NOTE: exporters only read the row so it is safe to share between them
+	for row := range stream.Rows {
+		for _, ts := range targetStreams {
+			select {
+			case ts.rows <- row:
+			case <-ts.done:
+			}
+		}
+	}
+
+	var errors []error
+	var expLocation []string
+	for _, ts := range targetStreams {
+		close(ts.rows)
+		<-ts.done
+		if ts.err != nil {
+			errors = append(errors, ts.err)
+		} else {
+			expLocation = append(expLocation, ts.msg)
+		}
+	}
+	return expLocation, error_helpers.CombineErrors(errors...)
+}
+
 // HasNamedExport returns true if any of the export arguments has a filename (--export=file.json) instead of the format name (--export=json)
 // panics if a target is not valid
 func (m *Manager) HasNamedExport(exports []string) bool {
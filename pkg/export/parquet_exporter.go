@@ -0,0 +1,45 @@
+package export
+
+import (
+	"context"
+	"io"
+
+	"github.com/turbot/steampipe/v2/pkg/constants"
+)
+
+type ParquetExporter struct {
+	ExporterBase
+}
+
+// Export writes the query result as a parquet file, mapping the postgres column types to parquet logical types
+func (e *ParquetExporter) Export(ctx context.Context, input ExportSourceData, filePath string) error {
+	stream, err := getQueryRowStream(e.Name(), input)
+	if err != nil {
+		return err
+	}
+	return e.ExportStream(ctx, stream, filePath)
+}
+
+// ExportStream implements StreamingExporter
+func (e *ParquetExporter) ExportStream(_ context.Context, stream *RowStream, filePath string) error {
+	return WriteStream(filePath, func(w io.Writer) error {
+		pw, err := newParquetRowWriter(w, stream.Columns)
+		if err != nil {
+			return err
+		}
+		for row := range stream.Rows {
+			if err := pw.writeRow(rowValues(row, stream.Columns)); err != nil {
+				return err
+			}
+		}
+		return pw.close()
+	})
+}
+
+func (e *ParquetExporter) FileExtension() string {
+	return constants.ParquetExtension
+}
+
+func (e *ParquetExporter) Name() string {
+	return constants.OutputFormatParquet
+}
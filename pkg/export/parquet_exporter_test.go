@@ -0,0 +1,192 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/turbot/pipe-fittings/v2/queryresult"
+)
+
+func openParquetFile(t *testing.T, path string) *parquet.File {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	f, err := parquet.OpenFile(bytes.NewReader(content), int64(len(content)))
+	require.NoError(t, err)
+	return f
+}
+
+func TestParquetExporter_Export(t *testing.T) {
+	cols := []*queryresult.ColumnDef{
+		{Name: "id", DataType: "INT8"},
+		{Name: "name", DataType: "TEXT"},
+		{Name: "tags", DataType: "JSONB"},
+		{Name: "created", DataType: "TIMESTAMPTZ"},
+		{Name: "ip", DataType: "INET"},
+		{Name: "cost", DataType: "NUMERIC"},
+		{Name: "aliases", DataType: "_TEXT"},
+		{Name: "ports", DataType: "_INT4"},
+	}
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	rows := [][]any{
+		// text arrays are comma separated strings, as returned by the db client
+		{int64(1), "alpha", map[string]any{"env": "prod"}, created, "10.0.0.1", 12.5, "a,b", []any{int32(80), int32(443)}},
+		{int64(2), nil, nil, nil, nil, nil, nil, nil},
+	}
+	snap := newTestSnapshot(t, cols, rows)
+	target := filepath.Join(t.TempDir(), "out.parquet")
+
+	err := (&ParquetExporter{}).Export(context.Background(), snap, target)
+	require.NoError(t, err)
+
+	f := openParquetFile(t, target)
+	assert.Equal(t, int64(2), f.NumRows())
+
+	expectedTypes := map[string]string{
+		"id":      "INT(64,true)",
+		"name":    "STRING",
+		"tags":    "JSON",
+		"created": "TIMESTAMP(isAdjustedToUTC=true,unit=MICROS)",
+		"ip":      "STRING",
+		"cost":    "DECIMAL(38,9)",
+		"aliases": "STRING",
+		"ports":   "LIST",
+	}
+	for _, field := range f.Schema().Fields() {
+		expected, ok := expectedTypes[field.Name()]
+		require.True(t, ok, "unexpected field %s", field.Name())
+		assert.Equal(t, expected, field.Type().LogicalType().String(), field.Name())
+	}
+
+	var read []map[string]any
+	reader := parquet.NewReader(f)
+	for {
+		row := map[string]any{}
+		if err := reader.Read(&row); err != nil {
+			break
+		}
+		read = append(read, row)
+	}
+	require.Len(t, read, 2)
+	assert.Equal(t, int64(1), read[0]["id"])
+	assert.Equal(t, "alpha", read[0]["name"])
+	assert.Equal(t, "10.0.0.1", read[0]["ip"])
+	assert.Nil(t, read[1]["name"])
+	assert.Equal(t, "a,b", read[0]["aliases"])
+	assert.Equal(t, []any{int32(80), int32(443)}, read[0]["ports"])
+}
+
+func TestParquetExporter_RowGroups(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := newParquetRowWriter(&buf, []*queryresult.ColumnDef{{Name: "id", DataType: "INT8"}})
+	require.NoError(t, err)
+	w.rowGroupMaxSize = 2
+	for i := 0; i < 5; i++ {
+		require.NoError(t, w.writeRow([]any{int64(i)}))
+	}
+	require.NoError(t, w.close())
+
+	f, err := parquet.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), f.NumRows())
+	assert.Len(t, f.RowGroups(), 3)
+}
+
+func TestToParquetDecimal(t *testing.T) {
+	tests := map[string]struct {
+		input    any
+		expected []byte
+	}{
+		"positive": {input: "1.5", expected: []byte{0: 0, 12: 0x59, 13: 0x68, 14: 0x2f, 15: 0x00}},
+		"negative": {input: -1.5, expected: []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xa6, 0x97, 0xd1, 0x00}},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			v, err := toParquetDecimal(tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, v.ByteArray())
+		})
+	}
+}
+
+func TestToParquetDecimal_Rounding(t *testing.T) {
+	tests := map[string]struct {
+		input    string
+		expected int64
+	}{
+		"round down":          {input: "0.0000000014", expected: 1},
+		"round up":            {input: "0.0000000016", expected: 2},
+		"half away from zero": {input: "0.0000000015", expected: 2},
+		"negative half":       {input: "-0.0000000015", expected: -2},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			v, err := toParquetDecimal(tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, twosComplementBytes(big.NewInt(tc.expected), parquetDecimalByteLength), v.ByteArray())
+		})
+	}
+}
+
+func TestToParquetDecimal_Precision(t *testing.T) {
+	// 29 integer digits and 9 fractional digits is the maximum precision
+	_, err := toParquetDecimal(strings.Repeat("9", 29) + ".999999999")
+	assert.NoError(t, err)
+	_, err = toParquetDecimal("1" + strings.Repeat("0", 29))
+	assert.ErrorContains(t, err, "too large for DECIMAL(38,9)")
+}
+
+func TestListElements(t *testing.T) {
+	tests := map[string]struct {
+		input    any
+		expected []any
+	}{
+		"slice":           {input: []any{int64(1), int64(2)}, expected: []any{int64(1), int64(2)}},
+		"empty":           {input: "", expected: nil},
+		"go slice":        {input: "[80 443]", expected: []any{"80", "443"}},
+		"empty go slice":  {input: "[]", expected: nil},
+		"single element":  {input: "2024-01-01", expected: []any{"2024-01-01"}},
+		"comma separated": {input: "2024-01-01,2024-01-02", expected: []any{"2024-01-01", "2024-01-02"}},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, listElements(tc.input))
+		})
+	}
+}
+
+func TestListElementType(t *testing.T) {
+	for _, dataType := range []string{"_INT4", "_bool", "_DATE", "_TIMESTAMPTZ"} {
+		_, isList := listElementType(dataType)
+		assert.True(t, isList, dataType)
+	}
+	// the elements of these arrays cannot be recovered from their values, so they are exported as strings
+	for _, dataType := range []string{"_TEXT", "_VARCHAR", "_JSONB", "_NUMERIC", "_UUID", "TEXT"} {
+		_, isList := listElementType(dataType)
+		assert.False(t, isList, dataType)
+	}
+	assert.Equal(t, "{foo}", arrayString("{foo}"))
+	assert.Equal(t, "[1,2]", arrayString("[1,2]"))
+	assert.Equal(t, "a,b", arrayString([]any{"a", "b"}))
+}
+
+func TestToParquetInt32_OutOfRange(t *testing.T) {
+	v, err := toParquetInt32(int64(math.MaxInt32))
+	require.NoError(t, err)
+	assert.Equal(t, int32(math.MaxInt32), v.Int32())
+
+	_, err = toParquetInt32(int64(math.MaxInt32) + 1)
+	assert.ErrorContains(t, err, "out of range")
+	_, err = toParquetInt32("-2147483649")
+	assert.ErrorContains(t, err, "out of range")
+}
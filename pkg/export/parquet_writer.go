@@ -0,0 +1,482 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/parquet-go/parquet-go"
+	typeHelpers "github.com/turbot/go-kit/types"
+	"github.com/turbot/pipe-fittings/v2/queryresult"
+)
+
+const (
+	// the number of rows written to each parquet row group
+	parquetRowGroupSize = 10000
+	// numeric columns are written as DECIMAL(38,9)
+	parquetDecimalPrecision = 38
+	parquetDecimalScale     = 9
+	// the byte length required to store a decimal of parquetDecimalPrecision
+	parquetDecimalByteLength = 16
+)
+
+// parquetValueConverter converts a single (non-null) query result value into a parquet value
+type parquetValueConverter func(v any) (parquet.Value, error)
+
+type parquetColumn struct {
+	name string
+	// the index of the leaf column in the parquet schema
+	// (this may differ from the query column index as parquet orders fields by name)
+	leafIndex int
+	isList    bool
+	convert   parquetValueConverter
+}
+
+// parquetRowWriter writes query result rows to a parquet file, flushing a row group every parquetRowGroupSize rows
+// so that the full result never needs to be buffered in memory
+type parquetRowWriter struct {
+	writer          *parquet.Writer
+	columns         []*parquetColumn
+	rowsInRowGroup  int
+	rowGroupMaxSize int
+}
+
+func newParquetRowWriter(w io.Writer, cols []*queryresult.ColumnDef) (*parquetRowWriter, error) {
+	if len(cols) == 0 {
+		return nil, fmt.Errorf("cannot write parquet file for a result with no columns")
+	}
+	group := parquet.Group{}
+	columns := make([]*parquetColumn, len(cols))
+	for i, col := range cols {
+		node, convert, isList := parquetNodeForColumn(col.DataType)
+		group[col.Name] = node
+		columns[i] = &parquetColumn{
+			name:    col.Name,
+			isList:  isList,
+			convert: convert,
+		}
+	}
+	schema := parquet.NewSchema("query_result", group)
+
+	// map each column to its leaf index - the first element of the leaf path is the column name
+	leafIndexes := make(map[string]int)
+	for idx, path := range schema.Columns() {
+		leafIndexes[path[0]] = idx
+	}
+	for _, c := range columns {
+		c.leafIndex = leafIndexes[c.name]
+	}
+
+	return &parquetRowWriter{
+		writer:          parquet.NewWriter(w, schema, parquet.Compression(&parquet.Snappy)),
+		columns:         columns,
+		rowGroupMaxSize: parquetRowGroupSize,
+	}, nil
+}
+
+// writeRow writes a row of query result values, in query column order
+func (w *parquetRowWriter) writeRow(values []any) error {
+	leafValues := make([][]parquet.Value, len(w.columns))
+	for i, c := range w.columns {
+		var v any
+		if i < len(values) {
+			v = values[i]
+		}
+		var err error
+		if c.isList {
+			leafValues[c.leafIndex], err = c.listValues(v)
+		} else {
+			leafValues[c.leafIndex], err = c.scalarValues(v)
+		}
+		if err != nil {
+			return fmt.Errorf("column '%s': %w", c.name, err)
+		}
+	}
+
+	var row parquet.Row
+	for _, v := range leafValues {
+		row = append(row, v...)
+	}
+	if _, err := w.writer.WriteRows([]parquet.Row{row}); err != nil {
+		return err
+	}
+
+	w.rowsInRowGroup++
+	if w.rowsInRowGroup >= w.rowGroupMaxSize {
+		w.rowsInRowGroup = 0
+		return w.writer.Flush()
+	}
+	return nil
+}
+
+// close flushes the final row group and writes the parquet footer
+func (w *parquetRowWriter) close() error {
+	return w.writer.Close()
+}
+
+// scalarValues returns the values for an optional scalar column
+func (c *parquetColumn) scalarValues(v any) ([]parquet.Value, error) {
+	if v == nil {
+		return []parquet.Value{parquet.NullValue().Level(0, 0, c.leafIndex)}, nil
+	}
+	pv, err := c.convert(v)
+	if err != nil {
+		return nil, err
+	}
+	return []parquet.Value{pv.Level(0, 1, c.leafIndex)}, nil
+}
+
+// listValues returns the values for an optional list column of optional elements, i.e.
+//
+//	optional group <name> (LIST) { repeated group list { optional <type> element; } }
+//
+// definition levels are: 0 - null list, 1 - empty list, 2 - null element, 3 - element present
+func (c *parquetColumn) listValues(v any) ([]parquet.Value, error) {
+	if v == nil {
+		return []parquet.Value{parquet.NullValue().Level(0, 0, c.leafIndex)}, nil
+	}
+	elements := listElements(v)
+	if len(elements) == 0 {
+		return []parquet.Value{parquet.NullValue().Level(0, 1, c.leafIndex)}, nil
+	}
+	res := make([]parquet.Value, len(elements))
+	for i, e := range elements {
+		repetitionLevel := 1
+		if i == 0 {
+			repetitionLevel = 0
+		}
+		if e == nil {
+			res[i] = parquet.NullValue().Level(repetitionLevel, 2, c.leafIndex)
+			continue
+		}
+		pv, err := c.convert(e)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = pv.Level(repetitionLevel, 3, c.leafIndex)
+	}
+	return res, nil
+}
+
+// the element types of the arrays which are exported as lists. The values of other arrays are exported as
+// strings, as they are in csv output - the db client returns text arrays as comma separated strings, so their
+// elements (which may themselves contain commas) cannot be recovered
+var listElementTypes = map[string]bool{
+	"BOOL":        true,
+	"INT2":        true,
+	"INT4":        true,
+	"INT8":        true,
+	"FLOAT4":      true,
+	"FLOAT8":      true,
+	"DATE":        true,
+	"TIMESTAMPTZ": true,
+}
+
+// listElementType returns the element type of an array data type, and whether the array is exported as a list
+func listElementType(dataType string) (string, bool) {
+	elementType, isArray := strings.CutPrefix(strings.ToUpper(dataType), "_")
+	return elementType, isArray && listElementTypes[elementType]
+}
+
+// listElements returns the elements of an array column value which is exported as a list. As well as slices, this
+// accepts the string values of the exported rows - number and bool arrays are formatted as go slices, e.g. [1 2],
+// and date and timestamptz arrays are joined with commas by the db client
+func listElements(v any) []any {
+	switch t := v.(type) {
+	case []any:
+		return t
+	case string:
+		if inner, isSlice := strings.CutPrefix(t, "["); isSlice && strings.HasSuffix(inner, "]") {
+			return stringElements(strings.Fields(strings.TrimSuffix(inner, "]")))
+		}
+		if t == "" {
+			return nil
+		}
+		return stringElements(strings.Split(t, ","))
+	default:
+		return []any{v}
+	}
+}
+
+func stringElements(elements []string) []any {
+	if len(elements) == 0 {
+		return nil
+	}
+	res := make([]any, len(elements))
+	for i, e := range elements {
+		res[i] = e
+	}
+	return res
+}
+
+// arrayString returns an array column value which is not exported as a list as a string - the elements of
+// an array value which is not already a string are joined with commas, as the db client does for text arrays
+func arrayString(v any) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case []any:
+		elements := make([]string, len(t))
+		for i, e := range t {
+			elements[i] = typeHelpers.ToString(e)
+		}
+		return strings.Join(elements, ",")
+	default:
+		return typeHelpers.ToString(v)
+	}
+}
+
+// parquetNodeForColumn returns the parquet node and value converter for a postgres data type
+func parquetNodeForColumn(dataType string) (parquet.Node, parquetValueConverter, bool) {
+	dataType = strings.ToUpper(dataType)
+	// array types are prefixed with an underscore
+	if elementType, isList := listElementType(dataType); isList {
+		node, convert := parquetLeafForDataType(elementType)
+		return parquet.Optional(parquet.List(parquet.Optional(node))), convert, true
+	}
+	if strings.HasPrefix(dataType, "_") {
+		return parquet.Optional(parquet.String()), toParquetArrayString, false
+	}
+	node, convert := parquetLeafForDataType(dataType)
+	return parquet.Optional(node), convert, false
+}
+
+func parquetLeafForDataType(dataType string) (parquet.Node, parquetValueConverter) {
+	switch dataType {
+	case "BOOL":
+		return parquet.Leaf(parquet.BooleanType), toParquetBool
+	case "INT2", "INT4":
+		return parquet.Int(32), toParquetInt32
+	case "INT8":
+		return parquet.Int(64), toParquetInt64
+	case "FLOAT4":
+		return parquet.Leaf(parquet.FloatType), toParquetFloat
+	case "FLOAT8":
+		return parquet.Leaf(parquet.DoubleType), toParquetDouble
+	case "NUMERIC":
+		return parquet.Decimal(parquetDecimalScale, parquetDecimalPrecision, parquet.FixedLenByteArrayType(parquetDecimalByteLength)), toParquetDecimal
+	case "TIMESTAMPTZ":
+		return parquet.Timestamp(parquet.Microsecond), toParquetTimestamp
+	case "TIMESTAMP":
+		return parquet.TimestampAdjusted(parquet.Microsecond, false), toParquetTimestamp
+	case "DATE":
+		return parquet.Date(), toParquetDate
+	case "JSON", "JSONB":
+		return parquet.JSON(), toParquetJSON
+	case "UUID":
+		return parquet.UUID(), toParquetUUID
+	default:
+		// text, inet, cidr, intervals etc. are all written as strings
+		return parquet.String(), toParquetString
+	}
+}
+
+func toParquetBool(v any) (parquet.Value, error) {
+	switch t := v.(type) {
+	case bool:
+		return parquet.BooleanValue(t), nil
+	case string:
+		b, err := strconv.ParseBool(t)
+		if err != nil {
+			return parquet.Value{}, err
+		}
+		return parquet.BooleanValue(b), nil
+	}
+	return parquet.Value{}, fmt.Errorf("cannot convert %T to boolean", v)
+}
+
+func toParquetInt32(v any) (parquet.Value, error) {
+	i, err := toInt64(v)
+	if err != nil {
+		return parquet.Value{}, err
+	}
+	if i < math.MinInt32 || i > math.MaxInt32 {
+		return parquet.Value{}, fmt.Errorf("value %d is out of range for a 32 bit integer", i)
+	}
+	return parquet.Int32Value(int32(i)), nil
+}
+
+func toParquetInt64(v any) (parquet.Value, error) {
+	i, err := toInt64(v)
+	if err != nil {
+		return parquet.Value{}, err
+	}
+	return parquet.Int64Value(i), nil
+}
+
+func toInt64(v any) (int64, error) {
+	switch t := v.(type) {
+	case int:
+		return int64(t), nil
+	case int16:
+		return int64(t), nil
+	case int32:
+		return int64(t), nil
+	case int64:
+		return t, nil
+	case float64:
+		return int64(t), nil
+	case string:
+		return strconv.ParseInt(t, 10, 64)
+	}
+	return 0, fmt.Errorf("cannot convert %T to integer", v)
+}
+
+func toParquetFloat(v any) (parquet.Value, error) {
+	f, err := toFloat64(v)
+	if err != nil {
+		return parquet.Value{}, err
+	}
+	return parquet.FloatValue(float32(f)), nil
+}
+
+func toParquetDouble(v any) (parquet.Value, error) {
+	f, err := toFloat64(v)
+	if err != nil {
+		return parquet.Value{}, err
+	}
+	return parquet.DoubleValue(f), nil
+}
+
+func toFloat64(v any) (float64, error) {
+	switch t := v.(type) {
+	case float32:
+		return float64(t), nil
+	case float64:
+		return t, nil
+	case string:
+		return strconv.ParseFloat(t, 64)
+	}
+	i, err := toInt64(v)
+	if err != nil {
+		return 0, fmt.Errorf("cannot convert %T to float", v)
+	}
+	return float64(i), nil
+}
+
+// toParquetDecimal converts a value to a DECIMAL(38,9) stored as a 16 byte big-endian two's complement integer
+func toParquetDecimal(v any) (parquet.Value, error) {
+	r := new(big.Rat)
+	switch t := v.(type) {
+	case string:
+		if _, ok := r.SetString(t); !ok {
+			return parquet.Value{}, fmt.Errorf("cannot convert '%s' to decimal", t)
+		}
+	default:
+		f, err := toFloat64(v)
+		if err != nil {
+			return parquet.Value{}, err
+		}
+		if r.SetFloat64(f) == nil {
+			return parquet.Value{}, fmt.Errorf("cannot convert %v to decimal", f)
+		}
+	}
+	// scale the value and round to the nearest integer, rounding halves away from zero
+	scaled := new(big.Rat).Mul(r, new(big.Rat).SetInt(pow10(parquetDecimalScale)))
+	unscaled, remainder := new(big.Int).QuoRem(scaled.Num(), scaled.Denom(), new(big.Int))
+	if new(big.Int).Lsh(new(big.Int).Abs(remainder), 1).Cmp(scaled.Denom()) >= 0 {
+		unscaled.Add(unscaled, big.NewInt(int64(scaled.Sign())))
+	}
+	// the unscaled value must have at most parquetDecimalPrecision digits
+	if new(big.Int).Abs(unscaled).Cmp(pow10(parquetDecimalPrecision)) >= 0 {
+		return parquet.Value{}, fmt.Errorf("value %s is too large for DECIMAL(%d,%d)", r.FloatString(parquetDecimalScale), parquetDecimalPrecision, parquetDecimalScale)
+	}
+	return parquet.FixedLenByteArrayValue(twosComplementBytes(unscaled, parquetDecimalByteLength)), nil
+}
+
+func pow10(n int64) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(n), nil)
+}
+
+// twosComplementBytes returns the big-endian two's complement representation of i, padded to length bytes
+func twosComplementBytes(i *big.Int, length int) []byte {
+	res := make([]byte, length)
+	if i.Sign() >= 0 {
+		i.FillBytes(res)
+		return res
+	}
+	// for negative values, add 2^(8*length)
+	offset := new(big.Int).Lsh(big.NewInt(1), uint(length*8))
+	new(big.Int).Add(offset, i).FillBytes(res)
+	return res
+}
+
+func toParquetTimestamp(v any) (parquet.Value, error) {
+	t, err := toTime(v, time.RFC3339Nano, "2006-01-02 15:04:05")
+	if err != nil {
+		return parquet.Value{}, err
+	}
+	return parquet.Int64Value(t.UnixMicro()), nil
+}
+
+func toParquetDate(v any) (parquet.Value, error) {
+	t, err := toTime(v, "2006-01-02", time.RFC3339)
+	if err != nil {
+		return parquet.Value{}, err
+	}
+	// dates are stored as the number of days since the unix epoch
+	days := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Unix() / int64((24 * time.Hour).Seconds())
+	return parquet.Int32Value(int32(days)), nil
+}
+
+func toTime(v any, layouts ...string) (time.Time, error) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, nil
+	case string:
+		for _, layout := range layouts {
+			if parsed, err := time.Parse(layout, t); err == nil {
+				return parsed, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("cannot parse '%s' as a time", t)
+	}
+	return time.Time{}, fmt.Errorf("cannot convert %T to time", v)
+}
+
+func toParquetJSON(v any) (parquet.Value, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return parquet.Value{}, err
+	}
+	return parquet.ByteArrayValue(b), nil
+}
+
+func toParquetUUID(v any) (parquet.Value, error) {
+	var u uuid.UUID
+	switch t := v.(type) {
+	case uuid.UUID:
+		u = t
+	case [16]byte:
+		u = t
+	case string:
+		parsed, err := uuid.Parse(t)
+		if err != nil {
+			return parquet.Value{}, err
+		}
+		u = parsed
+	default:
+		return parquet.Value{}, fmt.Errorf("cannot convert %T to uuid", v)
+	}
+	return parquet.FixedLenByteArrayValue(u[:]), nil
+}
+
+func toParquetArrayString(v any) (parquet.Value, error) {
+	return parquet.ByteArrayValue([]byte(arrayString(v))), nil
+}
+
+func toParquetString(v any) (parquet.Value, error) {
+	switch t := v.(type) {
+	case string:
+		return parquet.ByteArrayValue([]byte(t)), nil
+	case []byte:
+		return parquet.ByteArrayValue(t), nil
+	}
+	return parquet.ByteArrayValue([]byte(typeHelpers.ToString(v))), nil
+}
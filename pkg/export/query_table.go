@@ -0,0 +1,90 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	pconstants "github.com/turbot/pipe-fittings/v2/constants"
+	"github.com/turbot/pipe-fittings/v2/querydisplay"
+	"github.com/turbot/pipe-fittings/v2/queryresult"
+	"github.com/turbot/pipe-fittings/v2/steampipeconfig"
+	"github.com/turbot/steampipe/v2/pkg/snapshot"
+)
+
+// getQueryTablePanel extracts the query result table panel (which includes the query SQL) from a query snapshot
+func getQueryTablePanel(exporterName string, input ExportSourceData) (*snapshot.PanelData, error) {
+	snap, ok := input.(*steampipeconfig.SteampipeSnapshot)
+	if !ok {
+		return nil, fmt.Errorf("%s exporter input must be *steampipeconfig.SteampipeSnapshot", exporterName)
+	}
+	panel, ok := snap.Panels[pconstants.SnapshotQueryTableName]
+	if !ok {
+		return nil, fmt.Errorf("snapshot does not contain a query result table")
+	}
+	panelData, ok := panel.(*snapshot.PanelData)
+	if !ok {
+		return nil, fmt.Errorf("failed to read query result from snapshot")
+	}
+	return panelData, nil
+}
+
+// columnNames builds a list of column names, respecting the original name if present
+func columnNames(columns []*queryresult.ColumnDef) []string {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		if c.OriginalName != "" {
+			names[i] = c.OriginalName
+		} else {
+			names[i] = c.Name
+		}
+	}
+	return names
+}
+
+// rowValues returns the values of a snapshot row, in column order
+func rowValues(row map[string]any, columns []*queryresult.ColumnDef) []any {
+	values := make([]any, len(columns))
+	for i, c := range columns {
+		values[i] = row[c.Name]
+	}
+	return values
+}
+
+// rowAsStrings returns the values of a snapshot row as strings, in column order - nulls are returned as empty strings
+func rowAsStrings(row map[string]any, columns []*queryresult.ColumnDef) ([]string, error) {
+	return querydisplay.ColumnValuesAsString(rowValues(row, columns), columns, querydisplay.WithNullString(""))
+}
+
+// jsonColumnDefs returns a copy of the column definitions with lower case data types (as used by json query output)
+func jsonColumnDefs(columns []*queryresult.ColumnDef) []*queryresult.ColumnDef {
+	res := make([]*queryresult.ColumnDef, len(columns))
+	for i, c := range columns {
+		res[i] = &queryresult.ColumnDef{
+			Name:         c.Name,
+			OriginalName: c.OriginalName,
+			DataType:     strings.ToLower(c.DataType),
+		}
+	}
+	return res
+}
+
+// getQueryRowStream returns a RowStream for the query result table in a query snapshot
+func getQueryRowStream(exporterName string, input ExportSourceData) (*RowStream, error) {
+	panelData, err := getQueryTablePanel(exporterName, input)
+	if err != nil {
+		return nil, err
+	}
+	// the rows are already in memory, so buffer them all - this means no goroutine is needed to send them
+	rows := make(chan map[string]any, len(panelData.Data.Rows))
+	for _, row := range panelData.Data.Rows {
+		rows <- row
+	}
+	close(rows)
+
+	return &RowStream{
+		Columns: panelData.Data.Columns,
+		SQL:     panelData.SQL,
+		Name:    panelData.Title,
+		Rows:    rows,
+	}, nil
+}
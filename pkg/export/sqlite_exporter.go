@@ -0,0 +1,303 @@
+package export
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/turbot/pipe-fittings/v2/queryresult"
+	"github.com/turbot/steampipe/v2/pkg/constants"
+	_ "modernc.org/sqlite"
+)
+
+// sqliteMetadataTable is the name of the table which records the SQL and execution time of each exported query
+const sqliteMetadataTable = "steampipe_export_metadata"
+
+// SqliteExporter exports query results to a SQLite database, with one table per query.
+// Each table is named after its query, if the query is named (e.g. a saved query), or query_<n> otherwise.
+// As the exporter is called once for each query executed in a run, it tracks the files it has written to in the run -
+// the first export to a file builds a new database which atomically replaces any existing file,
+// and subsequent exports add a table to it. StartRun must be called to start each run
+type SqliteExporter struct {
+	ExporterBase
+	// map of file path to the names of the tables exported to the file in this run
+	tables map[string][]string
+	mu     sync.Mutex
+}
+
+// StartRun implements RunExporter
+func (e *SqliteExporter) StartRun() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.tables = nil
+}
+
+// Export adds a table containing the query result to the SQLite database at filePath
+func (e *SqliteExporter) Export(ctx context.Context, input ExportSourceData, filePath string) error {
+	stream, err := getQueryRowStream(e.Name(), input)
+	if err != nil {
+		return err
+	}
+	return e.ExportStream(ctx, stream, filePath)
+}
+
+// ExportStream implements StreamingExporter
+func (e *SqliteExporter) ExportStream(ctx context.Context, stream *RowStream, filePath string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return err
+	}
+	if e.tables == nil {
+		e.tables = make(map[string][]string)
+	}
+	tables := e.tables[absPath]
+	tableName := sqliteTableName(stream.Name, tables)
+
+	if len(tables) == 0 {
+		// this is the first export to this file in this run - build a new database in a temporary file,
+		// so any existing file is only replaced if the export succeeds
+		err = writeSqliteFile(absPath, func(tmpPath string) error {
+			return writeSqliteTable(ctx, tmpPath, tableName, stream)
+		})
+	} else {
+		// the table is written in a transaction, so if this fails the tables already exported are unaffected
+		err = writeSqliteTable(ctx, absPath, tableName, stream)
+	}
+	if err != nil {
+		return err
+	}
+	e.tables[absPath] = append(tables, tableName)
+	return nil
+}
+
+// writeSqliteFile atomically writes a SQLite database, using writeFunc to write the database to a temporary file
+// which is then renamed to filePath
+// NOTE: SQLite writes to a file path rather than a writer, so WriteStream cannot be used
+func writeSqliteFile(filePath string, writeFunc func(tmpPath string) error) error {
+	// create the temp file in the same directory as the target file, so the rename is atomic
+	tmpFile, err := os.CreateTemp(filepath.Dir(filePath), ".steampipe-export-*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmpFile.Name()
+	// remove the temp file on failure (the successful path will have already renamed it)
+	defer os.Remove(tmpPath)
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+
+	if err := writeFunc(tmpPath); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, filePath)
+}
+
+// sqliteTableName returns the name of the table for a query - the sanitised query name, or query_<n> if the query
+// is not named. If the name is already used by a table in the file, it is suffixed with a number
+func sqliteTableName(queryName string, existing []string) string {
+	name := sanitiseSqliteTableName(queryName)
+	if name == "" {
+		name = fmt.Sprintf("query_%d", len(existing)+1)
+	}
+	res := name
+	for i := 2; slices.Contains(existing, res) || res == sqliteMetadataTable || strings.HasPrefix(res, "sqlite_"); i++ {
+		res = fmt.Sprintf("%s_%d", name, i)
+	}
+	return res
+}
+
+// sanitiseSqliteTableName converts a query name to a table name which does not need quoting, i.e. lower case letters,
+// digits and underscores, not starting with a digit
+func sanitiseSqliteTableName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case b.Len() > 0 && !strings.HasSuffix(b.String(), "_"):
+			// replace any other characters with a single underscore
+			b.WriteRune('_')
+		}
+	}
+	res := strings.TrimSuffix(b.String(), "_")
+	if res != "" && res[0] >= '0' && res[0] <= '9' {
+		res = "q_" + res
+	}
+	return res
+}
+
+func (e *SqliteExporter) FileExtension() string {
+	return constants.SqliteExtension
+}
+
+func (e *SqliteExporter) Name() string {
+	return constants.OutputFormatSqlite
+}
+
+// writeSqliteTable creates a table for the query result and inserts the rows as they are received, within a single transaction
+func writeSqliteTable(ctx context.Context, dbPath, tableName string, stream *RowStream) (err error) {
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	cols := stream.Columns
+	if _, err = tx.ExecContext(ctx, sqliteCreateTableStatement(tableName, cols)); err != nil {
+		return fmt.Errorf("failed to create table %s: %w", tableName, err)
+	}
+
+	insert, err := tx.PrepareContext(ctx, sqliteInsertStatement(tableName, cols))
+	if err != nil {
+		return err
+	}
+	defer insert.Close()
+
+	rowCount := 0
+	for row := range stream.Rows {
+		values, err := sqliteRowValues(rowValues(row, cols), cols)
+		if err != nil {
+			return err
+		}
+		if _, err = insert.ExecContext(ctx, values...); err != nil {
+			return fmt.Errorf("failed to insert row into %s: %w", tableName, err)
+		}
+		rowCount++
+	}
+
+	createMetadata := fmt.Sprintf("create table if not exists %s (table_name text primary key, sql text, timestamp timestamp, row_count integer)", sqliteMetadataTable)
+	if _, err = tx.ExecContext(ctx, createMetadata); err != nil {
+		return err
+	}
+	insertMetadata := fmt.Sprintf("insert into %s (table_name, sql, timestamp, row_count) values (?, ?, ?, ?)", sqliteMetadataTable)
+	if _, err = tx.ExecContext(ctx, insertMetadata, tableName, stream.SQL, time.Now().Format(time.RFC3339), rowCount); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func sqliteCreateTableStatement(tableName string, cols []*queryresult.ColumnDef) string {
+	columnDefs := make([]string, len(cols))
+	for i, c := range cols {
+		columnDefs[i] = fmt.Sprintf("%s %s", sqliteQuoteIdentifier(c.Name), sqliteColumnType(c.DataType))
+	}
+	return fmt.Sprintf("create table %s (%s)", sqliteQuoteIdentifier(tableName), strings.Join(columnDefs, ", "))
+}
+
+func sqliteInsertStatement(tableName string, cols []*queryresult.ColumnDef) string {
+	columnNames := make([]string, len(cols))
+	placeholders := make([]string, len(cols))
+	for i, c := range cols {
+		columnNames[i] = sqliteQuoteIdentifier(c.Name)
+		placeholders[i] = "?"
+	}
+	return fmt.Sprintf("insert into %s (%s) values (%s)", sqliteQuoteIdentifier(tableName), strings.Join(columnNames, ", "), strings.Join(placeholders, ", "))
+}
+
+func sqliteQuoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// sqliteColumnType returns the SQLite column type for a postgres data type
+// NOTE: the declared types determine the SQLite type affinity and are also used by tools reading the file
+// to interpret the values, e.g. BOOLEAN, TIMESTAMP and JSON columns
+func sqliteColumnType(dataType string) string {
+	dataType = strings.ToUpper(dataType)
+	// arrays are stored as JSON, unless their elements cannot be recovered, in which case they are stored as text
+	if _, isList := listElementType(dataType); isList {
+		return "JSON"
+	}
+	if strings.HasPrefix(dataType, "_") {
+		return "TEXT"
+	}
+	switch dataType {
+	case "BOOL":
+		return "BOOLEAN"
+	case "INT2", "INT4", "INT8":
+		return "INTEGER"
+	case "FLOAT4", "FLOAT8":
+		return "REAL"
+	case "NUMERIC":
+		return "NUMERIC"
+	case "DATE":
+		return "DATE"
+	case "TIMESTAMP", "TIMESTAMPTZ":
+		return "TIMESTAMP"
+	case "JSON", "JSONB":
+		return "JSON"
+	default:
+		return "TEXT"
+	}
+}
+
+// sqliteListElements returns the elements of a list as json values - the elements of string array values are
+// strings, so number and bool elements are written as json numbers and bools rather than strings
+func sqliteListElements(elements []any) []any {
+	res := make([]any, len(elements))
+	for i, e := range elements {
+		res[i] = e
+		if s, ok := e.(string); ok && json.Valid([]byte(s)) {
+			res[i] = json.RawMessage(s)
+		}
+	}
+	return res
+}
+
+// sqliteRowValues converts query result values to values which can be inserted into SQLite
+func sqliteRowValues(values []any, cols []*queryresult.ColumnDef) ([]any, error) {
+	res := make([]any, len(values))
+	for i, v := range values {
+		if v == nil {
+			continue
+		}
+		dataType := strings.ToUpper(cols[i].DataType)
+		_, isList := listElementType(dataType)
+		switch {
+		case isList:
+			b, err := json.Marshal(sqliteListElements(listElements(v)))
+			if err != nil {
+				return nil, fmt.Errorf("column '%s': %w", cols[i].Name, err)
+			}
+			res[i] = string(b)
+		case strings.HasPrefix(dataType, "_"):
+			res[i] = arrayString(v)
+		case dataType == "JSON" || dataType == "JSONB":
+			b, err := json.Marshal(v)
+			if err != nil {
+				return nil, fmt.Errorf("column '%s': %w", cols[i].Name, err)
+			}
+			res[i] = string(b)
+		default:
+			switch t := v.(type) {
+			case bool, int, int16, int32, int64, float32, float64, string, []byte:
+				res[i] = t
+			case time.Time:
+				res[i] = t.Format(time.RFC3339Nano)
+			default:
+				res[i] = fmt.Sprintf("%v", t)
+			}
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,186 @@
+package export
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/turbot/pipe-fittings/v2/queryresult"
+)
+
+func TestSqliteExporter_Export(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "out.sqlite")
+	exporter := &SqliteExporter{}
+
+	// export two queries to the same file - each should be written to its own table
+	require.NoError(t, exporter.Export(context.Background(), newTestSnapshot(t, testExportCols(), testExportRows()), target))
+	arrayCols := []*queryresult.ColumnDef{
+		{Name: "active", DataType: "BOOL"},
+		{Name: "aliases", DataType: "_TEXT"},
+		{Name: "ports", DataType: "_INT4"},
+	}
+	// text arrays are comma separated strings, as returned by the db client
+	arrayRows := [][]any{{true, "a,b", []any{int32(80), int32(443)}}}
+	require.NoError(t, exporter.Export(context.Background(), newTestSnapshot(t, arrayCols, arrayRows), target))
+
+	db, err := sql.Open("sqlite", target)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// verify the column types
+	var createSQL string
+	require.NoError(t, db.QueryRow(`select sql from sqlite_master where name = 'query_1'`).Scan(&createSQL))
+	assert.Equal(t, `CREATE TABLE "query_1" ("id" INTEGER, "name" TEXT, "tags" JSON)`, createSQL)
+
+	var id int64
+	var name string
+	var tags sql.NullString
+	require.NoError(t, db.QueryRow(`select id, name, tags from query_1 where id = 1`).Scan(&id, &name, &tags))
+	assert.Equal(t, "alpha", name)
+	assert.Equal(t, `{"env":"prod"}`, tags.String)
+	require.NoError(t, db.QueryRow(`select tags from query_1 where id = 2`).Scan(&tags))
+	assert.False(t, tags.Valid)
+
+	var active bool
+	var aliases, ports string
+	require.NoError(t, db.QueryRow(`select active, aliases, ports from query_2`).Scan(&active, &aliases, &ports))
+	assert.True(t, active)
+	assert.Equal(t, "a,b", aliases)
+	assert.Equal(t, "[80,443]", ports)
+
+	// verify the metadata
+	rows, err := db.Query(`select table_name, sql, row_count from steampipe_export_metadata order by table_name`)
+	require.NoError(t, err)
+	defer rows.Close()
+	var tables []string
+	var rowCounts []int
+	for rows.Next() {
+		var tableName, query string
+		var rowCount int
+		require.NoError(t, rows.Scan(&tableName, &query, &rowCount))
+		assert.Equal(t, "select * from test", query)
+		tables = append(tables, tableName)
+		rowCounts = append(rowCounts, rowCount)
+	}
+	assert.Equal(t, []string{"query_1", "query_2"}, tables)
+	assert.Equal(t, []int{2, 1}, rowCounts)
+}
+
+func TestSqliteExporter_Export_ReplacesExistingFile(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "out.sqlite")
+	snap := newTestSnapshot(t, testExportCols(), testExportRows())
+
+	// a previous run has written to the file
+	require.NoError(t, (&SqliteExporter{}).Export(context.Background(), snap, target))
+	// a new run should replace the file rather than add to it
+	require.NoError(t, (&SqliteExporter{}).Export(context.Background(), snap, target))
+
+	db, err := sql.Open("sqlite", target)
+	require.NoError(t, err)
+	defer db.Close()
+
+	var count int
+	require.NoError(t, db.QueryRow(`select count(*) from steampipe_export_metadata`).Scan(&count))
+	assert.Equal(t, 1, count)
+}
+
+// testRowStream returns a row stream for a query with the given name
+func testRowStream(name string, cols []*queryresult.ColumnDef, rows []map[string]any) *RowStream {
+	ch := make(chan map[string]any, len(rows))
+	for _, row := range rows {
+		ch <- row
+	}
+	close(ch)
+	return &RowStream{Columns: cols, SQL: "select * from test", Name: name, Rows: ch}
+}
+
+func TestSqliteExporter_ExportStream_NamedQueries(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "out.sqlite")
+	exporter := &SqliteExporter{}
+	cols := []*queryresult.ColumnDef{{Name: "id", DataType: "INT8"}}
+	rows := []map[string]any{{"id": int64(1)}}
+
+	for _, name := range []string{"Running Instances", "", "running-instances"} {
+		require.NoError(t, exporter.ExportStream(context.Background(), testRowStream(name, cols, rows), target))
+	}
+
+	db, err := sql.Open("sqlite", target)
+	require.NoError(t, err)
+	defer db.Close()
+	var tables []string
+	dbRows, err := db.Query(`select table_name from steampipe_export_metadata order by rowid`)
+	require.NoError(t, err)
+	defer dbRows.Close()
+	for dbRows.Next() {
+		var table string
+		require.NoError(t, dbRows.Scan(&table))
+		tables = append(tables, table)
+	}
+	assert.Equal(t, []string{"running_instances", "query_2", "running_instances_2"}, tables)
+}
+
+func TestSqliteExporter_ExportStream_FailureKeepsExistingFile(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "out.sqlite")
+	require.NoError(t, (&SqliteExporter{}).Export(context.Background(), newTestSnapshot(t, testExportCols(), testExportRows()), target))
+
+	// the value cannot be converted to json, so the export fails
+	cols := []*queryresult.ColumnDef{{Name: "tags", DataType: "JSONB"}}
+	err := (&SqliteExporter{}).ExportStream(context.Background(), testRowStream("", cols, []map[string]any{{"tags": func() {}}}), target)
+	require.Error(t, err)
+
+	// the previous database is unchanged, and no temporary file is left behind
+	db, err := sql.Open("sqlite", target)
+	require.NoError(t, err)
+	defer db.Close()
+	var count int
+	require.NoError(t, db.QueryRow(`select count(*) from query_1`).Scan(&count))
+	assert.Equal(t, 2, count)
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestSqliteExporter_StartRun(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "out.sqlite")
+	exporter := &SqliteExporter{}
+	manager := NewManager()
+	require.NoError(t, manager.Register(exporter))
+	cols := []*queryresult.ColumnDef{{Name: "id", DataType: "INT8"}}
+
+	// each run replaces the file written by the previous run, even if it was deleted in between
+	manager.StartRun()
+	require.NoError(t, exporter.Export(context.Background(), newTestSnapshot(t, cols, [][]any{{int64(1)}}), target))
+	manager.StartRun()
+	require.NoError(t, os.Remove(target))
+	require.NoError(t, exporter.Export(context.Background(), newTestSnapshot(t, cols, [][]any{{int64(2)}}), target))
+
+	db, err := sql.Open("sqlite", target)
+	require.NoError(t, err)
+	defer db.Close()
+	var tables []string
+	rows, err := db.Query(`select table_name from steampipe_export_metadata`)
+	require.NoError(t, err)
+	defer rows.Close()
+	for rows.Next() {
+		var table string
+		require.NoError(t, rows.Scan(&table))
+		tables = append(tables, table)
+	}
+	assert.Equal(t, []string{"query_1"}, tables)
+	var id int64
+	require.NoError(t, db.QueryRow(`select id from query_1`).Scan(&id))
+	assert.Equal(t, int64(2), id)
+}
+
+func TestSanitiseSqliteTableName(t *testing.T) {
+	assert.Equal(t, "my_query", sanitiseSqliteTableName("my_query"))
+	assert.Equal(t, "aws_instances_2", sanitiseSqliteTableName("AWS instances (2)"))
+	assert.Equal(t, "q_2024_report", sanitiseSqliteTableName("2024-report"))
+	assert.Equal(t, "", sanitiseSqliteTableName("--"))
+	assert.Equal(t, "steampipe_export_metadata_2", sqliteTableName("steampipe_export_metadata", nil))
+}
@@ -0,0 +1,85 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingStreamExporter returns an error without reading any rows
+type failingStreamExporter struct {
+	ExporterBase
+}
+
+func (e *failingStreamExporter) Export(context.Context, ExportSourceData, string) error {
+	return errors.New("export failed")
+}
+func (e *failingStreamExporter) ExportStream(context.Context, *RowStream, string) error {
+	return errors.New("export failed")
+}
+func (e *failingStreamExporter) FileExtension() string { return ".fail" }
+func (e *failingStreamExporter) Name() string          { return "fail" }
+
+func newTestRowStream(rowCount int) *RowStream {
+	rows := make(chan map[string]any)
+	go func() {
+		defer close(rows)
+		for i := 0; i < rowCount; i++ {
+			rows <- map[string]any{"id": int64(i), "name": "row", "tags": nil}
+		}
+	}()
+	return &RowStream{Columns: testExportCols(), SQL: "select * from test", Rows: rows}
+}
+
+func TestManager_CanStreamExport(t *testing.T) {
+	m := NewManager()
+	for _, e := range []Exporter{&SnapshotExporter{}, &CsvExporter{}, &JsonlExporter{}} {
+		require.NoError(t, m.Register(e))
+	}
+
+	assert.True(t, m.CanStreamExport([]string{"out.csv", "out.jsonl"}))
+	assert.False(t, m.CanStreamExport([]string{"out.csv", "out.sps"}))
+	assert.False(t, m.CanStreamExport([]string{"out.unknown"}))
+}
+
+func TestManager_DoStreamingExport(t *testing.T) {
+	m := NewManager()
+	for _, e := range []Exporter{&CsvExporter{}, &JsonlExporter{}} {
+		require.NoError(t, m.Register(e))
+	}
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "out.csv")
+	jsonlPath := filepath.Join(dir, "out.jsonl")
+
+	msgs, err := m.DoStreamingExport(context.Background(), "query", newTestRowStream(1000), []string{csvPath, jsonlPath})
+	require.NoError(t, err)
+	assert.Len(t, msgs, 2)
+
+	for _, path := range []string{csvPath, jsonlPath} {
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.NotEmpty(t, content, path)
+	}
+}
+
+// a failing target must not block the stream or the other targets
+func TestManager_DoStreamingExport_TargetError(t *testing.T) {
+	m := NewManager()
+	for _, e := range []Exporter{&CsvExporter{}, &failingStreamExporter{}} {
+		require.NoError(t, m.Register(e))
+	}
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "out.csv")
+
+	msgs, err := m.DoStreamingExport(context.Background(), "query", newTestRowStream(1000), []string{csvPath, filepath.Join(dir, "out.fail")})
+	assert.Error(t, err)
+	assert.Len(t, msgs, 1)
+
+	_, statErr := os.Stat(csvPath)
+	assert.NoError(t, statErr)
+}
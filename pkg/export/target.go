@@ -24,3 +24,16 @@ func (t *Target) Export(ctx context.Context, input ExportSourceData) (string, er
 		return fmt.Sprintf("File exported to %s/%s", pwd, t.filePath), nil
 	}
 }
+
+// ExportStream exports the row stream using the target exporter, which must implement StreamingExporter
+func (t *Target) ExportStream(ctx context.Context, stream *RowStream) (string, error) {
+	exporter, ok := t.exporter.(StreamingExporter)
+	if !ok {
+		return "", fmt.Errorf("exporter does not support streaming")
+	}
+	if err := exporter.ExportStream(ctx, stream, t.filePath); err != nil {
+		return "", err
+	}
+	pwd, _ := os.Getwd()
+	return fmt.Sprintf("File exported to %s/%s", pwd, t.filePath), nil
+}
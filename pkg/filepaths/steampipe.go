@@ -53,6 +53,21 @@ func EnsureInternalDir() string {
 	return ensureSteampipeSubDir("internal")
 }
 
+// EnsureSavedQueriesDir returns the path to the saved queries directory (creates if missing)
+func EnsureSavedQueriesDir() string {
+	return ensureSteampipeSubDir(filepath.Join("internal", "queries"))
+}
+
+// SavedQueriesDir returns the path to the saved queries directory
+func SavedQueriesDir() string {
+	return steampipeSubDir(filepath.Join("internal", "queries"))
+}
+
+// ThemesDir returns the path to the directory containing user theme files
+func ThemesDir() string {
+	return steampipeSubDir(filepath.Join("config", "themes"))
+}
+
 // EnsureBackupsDir returns the path to the backups directory (creates if missing)
 func EnsureBackupsDir() string {
 	return ensureSteampipeSubDir("backups")
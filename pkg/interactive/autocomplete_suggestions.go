@@ -24,6 +24,8 @@ type autoCompleteSuggestions struct {
 	tablesBySchema     map[string][]prompt.Suggest
 	queriesByMod       map[string][]prompt.Suggest
 	mods               []prompt.Suggest
+	// the key columns of plugin tables, shown in column suggestions
+	keyColumns *keyColumnLookup
 }
 
 func newAutocompleteSuggestions() *autoCompleteSuggestions {
@@ -0,0 +1,169 @@
+package interactive
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/c-bata/go-prompt"
+	"github.com/turbot/pipe-fittings/v2/utils"
+	"github.com/turbot/steampipe/v2/pkg/db/db_common"
+)
+
+// completionWordSeparator is the set of characters which separate the word being completed from the preceding text,
+// so that columns can be completed directly after a comma or parenthesis
+const completionWordSeparator = " \t\n,()"
+
+// keywords which start a clause referencing columns
+var columnClauseKeywords = map[string]struct{}{
+	"select": {}, "where": {}, "on": {}, "having": {}, "by": {},
+}
+
+// keywords which start a clause which does not reference columns
+var nonColumnClauseKeywords = map[string]struct{}{
+	"from": {}, "join": {}, "limit": {}, "offset": {}, "into": {}, "using": {}, "values": {}, "with": {}, "fetch": {},
+}
+
+// isEditingColumn returns whether the text before the word being completed ends in a clause which references columns,
+// i.e. the select list, or a where, on, having, group by or order by clause
+func isEditingColumn(textBeforeWord string) bool {
+	tokens := tokenizeSQL(textBeforeWord)
+	for i := len(tokens) - 1; i >= 0; i-- {
+		token := strings.ToLower(tokens[i])
+		// a column alias is being entered
+		if token == "as" && i == len(tokens)-1 {
+			return false
+		}
+		if _, ok := columnClauseKeywords[token]; ok {
+			return true
+		}
+		if _, ok := nonColumnClauseKeywords[token]; ok {
+			return false
+		}
+	}
+	return false
+}
+
+// keyColumnLookup is used to find the key columns of plugin tables, which are shown in column suggestions
+type keyColumnLookup struct {
+	// map of connection name to plugin
+	pluginByConnection map[string]string
+	// map of plugin -> table -> column -> the 'require' setting of the list key column
+	requireByPluginTable map[string]map[string]map[string]string
+}
+
+func newKeyColumnLookup() *keyColumnLookup {
+	return &keyColumnLookup{
+		pluginByConnection:   make(map[string]string),
+		requireByPluginTable: make(map[string]map[string]map[string]string),
+	}
+}
+
+func (k *keyColumnLookup) add(plugin, table, column, require string) {
+	tables, ok := k.requireByPluginTable[plugin]
+	if !ok {
+		tables = make(map[string]map[string]string)
+		k.requireByPluginTable[plugin] = tables
+	}
+	columns, ok := tables[table]
+	if !ok {
+		columns = make(map[string]string)
+		tables[table] = columns
+	}
+	// the require setting defaults to required
+	if require == "" {
+		require = "required"
+	}
+	columns[column] = require
+}
+
+// get returns the 'require' setting of a list key column, or an empty string if the column is not a key column
+func (k *keyColumnLookup) get(connection, table, column string) string {
+	if k == nil {
+		return ""
+	}
+	return k.requireByPluginTable[k.pluginByConnection[connection]][table][column]
+}
+
+// columnSuggestions returns suggestions for the columns of the referenced tables.
+// If the word is qualified by a table name or alias, only the columns of that table are suggested, with the same qualifier
+func columnSuggestions(word string, refs []tableReference, schemaMetadata *db_common.SchemaMetadata, searchPath []string, keyColumns *keyColumnLookup) []prompt.Suggest {
+	if schemaMetadata == nil {
+		return nil
+	}
+
+	var qualifier string
+	if idx := strings.LastIndex(word, "."); idx != -1 {
+		qualifier = unquoteIdentifier(word[:idx])
+	}
+
+	var suggestions []prompt.Suggest
+	for _, ref := range refs {
+		refName := ref.name
+		if ref.alias != "" {
+			refName = ref.alias
+		}
+		if qualifier != "" && qualifier != refName {
+			continue
+		}
+		schema := resolveTableSchema(ref, schemaMetadata, searchPath)
+		if schema == "" {
+			continue
+		}
+		table := schemaMetadata.Schemas[schema][ref.name]
+
+		// sort so that suggestions for each table are grouped and ordered by name
+		names := make([]string, 0, len(table.Columns))
+		for name := range table.Columns {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			column := table.Columns[name]
+			text := sanitiseColumnName(name)
+			if qualifier != "" {
+				text = fmt.Sprintf("%s.%s", word[:strings.LastIndex(word, ".")], text)
+			}
+			// the marker is only shown in the dropdown - the output is the column name
+			marker := ""
+			require := keyColumns.get(schema, ref.name, name)
+			if require == "required" {
+				marker = " *"
+			}
+			description := columnDescription(column, require)
+			// if there are multiple tables and the column is not qualified, show which table the column belongs to
+			if len(refs) > 1 && qualifier == "" {
+				description = fmt.Sprintf("%s: %s", refName, description)
+			}
+			suggestions = append(suggestions, prompt.Suggest{Text: text + marker, Description: description, Output: text})
+		}
+	}
+	return suggestions
+}
+
+// columnDescription returns the description of a column suggestion - the column type, key column requirement and description
+func columnDescription(column db_common.ColumnSchema, require string) string {
+	description := column.Type
+	switch require {
+	case "":
+	case "required":
+		description += ", required key column"
+	case "any_of":
+		description += ", key column (any of)"
+	default:
+		description += fmt.Sprintf(", %s key column", require)
+	}
+	if column.Description != "" {
+		description = fmt.Sprintf("%s - %s", description, column.Description)
+	}
+	return description
+}
+
+// sanitiseColumnName quotes a column name if it would not otherwise be interpreted correctly
+func sanitiseColumnName(name string) string {
+	if strings.ContainsAny(name, " -.") || utils.ContainsUpper(name) {
+		return db_common.PgEscapeName(name)
+	}
+	return name
+}
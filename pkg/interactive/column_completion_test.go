@@ -0,0 +1,104 @@
+package interactive
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/c-bata/go-prompt"
+	"github.com/turbot/steampipe/v2/pkg/db/db_common"
+)
+
+func TestIsEditingColumn(t *testing.T) {
+	tests := map[string]bool{
+		"select ":                               true,
+		"select a, ":                            true,
+		"select count(":                         true,
+		"select a as ":                          false,
+		"select a as x, ":                       true,
+		"select * from ":                        false,
+		"select * from t where ":                true,
+		"select * from t where a = 'from' and ": true,
+		"select * from t join u on ":            true,
+		"select * from t order by ":             true,
+		"select * from t group by a having ":    true,
+		"select * from t limit ":                false,
+		"with ":                                 false,
+		"":                                      false,
+	}
+	for text, expected := range tests {
+		if got := isEditingColumn(text); got != expected {
+			t.Errorf("isEditingColumn(%q) = %v, expected %v", text, got, expected)
+		}
+	}
+}
+
+func TestColumnSuggestions(t *testing.T) {
+	schemaMetadata := &db_common.SchemaMetadata{
+		Schemas: map[string]map[string]db_common.TableSchema{
+			"aws": {
+				"aws_s3_bucket": {Columns: map[string]db_common.ColumnSchema{
+					"region": {Name: "region", Type: "text", Description: "The region"},
+					"name":   {Name: "name", Type: "text"},
+				}},
+			},
+			"github": {
+				"github_repo": {Columns: map[string]db_common.ColumnSchema{
+					"Full Name": {Name: "Full Name", Type: "text"},
+				}},
+			},
+		},
+	}
+	keyColumns := newKeyColumnLookup()
+	keyColumns.pluginByConnection["aws"] = "hub.steampipe.io/plugins/turbot/aws@latest"
+	keyColumns.add("hub.steampipe.io/plugins/turbot/aws@latest", "aws_s3_bucket", "name", "")
+	keyColumns.add("hub.steampipe.io/plugins/turbot/aws@latest", "aws_s3_bucket", "region", "optional")
+	searchPath := []string{"aws", "github"}
+
+	tests := []struct {
+		name     string
+		word     string
+		refs     []tableReference
+		expected []prompt.Suggest
+	}{
+		{
+			name: "single table",
+			refs: []tableReference{{name: "aws_s3_bucket"}},
+			expected: []prompt.Suggest{
+				{Text: "name *", Description: "text, required key column", Output: "name"},
+				{Text: "region", Description: "text, optional key column - The region", Output: "region"},
+			},
+		},
+		{
+			name: "multiple tables",
+			refs: []tableReference{{name: "aws_s3_bucket", alias: "b"}, {schema: "github", name: "github_repo"}, {name: "unknown"}},
+			expected: []prompt.Suggest{
+				{Text: "name *", Description: "b: text, required key column", Output: "name"},
+				{Text: "region", Description: "b: text, optional key column - The region", Output: "region"},
+				{Text: `"Full Name"`, Description: "github_repo: text", Output: `"Full Name"`},
+			},
+		},
+		{
+			name: "qualified by alias",
+			word: "b.re",
+			refs: []tableReference{{name: "aws_s3_bucket", alias: "b"}, {name: "github_repo"}},
+			expected: []prompt.Suggest{
+				{Text: "b.name *", Description: "text, required key column", Output: "b.name"},
+				{Text: "b.region", Description: "text, optional key column - The region", Output: "b.region"},
+			},
+		},
+		{
+			name:     "qualified by unknown table",
+			word:     "x.",
+			refs:     []tableReference{{name: "aws_s3_bucket"}},
+			expected: nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := columnSuggestions(test.word, test.refs, schemaMetadata, searchPath, keyColumns)
+			if !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/alecthomas/chroma/lexers"
 	"github.com/alecthomas/chroma/styles"
 	"github.com/c-bata/go-prompt"
+	"github.com/turbot/steampipe/v2/pkg/display"
 )
 
 // TestNewHighlighter tests highlighter creation
@@ -152,7 +153,11 @@ func TestGetHighlighter(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			h := getHighlighter(tt.theme)
+			theme, err := display.LoadTheme(tt.theme)
+			if err != nil {
+				t.Fatalf("LoadTheme returned error: %v", err)
+			}
+			h := getHighlighter(theme)
 
 			if h == nil {
 				t.Fatal("getHighlighter returned nil")
@@ -0,0 +1,66 @@
+package interactive
+
+import (
+	pqueryresult "github.com/turbot/pipe-fittings/v2/queryresult"
+	"github.com/turbot/steampipe/v2/pkg/query/queryresult"
+)
+
+// maxRetainedRows is the maximum number of rows of a result which are kept in memory so it can be exported with .export.
+// The rows of larger results are discarded as they are read
+const maxRetainedRows = 100000
+
+// resultRowCounter counts the rows of a result as they are displayed, so they can be recorded in the history.
+// The rows are also kept, up to a limit, so the result can be exported with .export
+type resultRowCounter struct {
+	rows int64
+	// the rows of the result - nil if the result had more rows than the limit
+	data [][]any
+	// whether the result had more rows than the limit, so its rows were discarded
+	tooLarge bool
+	// the first row error, if any
+	err  error
+	done chan struct{}
+}
+
+// newCountedResult returns a result which streams the rows of the given result, counting them as they are read.
+// At most maxRows rows are kept - if maxRows is 0, all rows are kept.
+// The counts are available from the counter once the returned result has been fully read
+func newCountedResult(result *pqueryresult.Result[queryresult.TimingResultStream], maxRows int) (*pqueryresult.Result[queryresult.TimingResultStream], *resultRowCounter) {
+	counted := pqueryresult.NewResult(result.Cols, result.Timing)
+	counter := &resultRowCounter{done: make(chan struct{})}
+	go func() {
+		defer close(counter.done)
+		defer counted.Close()
+		for row := range result.RowChan {
+			if row.Error != nil {
+				if counter.err == nil {
+					counter.err = row.Error
+				}
+			} else {
+				counter.rows++
+				counter.retain(row.Data, maxRows)
+			}
+			counted.RowChan <- row
+		}
+	}()
+	return counted, counter
+}
+
+// retain keeps the row, unless the result has more than maxRows rows, in which case all the rows are discarded
+func (c *resultRowCounter) retain(row []any, maxRows int) {
+	if c.tooLarge {
+		return
+	}
+	if maxRows > 0 && len(c.data) >= maxRows {
+		c.tooLarge = true
+		c.data = nil
+		return
+	}
+	c.data = append(c.data, row)
+}
+
+// wait waits for all rows to be read, and returns the number of rows and the first row error
+func (c *resultRowCounter) wait() (int64, error) {
+	<-c.done
+	return c.rows, c.err
+}
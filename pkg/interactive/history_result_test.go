@@ -0,0 +1,54 @@
+package interactive
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	pqueryresult "github.com/turbot/pipe-fittings/v2/queryresult"
+	"github.com/turbot/steampipe/v2/pkg/query/queryresult"
+)
+
+func countTestRows(rowCount, maxRows int) *resultRowCounter {
+	result := queryresult.NewResult([]*pqueryresult.ColumnDef{{Name: "id", DataType: "INT8"}})
+	go func() {
+		for i := 0; i < rowCount; i++ {
+			result.StreamRow([]any{int64(i)})
+		}
+		result.Close()
+	}()
+	counted, counter := newCountedResult(result.Result, maxRows)
+	for range counted.RowChan {
+	}
+	return counter
+}
+
+func TestNewCountedResult_RetainsRows(t *testing.T) {
+	counter := countTestRows(3, 3)
+	rows, err := counter.wait()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), rows)
+	assert.Equal(t, [][]any{{int64(0)}, {int64(1)}, {int64(2)}}, counter.data)
+	assert.False(t, counter.tooLarge)
+}
+
+func TestNewCountedResult_DiscardsRowsOverLimit(t *testing.T) {
+	counter := countTestRows(4, 3)
+	rows, err := counter.wait()
+	assert.NoError(t, err)
+	// all rows are counted, but none are kept
+	assert.Equal(t, int64(4), rows)
+	assert.Nil(t, counter.data)
+	assert.True(t, counter.tooLarge)
+}
+
+func TestNewCountedResult_NoLimit(t *testing.T) {
+	counter := countTestRows(4, 0)
+	_, _ = counter.wait()
+	assert.Len(t, counter.data, 4)
+	assert.False(t, counter.tooLarge)
+}
+
+func TestLastResult_TooLarge(t *testing.T) {
+	_, err := (&lastResult{tooLarge: true}).snapshot()
+	assert.ErrorContains(t, err, "the last query result was not kept as it returned more than 100000 rows")
+}
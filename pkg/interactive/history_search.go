@@ -0,0 +1,149 @@
+package interactive
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/c-bata/go-prompt"
+)
+
+// historySearch implements a Ctrl-R style incremental reverse search of the query history.
+// While a search is active, typed text is added to the search term and the buffer shows the most recent matching query
+type historySearch struct {
+	// function returning the queries of the history, oldest first
+	getHistory func() []string
+
+	active bool
+	term   string
+	// the index of the current match in the history - len(history) if there is no match
+	matchIndex int
+	// the text shown in the buffer for the current match
+	matchText string
+	// the text of the buffer when the search was started - restored if the search is cancelled
+	originalText string
+	// is there no match for the term
+	failing bool
+}
+
+func newHistorySearch(getHistory func() []string) *historySearch {
+	return &historySearch{getHistory: getHistory}
+}
+
+// prefix returns the prompt prefix to show while searching
+func (s *historySearch) prefix() string {
+	if s.failing {
+		return fmt.Sprintf("(failing reverse-i-search)`%s': ", s.term)
+	}
+	return fmt.Sprintf("(reverse-i-search)`%s': ", s.term)
+}
+
+// searchOlder is bound to Ctrl-R - it starts a search, or finds the next older match if a search is active
+func (s *historySearch) searchOlder(b *prompt.Buffer) {
+	if !s.active {
+		history := s.getHistory()
+		s.active = true
+		s.failing = false
+		s.originalText = b.Text()
+		s.term = b.Text()
+		s.matchIndex = len(history)
+		s.matchText = b.Text()
+		if s.term != "" {
+			s.search(b, len(history)-1, "")
+		}
+		return
+	}
+	if s.term != "" {
+		// skip older executions of the current match
+		s.search(b, s.matchIndex-1, s.matchText)
+	}
+}
+
+// onInput is called after text has been inserted into the buffer, and adds the text to the search term
+func (s *historySearch) onInput(b *prompt.Buffer) {
+	if !s.active {
+		return
+	}
+	text := b.Text()
+	// if the buffer has been changed other than by typing after the match, stop searching
+	if !strings.HasPrefix(text, s.matchText) {
+		s.stop()
+		return
+	}
+	s.term += strings.TrimPrefix(text, s.matchText)
+	// the current match may still match the longer term
+	s.search(b, s.matchIndex, "")
+}
+
+// onBackspace is called after a character has been deleted from the buffer, and removes the last character of the search term
+func (s *historySearch) onBackspace(b *prompt.Buffer) {
+	if !s.active {
+		return
+	}
+	if term := []rune(s.term); len(term) > 0 {
+		s.term = string(term[:len(term)-1])
+	}
+	history := s.getHistory()
+	if s.term == "" {
+		s.failing = false
+		s.matchIndex = len(history)
+		s.matchText = ""
+		replaceBufferText(b, "")
+		return
+	}
+	s.search(b, len(history)-1, "")
+}
+
+// cancel stops the search, restoring the text which was in the buffer when the search started
+func (s *historySearch) cancel(b *prompt.Buffer) {
+	if !s.active {
+		return
+	}
+	replaceBufferText(b, s.originalText)
+	s.stop()
+}
+
+// stop stops the search, leaving the current match in the buffer
+func (s *historySearch) stop() {
+	s.active = false
+	s.failing = false
+	s.term = ""
+}
+
+// search finds the most recent query containing the search term, starting at index 'from' and searching backwards,
+// and shows it in the buffer. If there is no match, the buffer is left showing the previous match
+func (s *historySearch) search(b *prompt.Buffer, from int, skip string) {
+	history := s.getHistory()
+	idx := findInHistory(history, s.term, from, skip)
+	if idx == -1 {
+		s.failing = true
+		replaceBufferText(b, s.matchText)
+		return
+	}
+	s.failing = false
+	s.matchIndex = idx
+	s.matchText = history[idx]
+	replaceBufferText(b, s.matchText)
+}
+
+// findInHistory returns the index of the most recent query at or before 'from' containing the term
+// (case-insensitive), or -1 if there is none. Queries equal to 'skip' are ignored
+func findInHistory(history []string, term string, from int, skip string) int {
+	term = strings.ToLower(term)
+	for i := min(from, len(history)-1); i >= 0; i-- {
+		if history[i] != skip && strings.Contains(strings.ToLower(history[i]), term) {
+			return i
+		}
+	}
+	return -1
+}
+
+// replaceBufferText replaces the text of the buffer, leaving the cursor at the end
+func replaceBufferText(b *prompt.Buffer, text string) {
+	// move the cursor to the end of the buffer so the whole text can be deleted
+	for !b.Document().OnLastLine() {
+		b.CursorDown(1)
+	}
+	b.CursorRight(len([]rune(b.Document().CurrentLineAfterCursor())))
+	b.DeleteBeforeCursor(len([]rune(b.Text())))
+	b.InsertText(text, false, true)
+}
@@ -0,0 +1,85 @@
+package interactive
+
+import (
+	"testing"
+
+	"github.com/c-bata/go-prompt"
+)
+
+func TestHistorySearch(t *testing.T) {
+	history := []string{
+		"select * from aws_s3_bucket",
+		"select * from aws_account",
+		"select * from github_repo",
+		"select * from aws_account",
+		".tables",
+	}
+	s := newHistorySearch(func() []string { return history })
+	b := prompt.NewBuffer()
+
+	// type a character then apply the key binding, as go-prompt does
+	typeText := func(text string) {
+		b.InsertText(text, false, true)
+		s.onInput(b)
+	}
+	expect := func(step, term, text string, failing bool) {
+		t.Helper()
+		if s.term != term || b.Text() != text || s.failing != failing {
+			t.Errorf("%s: expected term %q, buffer %q, failing %v - got term %q, buffer %q, failing %v", step, term, text, failing, s.term, b.Text(), s.failing)
+		}
+	}
+
+	s.searchOlder(b)
+	if !s.active {
+		t.Fatal("expected Ctrl-R to start a search")
+	}
+	expect("start", "", "", false)
+
+	typeText("a")
+	expect("type a", "a", ".tables", false)
+
+	typeText("ws")
+	expect("type ws", "aws", "select * from aws_account", false)
+
+	// Ctrl-R again skips the older execution of the same query
+	s.searchOlder(b)
+	expect("search older", "aws", "select * from aws_s3_bucket", false)
+
+	s.searchOlder(b)
+	expect("no older match", "aws", "select * from aws_s3_bucket", true)
+
+	typeText("x")
+	expect("no match for longer term", "awsx", "select * from aws_s3_bucket", true)
+
+	b.DeleteBeforeCursor(1)
+	s.onBackspace(b)
+	expect("backspace", "aws", "select * from aws_account", false)
+
+	s.cancel(b)
+	if s.active {
+		t.Error("expected Ctrl-G to stop the search")
+	}
+	if b.Text() != "" {
+		t.Errorf("expected Ctrl-G to restore the original text, got %q", b.Text())
+	}
+}
+
+func TestHistorySearch_StartWithText(t *testing.T) {
+	history := []string{"select * from github_repo", "select 1"}
+	s := newHistorySearch(func() []string { return history })
+	b := prompt.NewBuffer()
+	b.InsertText("github", false, true)
+
+	s.searchOlder(b)
+	if b.Text() != "select * from github_repo" {
+		t.Errorf("expected the buffer text to be used as the search term, got %q", b.Text())
+	}
+
+	// editing the buffer other than by typing after the match stops the search
+	b.CursorLeft(4)
+	b.InsertText("x", false, true)
+	s.onInput(b)
+	if s.active {
+		t.Error("expected editing the match to stop the search")
+	}
+}
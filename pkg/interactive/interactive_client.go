@@ -15,7 +15,6 @@ import (
 
 	"github.com/alecthomas/chroma/formatters"
 	"github.com/alecthomas/chroma/lexers"
-	"github.com/alecthomas/chroma/styles"
 	"github.com/c-bata/go-prompt"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/spf13/viper"
@@ -28,10 +27,14 @@ import (
 	"github.com/turbot/steampipe/v2/pkg/connection_sync"
 	"github.com/turbot/steampipe/v2/pkg/constants"
 	"github.com/turbot/steampipe/v2/pkg/db/db_common"
+	"github.com/turbot/steampipe/v2/pkg/display"
 	"github.com/turbot/steampipe/v2/pkg/error_helpers"
+	"github.com/turbot/steampipe/v2/pkg/export"
 	"github.com/turbot/steampipe/v2/pkg/interactive/metaquery"
 	"github.com/turbot/steampipe/v2/pkg/query"
 	"github.com/turbot/steampipe/v2/pkg/query/queryhistory"
+	"github.com/turbot/steampipe/v2/pkg/query/queryresult"
+	"github.com/turbot/steampipe/v2/pkg/query/transcript"
 	"github.com/turbot/steampipe/v2/pkg/statushooks"
 	"github.com/turbot/steampipe/v2/pkg/steampipeconfig"
 )
@@ -68,17 +71,33 @@ type InteractiveClient struct {
 	// the schema metadata - this is loaded asynchronously during init
 	schemaMetadata *db_common.SchemaMetadata
 	highlighter    *Highlighter
+	// the theme used to highlight sql and show table output
+	theme *display.Theme
 	// hidePrompt is used to render a blank as the prompt prefix
 	hidePrompt bool
+	// Ctrl-R search of the query history
+	historySearch *historySearch
+	// the lines of the multi-line query which were entered before the current metaquery
+	incompleteQuery string
+	// text to load into the prompt buffer when the prompt restarts
+	promptText string
+	// the result of the last successful query - nil if the last query failed
+	lastResult *lastResult
+	// the cache usage of each connection over the queries of the session
+	cacheStats *queryresult.CacheStats
+	// the key binding mode of the prompt, and any remapped keys
+	keyBindings *keyBindings
+	// records the session to a transcript file - nil if the session is not being recorded
+	recorder *transcript.Recorder
 
 	suggestions *autoCompleteSuggestions
 }
 
-func getHighlighter(theme string) *Highlighter {
+func getHighlighter(theme *display.Theme) *Highlighter {
 	return newHighlighter(
 		lexers.Get("sql"),
 		formatters.Get("terminal256"),
-		styles.Native,
+		theme.Style,
 	)
 }
 
@@ -94,8 +113,20 @@ func newInteractiveClient(ctx context.Context, initData *query.InitData, result
 		interactiveBuffer:       []string{},
 		autocompleteOnEmpty:     false,
 		initResultChan:          make(chan *db_common.InitResult, 1),
-		highlighter:             getHighlighter(viper.GetString(pconstants.ArgTheme)),
 		suggestions:             newAutocompleteSuggestions(),
+		cacheStats:              queryresult.NewCacheStats(),
+	}
+	c.historySearch = newHistorySearch(interactiveQueryHistory.Get)
+	theme, err := display.LoadTheme(viper.GetString(pconstants.ArgTheme))
+	if err != nil {
+		error_helpers.ShowWarning(fmt.Sprintf("using the default theme: %s", err.Error()))
+		theme = display.DefaultTheme()
+	}
+	c.setTheme(theme)
+	c.keyBindings, err = newKeyBindings(viper.GetString(constants.ArgKeyBindMode), viper.GetStringMapString(constants.ArgKeyBindings))
+	if err != nil {
+		error_helpers.ShowWarning(fmt.Sprintf("using the default key bindings: %s", err.Error()))
+		c.keyBindings, _ = newKeyBindings(constants.KeyBindModeEmacs, nil)
 	}
 
 	// asynchronously wait for init to complete
@@ -122,6 +153,11 @@ func (c *InteractiveClient) InteractivePrompt(parentContext context.Context) {
 		quitChannel <- true
 		close(quitChannel)
 
+		// stop any recording of the session
+		if err := c.record(""); err != nil {
+			error_helpers.ShowError(ctx, err)
+		}
+
 		// cleanup the init data to ensure any services we started are stopped
 		c.initData.Cleanup(ctx)
 
@@ -224,26 +260,39 @@ func (c *InteractiveClient) runInteractivePrompt(ctx context.Context) {
 	completer := func(d prompt.Document) []prompt.Suggest {
 		return c.queryCompleter(d)
 	}
+	// load any text set by a metaquery (e.g. .edit) into the buffer
+	initialText := c.promptText
+	c.promptText = ""
 	c.interactivePrompt = prompt.New(
 		callExecutor,
 		completer,
 		prompt.OptionTitle("steampipe interactive client "),
+		prompt.OptionInitialBufferText(initialText),
 		prompt.OptionLivePrefix(func() (prefix string, useLive bool) {
 			prefix = "> "
 			useLive = true
 			if len(c.interactiveBuffer) > 0 {
 				prefix = ">>  "
 			}
+			if c.historySearch.active {
+				prefix = c.historySearch.prefix()
+			}
 			if c.hidePrompt {
 				prefix = ""
 			}
 			return
 		}),
-		prompt.OptionFormatter(c.highlighter.Highlight),
+		// use the highlighter of the current theme, as the theme may be changed while the prompt is open
+		prompt.OptionFormatter(func(d prompt.Document) ([]byte, error) { return c.highlighter.Highlight(d) }),
 		prompt.OptionHistory(c.interactiveQueryHistory.Get()),
 		prompt.OptionInputTextColor(prompt.DefaultColor),
 		prompt.OptionPrefixTextColor(prompt.DefaultColor),
 		prompt.OptionMaxSuggestion(20),
+		prompt.OptionCompletionWordSeparator(completionWordSeparator),
+		// apply the configured key bindings to the input
+		prompt.OptionParser(c.keyBindings.newParser(prompt.NewStandardInputParser())),
+		prompt.OptionSwitchKeyBindMode(c.keyBindings.promptMode()),
+		prompt.OptionAddASCIICodeBind(c.keyActionBinds()...),
 		// Known Key Bindings
 		prompt.OptionAddKeyBind(prompt.KeyBind{
 			Key: prompt.ControlC,
@@ -257,9 +306,24 @@ func (c *InteractiveClient) runInteractivePrompt(ctx context.Context) {
 				}
 			},
 		}),
+		// history search (Ctrl-R by default) is bound in keyActionBinds
+		prompt.OptionAddKeyBind(prompt.KeyBind{
+			Key: prompt.ControlG,
+			Fn:  c.historySearch.cancel,
+		}),
+		// NotDefined is the key for typed text
+		prompt.OptionAddKeyBind(prompt.KeyBind{
+			Key: prompt.NotDefined,
+			Fn:  c.historySearch.onInput,
+		}),
+		prompt.OptionAddKeyBind(prompt.KeyBind{
+			Key: prompt.Backspace,
+			Fn:  c.historySearch.onBackspace,
+		}),
 		prompt.OptionAddKeyBind(prompt.KeyBind{
 			Key: prompt.Tab,
 			Fn: func(b *prompt.Buffer) {
+				c.historySearch.stop()
 				if len(b.Text()) == 0 {
 					c.autocompleteOnEmpty = true
 				} else {
@@ -270,6 +334,7 @@ func (c *InteractiveClient) runInteractivePrompt(ctx context.Context) {
 		prompt.OptionAddKeyBind(prompt.KeyBind{
 			Key: prompt.Escape,
 			Fn: func(b *prompt.Buffer) {
+				c.historySearch.stop()
 				if len(b.Text()) == 0 {
 					c.autocompleteOnEmpty = false
 				}
@@ -353,6 +418,9 @@ func (c *InteractiveClient) executor(ctx context.Context, line string) {
 
 	line = strings.TrimSpace(line)
 
+	// executing the line ends any history search
+	c.historySearch.stop()
+
 	resolvedQuery := c.getQuery(ctx, line)
 	if resolvedQuery == nil {
 		// we failed to resolve a query, or are in the middle of a multi-line entry
@@ -361,7 +429,8 @@ func (c *InteractiveClient) executor(ctx context.Context, line string) {
 		return
 	}
 
-	// we successfully retrieved a query
+	// we successfully retrieved a query - getQuery will have added it to the history
+	historyEntry := c.interactiveQueryHistory.Peek()
 
 	// create a  context for the execution of the query
 	queryCtx := c.createQueryContext(ctx)
@@ -370,7 +439,10 @@ func (c *InteractiveClient) executor(ctx context.Context, line string) {
 		c.hidePrompt = true
 		c.interactivePrompt.Render()
 
-		if err := c.executeMetaquery(queryCtx, resolvedQuery.ExecuteSQL); err != nil {
+		t := time.Now()
+		err := c.executeMetaquery(queryCtx, resolvedQuery.ExecuteSQL)
+		historyEntry.SetResult(time.Since(t), 0, nil, err)
+		if err != nil {
 			error_helpers.ShowError(ctx, err)
 		}
 		c.hidePrompt = false
@@ -382,33 +454,100 @@ func (c *InteractiveClient) executor(ctx context.Context, line string) {
 		defer statushooks.Done(ctx)
 		statushooks.SetStatus(ctx, "Executing query…")
 		// otherwise execute query
-		c.executeQuery(ctx, queryCtx, resolvedQuery)
+		c.executeQuery(ctx, queryCtx, resolvedQuery, historyEntry)
 	}
 
 	// restart the prompt
 	c.restartInteractiveSession()
 }
 
-func (c *InteractiveClient) executeQuery(ctx context.Context, queryCtx context.Context, resolvedQuery *modconfig.ResolvedQuery) {
+// executeQuery executes the query and streams the result to the display, recording the result in the history entry
+func (c *InteractiveClient) executeQuery(ctx context.Context, queryCtx context.Context, resolvedQuery *modconfig.ResolvedQuery, historyEntry *queryhistory.HistoryEntry) {
+	t := time.Now()
 	// if there is a custom search path, wait until the first connection of each plugin has loaded
 	if customSearchPath := c.client().GetCustomSearchPath(); customSearchPath != nil {
 		if err := connection_sync.WaitForSearchPathSchemas(ctx, c.client(), customSearchPath); err != nil {
 			error_helpers.ShowError(ctx, err)
+			historyEntry.SetResult(time.Since(t), 0, nil, err)
+			c.recordQuery(resolvedQuery, t, nil, nil, err)
 			return
 		}
 	}
 
-	t := time.Now()
-	result, err := c.client().Execute(queryCtx, resolvedQuery.ExecuteSQL, resolvedQuery.Args...)
+	connections := getQueryConnections(resolvedQuery.ExecuteSQL, c.schemaMetadata, c.client().GetRequiredSessionSearchPath())
+
+	t = time.Now()
+	// execute in a session acquired here, rather than using Execute, so that the scan metadata of the query
+	// can be read from the session once the query is complete
+	var result *queryresult.Result
+	sessionResult := c.client().AcquireSession(queryCtx)
+	err := sessionResult.Error
+	if err == nil {
+		defer func() {
+			// we need to do this in a closure, otherwise the ctx will be evaluated immediately
+			// and not in call-time
+			sessionResult.Session.Close(error_helpers.IsContextCanceled(queryCtx))
+		}()
+		queryComplete := make(chan struct{})
+		result, err = c.client().ExecuteInSession(queryCtx, sessionResult.Session, func() { close(queryComplete) }, resolvedQuery.ExecuteSQL, resolvedQuery.Args...)
+		if err == nil {
+			defer func() {
+				<-queryComplete
+				c.updateCacheStats(queryCtx, sessionResult.Session)
+			}()
+		}
+	}
 	if err != nil {
-		error_helpers.ShowError(ctx, error_helpers.HandleCancelError(err))
+		err = error_helpers.HandleCancelError(err)
+		error_helpers.ShowError(ctx, err)
 		// if timing flag is enabled, show the time taken for the query to fail
 		if cmdconfig.Viper().GetString(pconstants.ArgTiming) != pconstants.ArgOff {
 			querydisplay.DisplayErrorTiming(t)
 		}
+		historyEntry.SetResult(time.Since(t), 0, connections, err)
+		c.recordQuery(resolvedQuery, t, nil, nil, err)
+		c.lastResult = nil
 	} else {
-		c.promptResult.Streamer.StreamResult(result.Result)
+		// while the session is being recorded, all rows are kept as they are written to the transcript,
+		// otherwise only as many rows as can be kept for .export
+		maxRows := maxRetainedRows
+		if c.recorder != nil {
+			maxRows = 0
+		}
+		countedResult, counter := newCountedResult(result.Result, maxRows)
+		c.promptResult.Streamer.StreamResult(countedResult)
+		rows, err := counter.wait()
+		historyEntry.SetResult(time.Since(t), rows, connections, err)
+		c.recordQuery(resolvedQuery, t, result.Cols, counter.data, err)
+
+		// keep the result, so it can be exported
+		c.lastResult = nil
+		if err == nil {
+			last := &lastResult{
+				query:      resolvedQuery,
+				cols:       result.Cols,
+				rows:       counter.data,
+				searchPath: c.client().GetRequiredSessionSearchPath(),
+				startTime:  t,
+				endTime:    time.Now(),
+			}
+			// while recording all rows are kept, but the rows of a result over the limit are still not kept for export
+			if counter.tooLarge || len(counter.data) > maxRetainedRows {
+				last.rows, last.tooLarge = nil, true
+			}
+			c.lastResult = last
+		}
+	}
+}
+
+// updateCacheStats adds the scans of the query which has just completed in the session to the cache stats
+func (c *InteractiveClient) updateCacheStats(ctx context.Context, session *db_common.DatabaseSession) {
+	summaries, err := db_common.LoadConnectionScanSummaries(ctx, session.Connection.Conn())
+	if err != nil {
+		log.Printf("[WARN] updateCacheStats: failed to read scan metadata, err: %s", err)
+		return
 	}
+	c.cacheStats.Add(summaries)
 }
 
 func (c *InteractiveClient) getQuery(ctx context.Context, line string) *modconfig.ResolvedQuery {
@@ -419,10 +558,14 @@ func (c *InteractiveClient) getQuery(ctx context.Context, line string) *modconfi
 
 	// store the history (the raw line which was entered)
 	historyEntry := line
+	var resolveErr error
 	defer func() {
 		if len(historyEntry) > 0 {
 			// we want to store even if we fail to resolve a query
-			c.interactiveQueryHistory.Push(historyEntry)
+			entry := c.interactiveQueryHistory.Push(historyEntry)
+			if resolveErr != nil {
+				entry.SetResult(0, 0, nil, resolveErr)
+			}
 		}
 
 	}()
@@ -461,6 +604,8 @@ func (c *InteractiveClient) getQuery(ctx context.Context, line string) *modconfi
 	// check if the contents in the buffer evaluates to a metaquery
 	if metaquery.IsMetaQuery(line) {
 		// this is a metaquery
+		// keep any multi-line query which was being entered, so the metaquery can use it (e.g. .edit)
+		c.incompleteQuery = strings.Join(c.interactiveBuffer[:len(c.interactiveBuffer)-1], "\n")
 		// clear the interactive buffer
 		c.interactiveBuffer = nil
 		return &modconfig.ResolvedQuery{
@@ -478,6 +623,7 @@ func (c *InteractiveClient) getQuery(ctx context.Context, line string) *modconfi
 		// - clear interactive buffer
 		c.interactiveBuffer = nil
 		error_helpers.ShowError(ctx, err)
+		resolveErr = err
 		return nil
 	}
 
@@ -530,8 +676,13 @@ func (c *InteractiveClient) executeMetaquery(ctx context.Context, query string)
 	}
 	client := c.client()
 
-	// validation passed, now we will run
-	return metaquery.Handle(ctx, &metaquery.HandlerInput{
+	// record the metaquery, with any settings it changes
+	t := time.Now()
+	var settings transcript.Settings
+	if c.recorder != nil {
+		settings = c.sessionSettings()
+	}
+	err := metaquery.Handle(ctx, &metaquery.HandlerInput{
 		Query:                 query,
 		Client:                client,
 		Schema:                c.schemaMetadata,
@@ -539,7 +690,45 @@ func (c *InteractiveClient) executeMetaquery(ctx context.Context, query string)
 		Prompt:                c.interactivePrompt,
 		ClosePrompt:           func() { c.afterClose = AfterPromptCloseExit },
 		GetConnectionStateMap: c.getConnectionState,
+		History:               c.interactiveQueryHistory,
+		IncompleteQuery:       c.incompleteQuery,
+		SetPromptText:         func(text string) { c.promptText = text },
+		ExecuteMetaquery:      c.executeMetaquery,
+		ExportManager:         c.initData.ExportManager,
+		CacheStats:            c.cacheStats,
+		Theme:                 c.theme.Name,
+		SetTheme: func(name string) error {
+			theme, err := display.LoadTheme(name)
+			if err != nil {
+				return err
+			}
+			c.setTheme(theme)
+			return nil
+		},
+		LastResult: func() (export.ExportSourceData, error) {
+			if c.lastResult == nil {
+				return nil, nil
+			}
+			return c.lastResult.snapshot()
+		},
+		ExecuteQuery: func(ctx context.Context, resolvedQuery *modconfig.ResolvedQuery, historyEntry *queryhistory.HistoryEntry) {
+			statushooks.Show(ctx)
+			defer statushooks.Done(ctx)
+			statushooks.SetStatus(ctx, "Executing query…")
+			c.executeQuery(ctx, ctx, resolvedQuery, historyEntry)
+		},
+		Record:        c.record,
+		RecordingPath: c.recordingPath(),
 	})
+	c.recordMetaquery(query, t, settings, err)
+	return err
+}
+
+// setTheme sets the theme used to highlight sql in the prompt, and the colours of table output
+func (c *InteractiveClient) setTheme(theme *display.Theme) {
+	c.theme = theme
+	c.highlighter = getHighlighter(theme)
+	display.SetTableColors(theme.TableColors)
 }
 
 // helper function to acquire db connection and retrieve connection state
@@ -594,6 +783,9 @@ func (c *InteractiveClient) queryCompleter(d prompt.Document) []prompt.Suggest {
 		return nil
 	}
 
+	// the word being completed
+	word := d.GetWordBeforeCursorUntilSeparator(completionWordSeparator)
+
 	var s []prompt.Suggest
 
 	switch {
@@ -608,12 +800,14 @@ func (c *InteractiveClient) queryCompleter(d prompt.Document) []prompt.Suggest {
 		s = append(s, suggestions...)
 	default:
 		if queryInfo := getQueryInfo(text); queryInfo.EditingTable {
-			tableSuggestions := c.getTableAndConnectionSuggestions(lastWord(text))
+			tableSuggestions := c.getTableAndConnectionSuggestions(word)
 			s = append(s, tableSuggestions...)
+		} else if columnSuggestions := c.getColumnSuggestions(d, word); len(columnSuggestions) > 0 {
+			s = append(s, columnSuggestions...)
 		}
 	}
 
-	return prompt.FilterHasPrefix(s, d.GetWordBeforeCursor(), true)
+	return prompt.FilterHasPrefix(s, word, true)
 }
 
 func (c *InteractiveClient) getFirstWordSuggestions(word string) []prompt.Suggest {
@@ -659,6 +853,19 @@ func (c *InteractiveClient) getTableAndConnectionSuggestions(word string) []prom
 	return t
 }
 
+// getColumnSuggestions returns suggestions for the columns of the tables referenced by the query,
+// if the word being completed is in a clause which references columns
+func (c *InteractiveClient) getColumnSuggestions(d prompt.Document, word string) []prompt.Suggest {
+	textBeforeCursor := d.TextBeforeCursor()
+	// include the lines of a multi-line query which have already been entered
+	previousLines := strings.Join(c.interactiveBuffer, "\n")
+	if !isEditingColumn(previousLines + "\n" + textBeforeCursor[:len(textBeforeCursor)-len(word)]) {
+		return nil
+	}
+	refs := getTableReferences(previousLines + "\n" + d.Text)
+	return columnSuggestions(word, refs, c.schemaMetadata, c.client().GetRequiredSessionSearchPath(), c.suggestions.keyColumns)
+}
+
 func (c *InteractiveClient) startCancelHandler() chan bool {
 	sigIntChannel := make(chan os.Signal, 1)
 	quitChannel := make(chan bool, 1)
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/c-bata/go-prompt"
+	"github.com/jackc/pgx/v5"
 	"github.com/turbot/go-kit/helpers"
 	"github.com/turbot/pipe-fittings/v2/utils"
 	"github.com/turbot/steampipe/v2/pkg/constants"
@@ -33,6 +34,7 @@ func (c *InteractiveClient) initialiseSuggestions(ctx context.Context) error {
 	// reset suggestions
 	c.suggestions = newAutocompleteSuggestions()
 	c.initialiseSchemaAndTableSuggestions(connectionStateMap)
+	c.initialiseKeyColumnSuggestions(ctx, conn.Conn(), connectionStateMap)
 	c.initialiseQuerySuggestions()
 	c.suggestions.sort()
 	return nil
@@ -109,6 +111,36 @@ func (c *InteractiveClient) initialiseSchemaAndTableSuggestions(connectionStateM
 	}
 }
 
+// initialiseKeyColumnSuggestions loads the list key columns of all plugin tables, so they can be shown in column suggestions
+func (c *InteractiveClient) initialiseKeyColumnSuggestions(ctx context.Context, conn *pgx.Conn, connectionStateMap steampipeconfig.ConnectionStateMap) {
+	keyColumns := newKeyColumnLookup()
+	for connectionName, connectionState := range connectionStateMap {
+		keyColumns.pluginByConnection[connectionName] = connectionState.Plugin
+	}
+
+	query := fmt.Sprintf(`SELECT plugin, table_name, name, coalesce(list_config->>'require', '') FROM %s.%s WHERE list_config IS NOT NULL`,
+		constants.InternalSchema, constants.PluginColumnTable)
+	rows, err := conn.Query(ctx, query)
+	if err != nil {
+		log.Printf("[WARN] could not load key columns: %v", err)
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var plugin, table, column, require string
+		if err := rows.Scan(&plugin, &table, &column, &require); err != nil {
+			log.Printf("[WARN] could not load key columns: %v", err)
+			return
+		}
+		keyColumns.add(plugin, table, column, require)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("[WARN] could not load key columns: %v", err)
+		return
+	}
+	c.suggestions.keyColumns = keyColumns
+}
+
 func (c *InteractiveClient) initialiseQuerySuggestions() {
 	//	 TODO add sql files???
 }
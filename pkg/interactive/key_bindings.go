@@ -0,0 +1,359 @@
+package interactive
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/c-bata/go-prompt"
+	"github.com/turbot/steampipe/v2/pkg/constants"
+)
+
+// the actions which can be bound to keys with the key_bindings option
+const (
+	keyActionExecute       = "execute"
+	keyActionNewline       = "newline"
+	keyActionClear         = "clear"
+	keyActionHistorySearch = "history_search"
+)
+
+// the default key of each action - newline is not bound by default
+var defaultActionKeys = map[string]string{
+	keyActionExecute:       "enter",
+	keyActionClear:         "ctrl+c",
+	keyActionHistorySearch: "ctrl+r",
+}
+
+// the input which remapped keys and vi commands are translated to, for operations which go-prompt does not have a key for.
+// These are not valid terminal input, so go-prompt passes them to the ASCII code bindings
+var (
+	newlineCode         = actionCode("newline")
+	clearCode           = actionCode("clear")
+	historySearchCode   = actionCode("history-search")
+	wordLeftCode        = actionCode("word-left")
+	wordRightCode       = actionCode("word-right")
+	deleteCharCode      = actionCode("delete-char")
+	deleteWordCode      = actionCode("delete-word")
+	deleteToLineEndCode = actionCode("delete-to-line-end")
+	deleteLineCode      = actionCode("delete-line")
+)
+
+// the input of the keys used by the vi normal mode commands
+var (
+	enterKey  = []byte{0x0d}
+	escapeKey = keyCode(prompt.Escape)
+	leftKey   = keyCode(prompt.Left)
+	rightKey  = keyCode(prompt.Right)
+	upKey     = keyCode(prompt.Up)
+	downKey   = keyCode(prompt.Down)
+	homeKey   = keyCode(prompt.Home)
+	endKey    = keyCode(prompt.End)
+)
+
+// the input returned for keys which are ignored - go-prompt discards a single zero byte
+var ignoredInput = []byte{0}
+
+func actionCode(name string) []byte {
+	return []byte(fmt.Sprintf("\x1b[steampipe:%s]", name))
+}
+
+// keyCode returns the input of the given go-prompt key
+func keyCode(key prompt.Key) []byte {
+	for _, s := range prompt.ASCIISequences {
+		if s.Key == key {
+			return s.ASCIICode
+		}
+	}
+	return nil
+}
+
+// keyBindings is the key binding mode of the prompt, and the keys which are remapped to other actions
+type keyBindings struct {
+	mode string
+	// map of the input of a remapped key to the input it is translated to
+	remap map[string][]byte
+}
+
+// newKeyBindings returns the key bindings for the given mode, with the given map of action to key.
+// Actions which are not in the map are bound to their default key
+func newKeyBindings(mode string, actionKeys map[string]string) (*keyBindings, error) {
+	if mode == "" {
+		mode = constants.KeyBindModeEmacs
+	}
+	if mode != constants.KeyBindModeEmacs && mode != constants.KeyBindModeVi {
+		return nil, fmt.Errorf("invalid key bind mode '%s' - valid modes are %s and %s", mode, constants.KeyBindModeEmacs, constants.KeyBindModeVi)
+	}
+
+	keys := make(map[string][]byte)
+	specs := make(map[string]string)
+	for action, spec := range defaultActionKeys {
+		specs[action] = spec
+	}
+	for action, spec := range actionKeys {
+		if _, ok := specs[action]; !ok && action != keyActionNewline {
+			return nil, fmt.Errorf("invalid key binding action '%s' - valid actions are %s", action, strings.Join(keyActions(), ", "))
+		}
+		specs[action] = spec
+	}
+	boundBy := make(map[string]string)
+	// sort the actions so any error is deterministic
+	for _, action := range keyActions() {
+		spec, ok := specs[action]
+		if !ok {
+			continue
+		}
+		key, err := parseKey(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key binding for %s: %s", action, err.Error())
+		}
+		if other, ok := boundBy[string(key)]; ok {
+			return nil, fmt.Errorf("key '%s' is bound to both %s and %s", spec, other, action)
+		}
+		boundBy[string(key)] = action
+		keys[action] = key
+	}
+
+	remap := make(map[string][]byte)
+	// enter executes unless the execute key is remapped, so only map the execute key if it is not enter
+	if execute := keys[keyActionExecute]; !bytes.Equal(execute, enterKey) {
+		remap[string(execute)] = enterKey
+	}
+	if newline, ok := keys[keyActionNewline]; ok {
+		remap[string(newline)] = newlineCode
+	}
+	// ctrl+c clears the prompt natively
+	if clear := keys[keyActionClear]; !bytes.Equal(clear, keyCode(prompt.ControlC)) {
+		remap[string(clear)] = clearCode
+	}
+	remap[string(keys[keyActionHistorySearch])] = historySearchCode
+
+	return &keyBindings{mode: mode, remap: remap}, nil
+}
+
+// keyActions returns the names of the actions which can be bound to keys, sorted by name
+func keyActions() []string {
+	res := []string{keyActionNewline}
+	for action := range defaultActionKeys {
+		res = append(res, action)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// parseKey returns the terminal input of a key, e.g. 'ctrl+r', 'alt+enter' or 'tab'
+func parseKey(spec string) ([]byte, error) {
+	key := strings.ToLower(strings.TrimSpace(spec))
+	switch key {
+	case "enter", "return":
+		return enterKey, nil
+	case "tab":
+		return []byte{0x09}, nil
+	case "escape", "esc":
+		return escapeKey, nil
+	case "alt+enter", "alt+return":
+		return []byte{escapeKey[0], enterKey[0]}, nil
+	}
+	if letter, ok := strings.CutPrefix(key, "ctrl+"); ok && len(letter) == 1 && letter[0] >= 'a' && letter[0] <= 'z' {
+		return []byte{letter[0] - 'a' + 1}, nil
+	}
+	// use the untrimmed spec, as alt+<character> is case sensitive
+	if char, ok := strings.CutPrefix(strings.TrimSpace(spec), "alt+"); ok && len(char) == 1 && char[0] > 0x20 && char[0] < 0x7f {
+		return []byte{escapeKey[0], char[0]}, nil
+	}
+	return nil, fmt.Errorf("unknown key '%s' - keys must be enter, tab, escape, alt+enter, ctrl+<letter> or alt+<character>", spec)
+}
+
+// promptMode returns the go-prompt key bind mode - in vi mode, the emacs bindings are not used
+func (k *keyBindings) promptMode() prompt.KeyBindMode {
+	if k.mode == constants.KeyBindModeVi {
+		return prompt.CommonKeyBind
+	}
+	return prompt.EmacsKeyBind
+}
+
+// newParser returns a prompt input parser which applies the key bindings to the input of the given parser.
+// A new parser is created for each prompt, so vi mode starts in insert mode
+func (k *keyBindings) newParser(parser prompt.ConsoleParser) prompt.ConsoleParser {
+	res := &keyBindingParser{ConsoleParser: parser, bindings: k}
+	if k.mode == constants.KeyBindModeVi {
+		res.vi = &viMode{}
+	}
+	return res
+}
+
+// keyBindingParser translates remapped keys, and the commands of vi normal mode, into the input go-prompt acts on
+type keyBindingParser struct {
+	prompt.ConsoleParser
+	bindings *keyBindings
+	// nil unless the mode is vi
+	vi *viMode
+}
+
+func (p *keyBindingParser) Read() ([]byte, error) {
+	b, err := p.ConsoleParser.Read()
+	if err != nil {
+		return b, err
+	}
+	return p.translate(b), nil
+}
+
+func (p *keyBindingParser) translate(b []byte) []byte {
+	if remapped, ok := p.bindings.remap[string(b)]; ok {
+		b = remapped
+	}
+	if p.vi != nil {
+		b = p.vi.translate(b)
+	}
+	return b
+}
+
+// viMode translates the input of vi normal mode into editing commands
+type viMode struct {
+	normal bool
+	// the operator (d or c) waiting for a motion
+	pending byte
+}
+
+func (v *viMode) translate(b []byte) []byte {
+	if !v.normal {
+		// escape switches to normal mode - pass it on, so it also closes the suggestions
+		if bytes.Equal(b, escapeKey) {
+			v.normal = true
+		}
+		return b
+	}
+
+	// control keys, escape sequences and the translated input of remapped keys are not vi commands
+	if len(b) != 1 || b[0] < 0x20 || b[0] == 0x7f {
+		v.pending = 0
+		return b
+	}
+
+	if op := v.pending; op != 0 {
+		v.pending = 0
+		return v.operator(op, b[0])
+	}
+
+	switch b[0] {
+	case 'h':
+		return leftKey
+	case 'l', ' ':
+		return rightKey
+	case 'k':
+		return upKey
+	case 'j':
+		return downKey
+	case '0', '^':
+		return homeKey
+	case '$':
+		return endKey
+	case 'b':
+		return wordLeftCode
+	case 'w':
+		return wordRightCode
+	case 'x':
+		return deleteCharCode
+	case 'D':
+		return deleteToLineEndCode
+	case 'i':
+		v.normal = false
+		return ignoredInput
+	case 'a':
+		v.normal = false
+		return rightKey
+	case 'A':
+		v.normal = false
+		return endKey
+	case 'I':
+		v.normal = false
+		return homeKey
+	case 'C':
+		v.normal = false
+		return deleteToLineEndCode
+	case 'S':
+		v.normal = false
+		return deleteLineCode
+	case 'd', 'c':
+		v.pending = b[0]
+		return ignoredInput
+	}
+	// anything else is ignored, rather than inserted
+	return ignoredInput
+}
+
+// operator returns the input for a delete (d) or change (c) operator followed by the given motion
+func (v *viMode) operator(op, motion byte) []byte {
+	var res []byte
+	switch {
+	case motion == op:
+		res = deleteLineCode
+	case motion == 'w':
+		res = deleteWordCode
+	case motion == '$':
+		res = deleteToLineEndCode
+	default:
+		return ignoredInput
+	}
+	if op == 'c' {
+		v.normal = false
+	}
+	return res
+}
+
+// deleteRight deletes up to count characters after the cursor, on the current line
+func deleteRight(b *prompt.Buffer, count int) {
+	before := len([]rune(b.Document().TextBeforeCursor()))
+	b.CursorRight(count)
+	b.DeleteBeforeCursor(len([]rune(b.Document().TextBeforeCursor())) - before)
+}
+
+// deleteWord deletes the word after the cursor, and the spaces following it, as vi does
+func deleteWord(b *prompt.Buffer) {
+	after := []rune(b.Document().CurrentLineAfterCursor())
+	count := 0
+	for count < len(after) && !unicode.IsSpace(after[count]) {
+		count++
+	}
+	for count < len(after) && unicode.IsSpace(after[count]) {
+		count++
+	}
+	deleteRight(b, count)
+}
+
+// deleteLine deletes the text of the current line
+func deleteLine(b *prompt.Buffer) {
+	prompt.GoLineBeginning(b)
+	deleteRight(b, len([]rune(b.Document().CurrentLineAfterCursor())))
+}
+
+// clearBuffer deletes all the text of the buffer
+func clearBuffer(b *prompt.Buffer) {
+	for !b.Document().OnLastLine() {
+		b.CursorDown(1)
+	}
+	prompt.GoLineEnd(b)
+	b.DeleteBeforeCursor(len([]rune(b.Text())))
+}
+
+// keyActionBinds returns the bindings of the input which remapped keys and vi commands are translated to
+func (c *InteractiveClient) keyActionBinds() []prompt.ASCIICodeBind {
+	return []prompt.ASCIICodeBind{
+		{ASCIICode: newlineCode, Fn: func(b *prompt.Buffer) { b.NewLine(false) }},
+		{ASCIICode: clearCode, Fn: func(b *prompt.Buffer) {
+			c.historySearch.stop()
+			c.breakMultilinePrompt(b)
+			clearBuffer(b)
+		}},
+		{ASCIICode: historySearchCode, Fn: c.historySearch.searchOlder},
+		{ASCIICode: wordLeftCode, Fn: prompt.GoLeftWord},
+		{ASCIICode: wordRightCode, Fn: prompt.GoRightWord},
+		{ASCIICode: deleteCharCode, Fn: func(b *prompt.Buffer) { deleteRight(b, 1) }},
+		{ASCIICode: deleteWordCode, Fn: deleteWord},
+		{ASCIICode: deleteToLineEndCode, Fn: func(b *prompt.Buffer) {
+			deleteRight(b, len([]rune(b.Document().CurrentLineAfterCursor())))
+		}},
+		{ASCIICode: deleteLineCode, Fn: deleteLine},
+	}
+}
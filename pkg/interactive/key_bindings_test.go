@@ -0,0 +1,146 @@
+package interactive
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/c-bata/go-prompt"
+	"github.com/turbot/steampipe/v2/pkg/constants"
+)
+
+func TestParseKey(t *testing.T) {
+	cases := map[string][]byte{
+		"enter":     {0x0d},
+		"Ctrl+R":    {0x12},
+		"ctrl+j":    {0x0a},
+		"alt+enter": {0x1b, 0x0d},
+		"alt+x":     {0x1b, 'x'},
+		"alt+X":     {0x1b, 'X'},
+		"tab":       {0x09},
+		" escape ":  {0x1b},
+	}
+	for spec, expected := range cases {
+		key, err := parseKey(spec)
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", spec, err)
+			continue
+		}
+		if !bytes.Equal(key, expected) {
+			t.Errorf("%s: expected %v, got %v", spec, expected, key)
+		}
+	}
+
+	for _, spec := range []string{"", "ctrl+1", "ctrl+rr", "alt+", "shift+a", "f1"} {
+		if _, err := parseKey(spec); err == nil {
+			t.Errorf("%s: expected an error", spec)
+		}
+	}
+}
+
+func TestNewKeyBindings_Remap(t *testing.T) {
+	bindings, err := newKeyBindings("", map[string]string{
+		keyActionExecute: "alt+enter",
+		keyActionNewline: "enter",
+		keyActionClear:   "ctrl+x",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser := bindings.newParser(nil).(*keyBindingParser)
+	cases := []struct {
+		input, expected []byte
+	}{
+		{[]byte{0x1b, 0x0d}, enterKey},
+		{enterKey, newlineCode},
+		{[]byte{0x18}, clearCode},
+		{[]byte{0x12}, historySearchCode},
+		// keys which are not remapped are unchanged
+		{[]byte("a"), []byte("a")},
+		{keyCode(prompt.ControlC), keyCode(prompt.ControlC)},
+	}
+	for _, c := range cases {
+		if res := parser.translate(c.input); !bytes.Equal(res, c.expected) {
+			t.Errorf("%q: expected %q, got %q", c.input, c.expected, res)
+		}
+	}
+	if bindings.promptMode() != prompt.EmacsKeyBind {
+		t.Errorf("expected emacs mode by default")
+	}
+}
+
+func TestNewKeyBindings_Invalid(t *testing.T) {
+	cases := map[string]struct {
+		mode string
+		keys map[string]string
+	}{
+		"mode":      {mode: "nano"},
+		"action":    {keys: map[string]string{"run": "enter"}},
+		"key":       {keys: map[string]string{keyActionNewline: "shift+enter"}},
+		"duplicate": {keys: map[string]string{keyActionNewline: "ctrl+r"}},
+	}
+	for name, c := range cases {
+		if _, err := newKeyBindings(c.mode, c.keys); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestViMode(t *testing.T) {
+	bindings, err := newKeyBindings(constants.KeyBindModeVi, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bindings.promptMode() != prompt.CommonKeyBind {
+		t.Errorf("expected the emacs bindings to be disabled in vi mode")
+	}
+	parser := bindings.newParser(nil).(*keyBindingParser)
+	steps := []struct {
+		input, expected []byte
+	}{
+		// insert mode
+		{[]byte("h"), []byte("h")},
+		{escapeKey, escapeKey},
+		// normal mode
+		{[]byte("h"), leftKey},
+		{[]byte("w"), wordRightCode},
+		{[]byte("q"), ignoredInput},
+		{[]byte("d"), ignoredInput},
+		{[]byte("d"), deleteLineCode},
+		{[]byte("k"), upKey},
+		{[]byte{0x12}, historySearchCode},
+		{[]byte("c"), ignoredInput},
+		{[]byte("w"), deleteWordCode},
+		// the change operator returns to insert mode
+		{[]byte("l"), []byte("l")},
+		{escapeKey, escapeKey},
+		{[]byte("A"), endKey},
+		{[]byte("x"), []byte("x")},
+	}
+	for i, step := range steps {
+		if res := parser.translate(step.input); !bytes.Equal(res, step.expected) {
+			t.Errorf("step %d %q: expected %q, got %q", i, step.input, step.expected, res)
+		}
+	}
+}
+
+func TestBufferEdits(t *testing.T) {
+	b := prompt.NewBuffer()
+	b.InsertText("select name\nfrom foo where", false, true)
+
+	// the cursor is at the end of the second line
+	prompt.GoLineBeginning(b)
+	deleteWord(b)
+	if b.Text() != "select name\nfoo where" {
+		t.Errorf("delete word: got %q", b.Text())
+	}
+	deleteLine(b)
+	if b.Text() != "select name\n" {
+		t.Errorf("delete line: got %q", b.Text())
+	}
+	b.CursorUp(1)
+	prompt.GoLineBeginning(b)
+	clearBuffer(b)
+	if b.Text() != "" {
+		t.Errorf("clear: got %q", b.Text())
+	}
+}
@@ -0,0 +1,31 @@
+package interactive
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/turbot/pipe-fittings/v2/modconfig"
+	pqueryresult "github.com/turbot/pipe-fittings/v2/queryresult"
+	"github.com/turbot/pipe-fittings/v2/steampipeconfig"
+	"github.com/turbot/steampipe/v2/pkg/snapshot"
+)
+
+// lastResult is the result of the last query executed in the interactive session, kept so it can be exported
+type lastResult struct {
+	query *modconfig.ResolvedQuery
+	cols  []*pqueryresult.ColumnDef
+	rows  [][]any
+	// whether the result had too many rows to keep, so it cannot be exported
+	tooLarge   bool
+	searchPath []string
+	startTime  time.Time
+	endTime    time.Time
+}
+
+// snapshot converts the result into a snapshot, which can be passed to the export manager
+func (r *lastResult) snapshot() (*steampipeconfig.SteampipeSnapshot, error) {
+	if r.tooLarge {
+		return nil, fmt.Errorf("the last query result was not kept as it returned more than %d rows - use 'steampipe query --export' to export large results", maxRetainedRows)
+	}
+	return snapshot.RowsToSnapshot(r.query.RawSQL, r.cols, r.rows, r.searchPath, r.startTime, r.endTime)
+}
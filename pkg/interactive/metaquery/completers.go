@@ -4,6 +4,8 @@ import (
 	"strings"
 
 	"github.com/c-bata/go-prompt"
+	"github.com/turbot/steampipe/v2/pkg/display"
+	"github.com/turbot/steampipe/v2/pkg/query/savedquery"
 )
 
 // CompleterInput is a struct defining input data for the metaquery completer
@@ -43,3 +45,21 @@ func completerFromArgsOf(cmd string) completer {
 func inspectCompleter(input *CompleterInput) []prompt.Suggest {
 	return input.TableSuggestions
 }
+
+func themeCompleter(input *CompleterInput) []prompt.Suggest {
+	names := display.ThemeNames()
+	suggestions := make([]prompt.Suggest, len(names))
+	for idx, name := range names {
+		suggestions[idx] = prompt.Suggest{Text: name, Output: name}
+	}
+	return suggestions
+}
+
+func savedQueryCompleter(input *CompleterInput) []prompt.Suggest {
+	names := savedquery.Names()
+	suggestions := make([]prompt.Suggest, len(names))
+	for idx, name := range names {
+		suggestions[idx] = prompt.Suggest{Text: name, Output: name}
+	}
+	return suggestions
+}
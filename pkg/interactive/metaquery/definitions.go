@@ -84,6 +84,8 @@ func init() {
 				{value: pconstants.ArgOff, description: "Turn off query timer"},
 				{value: pconstants.ArgOn, description: "Display time elapsed after every query"},
 				{value: pconstants.ArgVerbose, description: "Display time elapsed and details of each scan"},
+				{value: constants.TimingJSON, description: "Display time elapsed as json"},
+				{value: constants.TimingJSONVerbose, description: "Display time elapsed and details of each scan as json"},
 			},
 			completer: completerFromArgsOf(constants.CmdTiming),
 		},
@@ -100,15 +102,96 @@ func init() {
 			},
 			completer: completerFromArgsOf(constants.CmdOutput),
 		},
+		constants.CmdEdit: {
+			title:       constants.CmdEdit,
+			handler:     editQuery,
+			validator:   composeValidator(atMostNArgs(1), validatorFromArgsOf(constants.CmdEdit)),
+			description: "Edit the current multi-line query, or the last query, in $EDITOR and load it into the prompt",
+			args: []metaQueryArg{
+				{value: "run", description: "Execute the query after editing, rather than loading it into the prompt"},
+			},
+			completer: completerFromArgsOf(constants.CmdEdit),
+		},
+		constants.CmdExport: {
+			title:       constants.CmdExport,
+			handler:     exportLastResult,
+			validator:   atLeastNArgs(1),
+			description: "Export the result of the last query, e.g. .export csv or .export results.json",
+			args: []metaQueryArg{
+				{value: "<file|format>...", description: "The file or format to export to - supported formats: csv, json, jsonl, parquet, sqlite, sps (snapshot)"},
+			},
+		},
+		constants.CmdSource: {
+			title:       constants.CmdSource,
+			handler:     sourceFile,
+			validator:   exactlyNArgs(1),
+			description: "Execute the sql statements and metaqueries in a file, in the current session",
+			args: []metaQueryArg{
+				{value: "<file>", description: "The file to execute - metaqueries must be on their own line"},
+			},
+		},
+		constants.CmdViewer: {
+			title:       "result viewer",
+			handler:     setResultViewer,
+			validator:   booleanValidator(constants.CmdViewer, constants.ArgResultViewer, validatorFromArgsOf(constants.CmdViewer)),
+			description: "Enable or disable the full-screen viewer for query results which do not fit on the screen",
+			args: []metaQueryArg{
+				{value: pconstants.ArgOn, description: "Show results which do not fit on the screen in the viewer"},
+				{value: pconstants.ArgOff, description: "Print all results to the terminal"},
+			},
+			completer: completerFromArgsOf(constants.CmdViewer),
+		},
+		constants.CmdRecord: {
+			title:       constants.CmdRecord,
+			handler:     recordSession,
+			validator:   recordValidator,
+			description: "Record the queries, metaqueries, setting changes and results of the session to a transcript file, which can be replayed with 'steampipe query --replay <file>'",
+			args: []metaQueryArg{
+				{value: recordArgStart, description: "Start recording to a file: .record start <file>"},
+				{value: recordArgStop, description: "Stop recording"},
+			},
+			completer: completerFromArgsOf(constants.CmdRecord),
+		},
+		constants.CmdTheme: {
+			title:       constants.CmdTheme,
+			handler:     setTheme,
+			validator:   atMostNArgs(1),
+			description: "Set the colour theme of sql highlighting and table output, or list the themes",
+			args: []metaQueryArg{
+				{value: "<name>", description: "A built in theme (dark, light or plain), a theme file in the themes directory or a chroma style"},
+			},
+			completer: themeCompleter,
+		},
+		constants.CmdOut: {
+			title:       constants.CmdOut,
+			handler:     redirectOutput(false),
+			validator:   atMostNArgs(1),
+			description: "Write query results to a file in the current output format, instead of the screen",
+			args: []metaQueryArg{
+				{value: "<file>", description: "The file to write results to - it is replaced if it exists"},
+				{value: pconstants.ArgOff, description: "Show results on the screen only"},
+			},
+		},
+		constants.CmdTee: {
+			title:       constants.CmdTee,
+			handler:     redirectOutput(true),
+			validator:   atMostNArgs(1),
+			description: "Write query results to a file in the current output format, as well as the screen",
+			args: []metaQueryArg{
+				{value: "<file>", description: "The file to write results to - it is replaced if it exists"},
+				{value: pconstants.ArgOff, description: "Show results on the screen only"},
+			},
+		},
 		constants.CmdCache: {
 			title:       constants.CmdCache,
 			handler:     cacheControl,
-			validator:   validatorFromArgsOf(constants.CmdCache),
-			description: "Enable, disable or clear the query cache",
+			validator:   cacheValidator,
+			description: "Enable, disable or clear the query cache, or show the cache stats of each connection",
 			args: []metaQueryArg{
 				{value: pconstants.ArgOn, description: "Turn on caching"},
 				{value: pconstants.ArgOff, description: "Turn off caching"},
-				{value: pconstants.ArgClear, description: "Clear the cache"},
+				{value: pconstants.ArgClear, description: "Clear the cache, or the cache of a connection with: .cache clear <connection>"},
+				{value: cacheArgStats, description: "Show the cached and fetched rows of each connection queried in this session"},
 			},
 			completer: completerFromArgsOf(constants.CmdCache),
 		},
@@ -128,6 +211,55 @@ func init() {
 			description: "View connections, tables & column information",
 			completer:   inspectCompleter,
 		},
+		constants.CmdExplain: {
+			title:       constants.CmdExplain,
+			handler:     explain,
+			validator:   atLeastNArgs(1),
+			description: "Show the query plan, and the key column quals pushed down to each plugin",
+			args: []metaQueryArg{
+				{value: "<sql>", description: "The query to explain"},
+			},
+		},
+		constants.CmdHistory: {
+			title:       constants.CmdHistory,
+			handler:     showHistory,
+			validator:   historyFilterValidator,
+			description: "Show the query history, optionally filtered (e.g. .history date=tuesday rows=12)",
+			args: []metaQueryArg{
+				{value: "[filter...]", description: "Text the query contains, or a condition on status, connection, rows, duration, date, since, until or limit"},
+			},
+		},
+		constants.CmdSave: {
+			title:       constants.CmdSave,
+			handler:     saveQuery,
+			validator:   atLeastNArgs(1),
+			description: "Save the last query, or the given query, by name",
+			args: []metaQueryArg{
+				{value: "<name>", description: "The name to save the query as"},
+				{value: "[sql]", description: "The query to save - defaults to the last query"},
+			},
+		},
+		constants.CmdRun: {
+			title:       constants.CmdRun,
+			handler:     runSavedQuery,
+			validator:   atLeastNArgs(1),
+			description: "Run a saved query, optionally passing query args (e.g. .run my_query region=us-east-1)",
+			args: []metaQueryArg{
+				{value: "<name>", description: "The name of the saved query"},
+				{value: "[args...]", description: "Positional query args, or named args in the form name=value"},
+			},
+			completer: savedQueryCompleter,
+		},
+		constants.CmdQueries: {
+			title:       constants.CmdQueries,
+			handler:     listSavedQueries,
+			validator:   atMostNArgs(1),
+			description: "List saved queries, or show the sql of a saved query",
+			args: []metaQueryArg{
+				{value: "[name]", description: "The name of the saved query to show"},
+			},
+			completer: savedQueryCompleter,
+		},
 		constants.CmdConnections: {
 			title:       constants.CmdConnections,
 			handler:     listConnections,
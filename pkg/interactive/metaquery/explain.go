@@ -0,0 +1,284 @@
+package metaquery
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/sperr"
+)
+
+const foreignScanNodeType = "Foreign Scan"
+
+// explainPlan is a node of the plan returned by EXPLAIN (VERBOSE, FORMAT JSON)
+type explainPlan struct {
+	NodeType     string         `json:"Node Type"`
+	Schema       string         `json:"Schema"`
+	RelationName string         `json:"Relation Name"`
+	Alias        string         `json:"Alias"`
+	Filter       string         `json:"Filter"`
+	Plans        []*explainPlan `json:"Plans"`
+}
+
+// parseExplainPlan parses the output of EXPLAIN (FORMAT JSON), which is an array containing a single plan
+func parseExplainPlan(data []byte) (*explainPlan, error) {
+	var plans []struct {
+		Plan *explainPlan `json:"Plan"`
+	}
+	if err := json.Unmarshal(data, &plans); err != nil {
+		return nil, sperr.WrapWithMessage(err, "failed to parse query plan")
+	}
+	if len(plans) == 0 || plans[0].Plan == nil {
+		return nil, sperr.New("query plan is empty")
+	}
+	return plans[0].Plan, nil
+}
+
+// foreignScans returns all the foreign scan nodes of the plan, in plan order
+func (p *explainPlan) foreignScans() []*explainPlan {
+	var res []*explainPlan
+	if p.NodeType == foreignScanNodeType {
+		res = append(res, p)
+	}
+	for _, child := range p.Plans {
+		res = append(res, child.foreignScans()...)
+	}
+	return res
+}
+
+// keyColumnConfig is the key column config of a column, as stored in the list_config and get_config
+// columns of the steampipe_plugin_column table
+type keyColumnConfig struct {
+	Operators []string `json:"operators,omitempty"`
+	Require   string   `json:"require,omitempty"`
+}
+
+func (k *keyColumnConfig) supportsOperator(operator string) bool {
+	// if no operators are specified, the plugin sdk defaults to '='
+	if len(k.Operators) == 0 {
+		return operator == "="
+	}
+	return slices.Contains(k.Operators, operator)
+}
+
+// tableKeyColumns is the list and get key column config of a table, keyed by column name
+type tableKeyColumns struct {
+	list map[string]*keyColumnConfig
+	get  map[string]*keyColumnConfig
+}
+
+func newTableKeyColumns() *tableKeyColumns {
+	return &tableKeyColumns{
+		list: make(map[string]*keyColumnConfig),
+		get:  make(map[string]*keyColumnConfig),
+	}
+}
+
+// explainQual is a single qual of a foreign scan filter
+type explainQual struct {
+	// the qualifier of the column, if any (with EXPLAIN VERBOSE, this is the table alias)
+	qualifier string
+	// the column name - empty if the qual is not a simple column comparison
+	column string
+	// the operator, normalised to the form used by the plugin sdk
+	operator string
+	// the qual as shown in the plan
+	expression string
+}
+
+func (q explainQual) String() string {
+	return q.expression
+}
+
+// regex to extract the column and operator from a qual such as
+// (b.region)::text = 'us-east-1'::text or b.name = ANY ('{a,b}'::text[])
+var qualRegex = regexp.MustCompile(`^\(*(?:"?(\w+)"?\.)?"?(\w+)"?\)?(?:::[\w ]+(?:\[\])?)?\s+(IS NOT NULL|IS NULL|<>|!=|<=|>=|!~~\*|!~~|~~\*|~~|=|<|>|@>|<@|\?\||\?&|\?)(?:\s|$)`)
+
+// map of the operators shown in the plan which are spelled differently by the plugin sdk
+var qualOperatorLookup = map[string]string{
+	"<>":          "!=",
+	"IS NULL":     "is null",
+	"IS NOT NULL": "is not null",
+}
+
+// parseFilterQuals splits a foreign scan filter into its top level AND-ed quals
+func parseFilterQuals(filter string) []explainQual {
+	var res []explainQual
+	for _, expression := range splitConjuncts(filter) {
+		qual := explainQual{expression: expression}
+		if match := qualRegex.FindStringSubmatch(expression); match != nil {
+			qual.qualifier = match[1]
+			qual.column = match[2]
+			qual.operator = match[3]
+			if operator, ok := qualOperatorLookup[qual.operator]; ok {
+				qual.operator = operator
+			}
+		}
+		res = append(res, qual)
+	}
+	return res
+}
+
+// splitConjuncts splits an expression by the AND operators which are not nested in parentheses or quotes,
+// removing any parentheses which enclose the whole of each part
+func splitConjuncts(expression string) []string {
+	expression = trimEnclosingParentheses(strings.TrimSpace(expression))
+	if expression == "" {
+		return nil
+	}
+
+	const and = " AND "
+	var parts []string
+	depth, start := 0, 0
+	inQuote := false
+	for i := 0; i < len(expression); i++ {
+		switch c := expression[i]; {
+		case c == '\'':
+			inQuote = !inQuote
+		case inQuote:
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case depth == 0 && strings.HasPrefix(expression[i:], and):
+			parts = append(parts, expression[start:i])
+			start = i + len(and)
+			i += len(and) - 1
+		}
+	}
+	if len(parts) == 0 {
+		return []string{expression}
+	}
+
+	var res []string
+	for _, part := range append(parts, expression[start:]) {
+		res = append(res, splitConjuncts(part)...)
+	}
+	return res
+}
+
+// trimEnclosingParentheses removes any parentheses which enclose the whole of the expression
+func trimEnclosingParentheses(expression string) string {
+	for strings.HasPrefix(expression, "(") && matchingParenthesis(expression) == len(expression)-1 {
+		expression = strings.TrimSpace(expression[1 : len(expression)-1])
+	}
+	return expression
+}
+
+// matchingParenthesis returns the index of the parenthesis which closes the one at the start of the expression
+func matchingParenthesis(expression string) int {
+	depth := 0
+	inQuote := false
+	for i := 0; i < len(expression); i++ {
+		switch c := expression[i]; {
+		case c == '\'':
+			inQuote = !inQuote
+		case inQuote:
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// foreignScanAnnotation describes how the quals of a foreign scan are handled by the FDW
+type foreignScanAnnotation struct {
+	connection string
+	table      string
+	// is the scan a get call (rather than a list call)
+	get bool
+	// the quals which are passed to the plugin
+	pushedDown []explainQual
+	// the quals which are evaluated by postgres, after the plugin has returned the rows
+	local []explainQual
+	// descriptions of the required key column quals which are missing
+	missingRequired []string
+}
+
+// annotateForeignScan determines which quals of the scan are pushed down to the plugin,
+// which are filtered locally and which of the required key column quals are missing.
+// keyColumns may be nil if the key columns of the table are not known, in which case all quals are treated as local
+func annotateForeignScan(scan *explainPlan, keyColumns *tableKeyColumns) *foreignScanAnnotation {
+	res := &foreignScanAnnotation{
+		connection: scan.Schema,
+		table:      scan.RelationName,
+	}
+	if keyColumns == nil {
+		keyColumns = newTableKeyColumns()
+	}
+
+	// map of pushed down column names to the operators used
+	pushedDown := make(map[string][]string)
+	for _, qual := range parseFilterQuals(scan.Filter) {
+		if isKeyColumnQual(qual, scan, keyColumns) {
+			res.pushedDown = append(res.pushedDown, qual)
+			pushedDown[qual.column] = append(pushedDown[qual.column], qual.operator)
+		} else {
+			res.local = append(res.local, qual)
+		}
+	}
+
+	// a get call is made if there are quals for all of the required get key columns
+	res.get = len(keyColumns.get) > 0 && len(missingRequiredKeyColumns(keyColumns.get, pushedDown, true)) == 0
+	if !res.get {
+		res.missingRequired = missingRequiredKeyColumns(keyColumns.list, pushedDown, false)
+	}
+	return res
+}
+
+func isKeyColumnQual(qual explainQual, scan *explainPlan, keyColumns *tableKeyColumns) bool {
+	if qual.column == "" {
+		return false
+	}
+	// with EXPLAIN VERBOSE, columns are qualified by the table alias - ignore columns of other tables
+	if qual.qualifier != "" && qual.qualifier != scan.Alias && qual.qualifier != scan.RelationName {
+		return false
+	}
+	for _, config := range []*keyColumnConfig{keyColumns.list[qual.column], keyColumns.get[qual.column]} {
+		if config != nil && config.supportsOperator(qual.operator) {
+			return true
+		}
+	}
+	return false
+}
+
+// missingRequiredKeyColumns returns descriptions of the required key columns which do not have a qual.
+// For get key columns, all columns are required unless they are optional
+func missingRequiredKeyColumns(keyColumns map[string]*keyColumnConfig, pushedDown map[string][]string, isGet bool) []string {
+	var res []string
+	var anyOf []string
+	anyOfSatisfied := false
+	for _, column := range slices.Sorted(maps.Keys(keyColumns)) {
+		config := keyColumns[column]
+		_, hasQual := pushedDown[column]
+		switch {
+		case config.Require == "any_of":
+			anyOf = append(anyOf, column)
+			anyOfSatisfied = anyOfSatisfied || hasQual
+		case config.Require == "optional":
+		case config.Require == "required" || (isGet && config.Require == ""):
+			if !hasQual {
+				res = append(res, describeKeyColumn(column, config))
+			}
+		}
+	}
+	if len(anyOf) > 0 && !anyOfSatisfied {
+		res = append(res, fmt.Sprintf("one of %s", strings.Join(anyOf, ", ")))
+	}
+	return res
+}
+
+func describeKeyColumn(column string, config *keyColumnConfig) string {
+	if len(config.Operators) == 0 {
+		return column
+	}
+	return fmt.Sprintf("%s (%s)", column, strings.Join(config.Operators, ", "))
+}
@@ -0,0 +1,191 @@
+package metaquery
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitConjuncts(t *testing.T) {
+	cases := map[string]struct {
+		filter   string
+		expected []string
+	}{
+		"empty": {
+			filter:   "",
+			expected: nil,
+		},
+		"single qual": {
+			filter:   "((b.region)::text = 'us-east-1'::text)",
+			expected: []string{"(b.region)::text = 'us-east-1'::text"},
+		},
+		"multiple quals": {
+			filter:   "(((b.region)::text = 'us-east-1'::text) AND (b.versioning_enabled = true))",
+			expected: []string{"(b.region)::text = 'us-east-1'::text", "b.versioning_enabled = true"},
+		},
+		"nested or is not split": {
+			filter:   "((b.a = 1) AND ((b.b = 2) OR ((b.c = 3) AND (b.d = 4))))",
+			expected: []string{"b.a = 1", "(b.b = 2) OR ((b.c = 3) AND (b.d = 4))"},
+		},
+		"and inside quotes is not split": {
+			filter:   "((b.name)::text = 'this AND (that'::text)",
+			expected: []string{"(b.name)::text = 'this AND (that'::text"},
+		},
+	}
+
+	for name, test := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual := splitConjuncts(test.filter)
+			if !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("expected %q, got %q", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestParseFilterQuals(t *testing.T) {
+	cases := map[string]struct {
+		filter   string
+		expected []explainQual
+	}{
+		"cast column": {
+			filter:   "((b.region)::text = 'us-east-1'::text)",
+			expected: []explainQual{{qualifier: "b", column: "region", operator: "=", expression: "(b.region)::text = 'us-east-1'::text"}},
+		},
+		"not equal": {
+			filter:   "(b.status <> 'active'::text)",
+			expected: []explainQual{{qualifier: "b", column: "status", operator: "!=", expression: "b.status <> 'active'::text"}},
+		},
+		"in list": {
+			filter:   "((b.name)::text = ANY ('{a,b}'::text[]))",
+			expected: []explainQual{{qualifier: "b", column: "name", operator: "=", expression: "(b.name)::text = ANY ('{a,b}'::text[])"}},
+		},
+		"is null": {
+			filter:   "(b.tags IS NULL)",
+			expected: []explainQual{{qualifier: "b", column: "tags", operator: "is null", expression: "b.tags IS NULL"}},
+		},
+		"unqualified like": {
+			filter:   "(name ~~ 'prod%'::text)",
+			expected: []explainQual{{column: "name", operator: "~~", expression: "name ~~ 'prod%'::text"}},
+		},
+		"function call": {
+			filter:   "(lower(b.name) = 'a'::text)",
+			expected: []explainQual{{expression: "lower(b.name) = 'a'::text"}},
+		},
+	}
+
+	for name, test := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual := parseFilterQuals(test.filter)
+			if !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("expected %+v, got %+v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestAnnotateForeignScan(t *testing.T) {
+	keyColumns := &tableKeyColumns{
+		list: map[string]*keyColumnConfig{
+			"region": {Operators: []string{"="}, Require: "optional"},
+			"status": {Operators: []string{"=", "!="}, Require: "optional"},
+			"org":    {Require: "required"},
+		},
+		get: map[string]*keyColumnConfig{
+			"name": {Operators: []string{"="}, Require: "required"},
+		},
+	}
+
+	cases := map[string]struct {
+		filter          string
+		keyColumns      *tableKeyColumns
+		get             bool
+		pushedDown      []string
+		local           []string
+		missingRequired []string
+	}{
+		"get call": {
+			filter:     "(((b.name)::text = 'bucket'::text) AND (b.versioning_enabled = true))",
+			keyColumns: keyColumns,
+			get:        true,
+			pushedDown: []string{"(b.name)::text = 'bucket'::text"},
+			local:      []string{"b.versioning_enabled = true"},
+		},
+		"list call with required qual": {
+			filter:     "(((b.org)::text = 'turbot'::text) AND ((b.status)::text <> 'archived'::text))",
+			keyColumns: keyColumns,
+			pushedDown: []string{"(b.org)::text = 'turbot'::text", "(b.status)::text <> 'archived'::text"},
+		},
+		"unsupported operator is filtered locally": {
+			filter:          "((b.region)::text ~~ 'us-%'::text)",
+			keyColumns:      keyColumns,
+			local:           []string{"(b.region)::text ~~ 'us-%'::text"},
+			missingRequired: []string{"org"},
+		},
+		"column of another table is filtered locally": {
+			filter:          "((o.org)::text = (b.login)::text)",
+			keyColumns:      keyColumns,
+			local:           []string{"(o.org)::text = (b.login)::text"},
+			missingRequired: []string{"org"},
+		},
+		"any of": {
+			filter: "",
+			keyColumns: &tableKeyColumns{
+				list: map[string]*keyColumnConfig{
+					"id":   {Require: "any_of"},
+					"name": {Require: "any_of"},
+				},
+			},
+			missingRequired: []string{"one of id, name"},
+		},
+		"unknown key columns": {
+			filter: "((b.name)::text = 'bucket'::text)",
+			local:  []string{"(b.name)::text = 'bucket'::text"},
+		},
+	}
+
+	for name, test := range cases {
+		t.Run(name, func(t *testing.T) {
+			scan := &explainPlan{NodeType: foreignScanNodeType, Schema: "github", RelationName: "github_repo", Alias: "b", Filter: test.filter}
+			actual := annotateForeignScan(scan, test.keyColumns)
+			if actual.get != test.get {
+				t.Errorf("expected get %v, got %v", test.get, actual.get)
+			}
+			if got := qualExpressions(actual.pushedDown); !reflect.DeepEqual(got, test.pushedDown) {
+				t.Errorf("expected pushed down %q, got %q", test.pushedDown, got)
+			}
+			if got := qualExpressions(actual.local); !reflect.DeepEqual(got, test.local) {
+				t.Errorf("expected local %q, got %q", test.local, got)
+			}
+			if !reflect.DeepEqual(actual.missingRequired, test.missingRequired) {
+				t.Errorf("expected missing required %q, got %q", test.missingRequired, actual.missingRequired)
+			}
+		})
+	}
+}
+
+func TestParseExplainPlan(t *testing.T) {
+	data := []byte(`[{"Plan": {"Node Type": "Hash Join", "Plans": [
+		{"Node Type": "Foreign Scan", "Schema": "aws", "Relation Name": "aws_s3_bucket", "Alias": "b"},
+		{"Node Type": "Hash", "Plans": [{"Node Type": "Foreign Scan", "Schema": "aws", "Relation Name": "aws_account", "Alias": "a"}]}
+	]}}]`)
+	plan, err := parseExplainPlan(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tables []string
+	for _, scan := range plan.foreignScans() {
+		tables = append(tables, scan.Schema+"."+scan.RelationName)
+	}
+	expected := []string{"aws.aws_s3_bucket", "aws.aws_account"}
+	if !reflect.DeepEqual(tables, expected) {
+		t.Errorf("expected %q, got %q", expected, tables)
+	}
+}
+
+func qualExpressions(quals []explainQual) []string {
+	var res []string
+	for _, q := range quals {
+		res = append(res, q.expression)
+	}
+	return res
+}
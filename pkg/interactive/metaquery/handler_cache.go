@@ -10,15 +10,31 @@ import (
 
 	"github.com/spf13/viper"
 	pconstants "github.com/turbot/pipe-fittings/v2/constants"
+	"github.com/turbot/pipe-fittings/v2/querydisplay"
 	"github.com/turbot/steampipe-plugin-sdk/v5/sperr"
 	"github.com/turbot/steampipe/v2/pkg/db/db_common"
+	"github.com/turbot/steampipe/v2/pkg/query/queryresult"
 )
 
+// the .cache argument which shows the cache stats of each connection
+const cacheArgStats = "stats"
+
 // controls the cache in the connected FDW
 func cacheControl(ctx context.Context, input *HandlerInput) error {
-	if len(input.args()) == 0 {
+	args := input.args()
+	if len(args) == 0 {
 		return showCache(ctx, input)
 	}
+	command := strings.ToLower(args[0])
+	if command == cacheArgStats {
+		return showCacheStats(input)
+	}
+	// validate the connection before clearing its cache, as the FDW ignores unknown connections
+	if command == pconstants.ArgClear && len(args) == 2 && input.Schema != nil {
+		if _, ok := input.Schema.Schemas[args[1]]; !ok {
+			return fmt.Errorf("connection '%s' not found", args[1])
+		}
+	}
 
 	// just get the active session from the connection pool
 	// and set the cache parameters on it.
@@ -35,7 +51,6 @@ func cacheControl(ctx context.Context, input *HandlerInput) error {
 	}()
 
 	conn := sessionResult.Session.Connection.Conn()
-	command := strings.ToLower(input.args()[0])
 	switch command {
 	case pconstants.ArgOn:
 		serverSettings := input.Client.ServerSettings()
@@ -48,12 +63,46 @@ func cacheControl(ctx context.Context, input *HandlerInput) error {
 		viper.Set(pconstants.ArgClientCacheEnabled, false)
 		return db_common.SetCacheEnabled(ctx, false, conn)
 	case pconstants.ArgClear:
+		if len(args) == 2 {
+			if err := db_common.ConnectionCacheClear(ctx, args[1], conn); err != nil {
+				return err
+			}
+			fmt.Printf("Cleared the cache of connection '%s'\n", args[1])
+			return nil
+		}
 		return db_common.CacheClear(ctx, conn)
 	}
 
 	return fmt.Errorf("invalid command")
 }
 
+// showCacheStats shows the cache usage of each connection over the queries executed in this session
+func showCacheStats(input *HandlerInput) error {
+	var stats []queryresult.ConnectionCacheStats
+	if input.CacheStats != nil {
+		stats = input.CacheStats.Stats()
+	}
+	if len(stats) == 0 {
+		fmt.Println("No connections have been queried in this session.")
+		return nil
+	}
+
+	header := []string{"Connection", "Scans", "Cached Scans", "Rows Fetched", "Cached Rows", "Row Hit Ratio"}
+	var rows [][]string
+	for _, s := range stats {
+		rows = append(rows, []string{
+			s.Connection,
+			fmt.Sprintf("%d", s.CachedScans+s.FetchedScans),
+			fmt.Sprintf("%d", s.CachedScans),
+			fmt.Sprintf("%d", s.FetchedRows),
+			fmt.Sprintf("%d", s.CachedRows),
+			fmt.Sprintf("%.1f%%", s.RowHitRatio()*100),
+		})
+	}
+	querydisplay.ShowWrappedTable(header, rows, &querydisplay.ShowWrappedTableOptions{AutoMerge: false})
+	return nil
+}
+
 // sets the cache TTL
 func cacheTTL(ctx context.Context, input *HandlerInput) error {
 	if len(input.args()) == 0 {
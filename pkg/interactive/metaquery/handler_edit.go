@@ -0,0 +1,98 @@
+package metaquery
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/sperr"
+	"github.com/turbot/steampipe/v2/pkg/query"
+	"github.com/turbot/steampipe/v2/pkg/query/queryhistory"
+)
+
+// .edit
+// open the incomplete multi-line query, or the last query, in the user's editor,
+// then load the edited query into the prompt - or if 'run' is passed, execute it
+func editQuery(ctx context.Context, input *HandlerInput) error {
+	text := input.IncompleteQuery
+	if text == "" {
+		text = lastQuery(input)
+	}
+
+	edited, err := editText(text)
+	if err != nil {
+		return err
+	}
+	if edited == "" {
+		fmt.Println("The query is empty.")
+		return nil
+	}
+
+	// metaqueries are always loaded into the prompt, rather than executed
+	run := len(input.args()) == 1
+	if !run || IsMetaQuery(edited) {
+		input.SetPromptText(edited)
+		return nil
+	}
+
+	for _, q := range query.StatementQueries(edited, nil) {
+		// stop if the execution has been cancelled
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		// add each statement to the history, as if it had been entered at the prompt, so its result is recorded
+		var historyEntry *queryhistory.HistoryEntry
+		if input.History != nil {
+			historyEntry = input.History.Push(q.RawSQL)
+		}
+		input.ExecuteQuery(ctx, q, historyEntry)
+	}
+	return nil
+}
+
+// editText opens the text in the user's editor, returning the edited text
+func editText(text string) (string, error) {
+	f, err := os.CreateTemp("", "steampipe-query-*.sql")
+	if err != nil {
+		return "", sperr.WrapWithMessage(err, "failed to create file to edit")
+	}
+	defer os.Remove(f.Name())
+	if text != "" {
+		text += "\n"
+	}
+	_, err = f.WriteString(text)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", sperr.WrapWithMessage(err, "failed to write file to edit")
+	}
+
+	editor := editorCommand()
+	cmd := exec.Command(editor[0], append(editor[1:], f.Name())...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", sperr.WrapWithMessage(err, "failed to run editor '%s'", strings.Join(editor, " "))
+	}
+
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		return "", sperr.WrapWithMessage(err, "failed to read edited file")
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// editorCommand returns the command used to edit queries - $EDITOR (which may include arguments),
+// falling back to $VISUAL and then vi
+func editorCommand() []string {
+	for _, env := range []string{"EDITOR", "VISUAL"} {
+		if fields := strings.Fields(os.Getenv(env)); len(fields) > 0 {
+			return fields
+		}
+	}
+	return []string{"vi"}
+}
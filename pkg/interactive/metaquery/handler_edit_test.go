@@ -0,0 +1,71 @@
+package metaquery
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/turbot/pipe-fittings/v2/modconfig"
+	"github.com/turbot/steampipe/v2/pkg/query/queryhistory"
+)
+
+func TestEditorCommand(t *testing.T) {
+	t.Setenv("EDITOR", "code --wait")
+	t.Setenv("VISUAL", "nano")
+	assert.Equal(t, []string{"code", "--wait"}, editorCommand())
+
+	t.Setenv("EDITOR", "")
+	assert.Equal(t, []string{"nano"}, editorCommand())
+
+	t.Setenv("VISUAL", " ")
+	assert.Equal(t, []string{"vi"}, editorCommand())
+}
+
+func TestEditText(t *testing.T) {
+	// an 'editor' which appends a where clause to the file
+	editor := filepath.Join(t.TempDir(), "editor.sh")
+	require.NoError(t, os.WriteFile(editor, []byte("#!/bin/sh\necho \"where id = 1\" >> \"$1\"\n"), 0700))
+	t.Setenv("EDITOR", editor)
+
+	edited, err := editText("select *\nfrom my_table")
+	require.NoError(t, err)
+	assert.Equal(t, "select *\nfrom my_table\nwhere id = 1", edited)
+}
+
+func TestEditText_EditorFails(t *testing.T) {
+	t.Setenv("EDITOR", "false")
+	_, err := editText("select 1")
+	assert.Error(t, err)
+}
+
+func TestEditQuery_RunRecordsHistory(t *testing.T) {
+	// an 'editor' which replaces the file with two statements
+	editor := filepath.Join(t.TempDir(), "editor.sh")
+	require.NoError(t, os.WriteFile(editor, []byte("#!/bin/sh\necho \"select 1; select 2\" > \"$1\"\n"), 0700))
+	t.Setenv("EDITOR", editor)
+
+	history := &queryhistory.QueryHistory{}
+	input := &HandlerInput{
+		Query:   ".edit run",
+		History: history,
+		ExecuteQuery: func(_ context.Context, q *modconfig.ResolvedQuery, historyEntry *queryhistory.HistoryEntry) {
+			// the result of each statement is recorded in its own history entry
+			require.NotNil(t, historyEntry)
+			assert.Equal(t, q.RawSQL, historyEntry.Query)
+			historyEntry.SetResult(time.Second, 1, nil, nil)
+		},
+	}
+	require.NoError(t, editQuery(context.Background(), input))
+
+	entries := history.Entries()
+	require.Len(t, entries, 2)
+	for i, query := range []string{"select 1", "select 2"} {
+		assert.Equal(t, query, entries[i].Query)
+		assert.Equal(t, int64(1), entries[i].Rows)
+		assert.Equal(t, queryhistory.StatusSuccess, entries[i].Status)
+	}
+}
@@ -0,0 +1,151 @@
+package metaquery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/turbot/pipe-fittings/v2/querydisplay"
+	"github.com/turbot/steampipe-plugin-sdk/v5/sperr"
+	"github.com/turbot/steampipe/v2/pkg/constants"
+	"github.com/turbot/steampipe/v2/pkg/display"
+)
+
+// .explain
+// show the query plan, annotating each foreign scan with the quals which are pushed down to the plugin
+// the output is written to the output file if the output is redirected with .out or .tee
+func explain(ctx context.Context, input *HandlerInput) error {
+	sql := strings.TrimSuffix(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(input.Query), constants.CmdExplain)), ";")
+
+	sessionResult := input.Client.AcquireSession(ctx)
+	if sessionResult.Error != nil {
+		return sessionResult.Error
+	}
+	defer func() {
+		// we need to do this in a closure, otherwise the ctx will be evaluated immediately
+		// and not in call-time
+		sessionResult.Session.Close(false)
+	}()
+	conn := sessionResult.Session.Connection.Conn()
+
+	// show the plan as postgres displays it
+	planText, err := getExplainText(ctx, conn, sql)
+	if err != nil {
+		return err
+	}
+
+	// now get the plan as json, to analyse the foreign scans
+	var planJSON []byte
+	if err := conn.QueryRow(ctx, fmt.Sprintf("EXPLAIN (VERBOSE, FORMAT JSON) %s", sql)).Scan(&planJSON); err != nil {
+		return err
+	}
+	plan, err := parseExplainPlan(planJSON)
+	if err != nil {
+		return err
+	}
+	scans := plan.foreignScans()
+	if len(scans) == 0 {
+		return display.WithOutputRedirect(func() {
+			fmt.Println(planText)
+			fmt.Println("The query does not scan any steampipe tables.")
+		})
+	}
+
+	connectionStateMap, err := input.GetConnectionStateMap(ctx)
+	if err != nil {
+		return err
+	}
+
+	header := []string{"Connection", "Table", "Call", "Pushed down quals", "Locally filtered quals", "Missing required quals"}
+	var rows [][]string
+	for _, scan := range scans {
+		var keyColumns *tableKeyColumns
+		if connectionState, ok := connectionStateMap[scan.Schema]; ok {
+			keyColumns, err = getTableKeyColumns(ctx, conn, connectionState.Plugin, scan.RelationName)
+			if err != nil {
+				return err
+			}
+		}
+		annotation := annotateForeignScan(scan, keyColumns)
+
+		call := "list"
+		if annotation.get {
+			call = "get"
+		}
+		rows = append(rows, []string{
+			annotation.connection,
+			annotation.table,
+			call,
+			joinQuals(annotation.pushedDown),
+			joinQuals(annotation.local),
+			strings.Join(annotation.missingRequired, "\n"),
+		})
+	}
+	return display.WithOutputRedirect(func() {
+		fmt.Println(planText)
+		fmt.Println()
+		querydisplay.ShowWrappedTable(header, rows, &querydisplay.ShowWrappedTableOptions{AutoMerge: false})
+	})
+}
+
+func getExplainText(ctx context.Context, conn *pgx.Conn, sql string) (string, error) {
+	rows, err := conn.Query(ctx, fmt.Sprintf("EXPLAIN %s", sql))
+	if err != nil {
+		return "", err
+	}
+	lines, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// getTableKeyColumns reads the key column config of a table from the plugin column introspection table
+func getTableKeyColumns(ctx context.Context, conn *pgx.Conn, plugin, table string) (*tableKeyColumns, error) {
+	query := fmt.Sprintf(`SELECT name, list_config, get_config FROM %s.%s
+WHERE plugin = $1 AND table_name = $2 AND (list_config IS NOT NULL OR get_config IS NOT NULL)`,
+		constants.InternalSchema, constants.PluginColumnTable)
+	rows, err := conn.Query(ctx, query, plugin, table)
+	if err != nil {
+		return nil, sperr.WrapWithMessage(err, "failed to read key columns of table '%s'", table)
+	}
+	defer rows.Close()
+
+	res := newTableKeyColumns()
+	for rows.Next() {
+		var name string
+		var listConfig, getConfig []byte
+		if err := rows.Scan(&name, &listConfig, &getConfig); err != nil {
+			return nil, err
+		}
+		if err := addKeyColumnConfig(res.list, name, listConfig); err != nil {
+			return nil, err
+		}
+		if err := addKeyColumnConfig(res.get, name, getConfig); err != nil {
+			return nil, err
+		}
+	}
+	return res, rows.Err()
+}
+
+func addKeyColumnConfig(target map[string]*keyColumnConfig, column string, data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	config := &keyColumnConfig{}
+	if err := json.Unmarshal(data, config); err != nil {
+		return sperr.WrapWithMessage(err, "failed to parse key column config of column '%s'", column)
+	}
+	target[column] = config
+	return nil
+}
+
+func joinQuals(quals []explainQual) string {
+	res := make([]string, len(quals))
+	for i, q := range quals {
+		res[i] = q.String()
+	}
+	return strings.Join(res, "\n")
+}
@@ -0,0 +1,32 @@
+package metaquery
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// .export
+// export the result of the last query using the registered exporters
+func exportLastResult(ctx context.Context, input *HandlerInput) error {
+	exports := input.args()
+	if err := input.ExportManager.ValidateExportFormat(exports); err != nil {
+		return err
+	}
+
+	result, err := input.LastResult()
+	if err != nil {
+		return err
+	}
+	if result == nil {
+		return fmt.Errorf("there is no query result to export - the last query failed, or no query has been run")
+	}
+
+	// each export is a separate run, so it replaces the files of any previous export
+	input.ExportManager.StartRun()
+	exportMsg, err := input.ExportManager.DoExport(ctx, "query", result, exports)
+	if len(exportMsg) > 0 {
+		fmt.Println(strings.Join(exportMsg, "\n"))
+	}
+	return err
+}
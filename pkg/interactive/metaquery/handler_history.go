@@ -0,0 +1,76 @@
+package metaquery
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/turbot/pipe-fittings/v2/querydisplay"
+	"github.com/turbot/steampipe/v2/pkg/query/queryhistory"
+)
+
+// .history
+// show the query history entries which match the filter
+func showHistory(_ context.Context, input *HandlerInput) error {
+	if input.History == nil {
+		return fmt.Errorf("query history is not available")
+	}
+	filter, err := queryhistory.ParseFilter(input.args(), time.Now())
+	if err != nil {
+		return err
+	}
+
+	// the current .history command will be the most recent entry - exclude it
+	current := input.History.Peek()
+	limit := filter.Limit
+	filter.Limit++
+	var entries []*queryhistory.HistoryEntry
+	for _, entry := range input.History.Find(filter) {
+		if entry != current {
+			entries = append(entries, entry)
+		}
+	}
+	if len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("No matching queries found.")
+		return nil
+	}
+
+	header := []string{"Timestamp", "Duration", "Rows", "Status", "Connections", "Query"}
+	var rows [][]string
+	for _, entry := range entries {
+		rows = append(rows, historyEntryRow(entry))
+	}
+	querydisplay.ShowWrappedTable(header, rows, &querydisplay.ShowWrappedTableOptions{AutoMerge: false})
+	return nil
+}
+
+func historyEntryRow(entry *queryhistory.HistoryEntry) []string {
+	// entries from previous versions have no metadata
+	if entry.Status == "" {
+		return []string{"", "", "", "", "", entry.Query}
+	}
+	status := string(entry.Status)
+	if entry.Error != "" {
+		status = fmt.Sprintf("%s: %s", status, entry.Error)
+	}
+	return []string{
+		entry.Timestamp.Local().Format(time.DateTime),
+		(time.Duration(entry.DurationMs) * time.Millisecond).String(),
+		fmt.Sprintf("%d", entry.Rows),
+		status,
+		strings.Join(entry.Connections, ", "),
+		entry.Query,
+	}
+}
+
+func historyFilterValidator(args []string) ValidationResult {
+	if _, err := queryhistory.ParseFilter(args, time.Now()); err != nil {
+		return ValidationResult{Err: err}
+	}
+	return ValidationResult{ShouldRun: true}
+}
@@ -4,12 +4,20 @@ import (
 	"context"
 
 	"github.com/c-bata/go-prompt"
+	"github.com/turbot/pipe-fittings/v2/modconfig"
 	"github.com/turbot/steampipe/v2/pkg/db/db_common"
+	"github.com/turbot/steampipe/v2/pkg/export"
+	"github.com/turbot/steampipe/v2/pkg/query/queryhistory"
+	"github.com/turbot/steampipe/v2/pkg/query/queryresult"
 	"github.com/turbot/steampipe/v2/pkg/steampipeconfig"
 )
 
 type ConnectionStateGetter func(context.Context) (steampipeconfig.ConnectionStateMap, error)
 
+// QueryExecutor executes a query, displaying the result in the current output format.
+// If historyEntry is not nil, the result of the query is recorded in it
+type QueryExecutor func(ctx context.Context, resolvedQuery *modconfig.ResolvedQuery, historyEntry *queryhistory.HistoryEntry)
+
 // HandlerInput defines input data for the metaquery handler
 type HandlerInput struct {
 	Client db_common.Client
@@ -20,6 +28,26 @@ type HandlerInput struct {
 	Query                 string
 	GetConnectionStateMap ConnectionStateGetter
 	SearchPath            []string
+	History               *queryhistory.QueryHistory
+	ExecuteQuery          QueryExecutor
+	ExportManager         *export.Manager
+	CacheStats            *queryresult.CacheStats
+	// returns the result of the last query, to export - nil if there is no result
+	LastResult func() (export.ExportSourceData, error)
+	// the lines of a multi-line query which were entered before the metaquery
+	IncompleteQuery string
+	// sets text to load into the prompt buffer when the prompt restarts
+	SetPromptText func(string)
+	// validates and executes a metaquery, as if it had been entered at the prompt
+	ExecuteMetaquery func(context.Context, string) error
+	// the name of the current theme
+	Theme string
+	// loads the named theme and uses it to highlight sql and show table output
+	SetTheme func(string) error
+	// starts recording the session to a transcript file - an empty path stops the recording
+	Record func(path string) error
+	// the path of the transcript file the session is recorded to - empty if the session is not being recorded
+	RecordingPath string
 }
 
 func (h *HandlerInput) args() []string {
@@ -0,0 +1,50 @@
+package metaquery
+
+import (
+	"context"
+	"fmt"
+
+	pconstants "github.com/turbot/pipe-fittings/v2/constants"
+	"github.com/turbot/steampipe/v2/pkg/display"
+)
+
+// .out and .tee
+// write subsequent query results to a file, and if tee is set, also show them on screen
+func redirectOutput(tee bool) handler {
+	return func(_ context.Context, input *HandlerInput) error {
+		args := input.args()
+		if len(args) == 0 {
+			showOutputRedirect()
+			return nil
+		}
+
+		if args[0] == pconstants.ArgOff {
+			path, _ := display.OutputRedirectTarget()
+			if err := display.StopOutputRedirect(); err != nil {
+				return err
+			}
+			if path != "" {
+				fmt.Printf("Stopped writing results to %s\n", path)
+			}
+			return nil
+		}
+
+		if err := display.RedirectOutput(args[0], tee); err != nil {
+			return err
+		}
+		showOutputRedirect()
+		return nil
+	}
+}
+
+func showOutputRedirect() {
+	path, tee := display.OutputRedirectTarget()
+	switch {
+	case path == "":
+		fmt.Println("Results are shown on the screen.")
+	case tee:
+		fmt.Printf("Results are written to %s and shown on the screen.\n", pconstants.Bold(path))
+	default:
+		fmt.Printf("Results are written to %s.\n", pconstants.Bold(path))
+	}
+}
@@ -0,0 +1,49 @@
+package metaquery
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	pconstants "github.com/turbot/pipe-fittings/v2/constants"
+	"github.com/turbot/steampipe/v2/pkg/constants"
+)
+
+// the args of .record
+const (
+	recordArgStart = "start"
+	recordArgStop  = "stop"
+)
+
+// .record
+// start or stop recording the session to a transcript file, or show whether the session is being recorded
+func recordSession(_ context.Context, input *HandlerInput) error {
+	args := input.args()
+	if len(args) == 0 {
+		if input.RecordingPath == "" {
+			fmt.Printf("The session is not being recorded. You can start recording with: %s\n", pconstants.Bold(fmt.Sprintf("%s %s <file>", constants.CmdRecord, recordArgStart)))
+			return nil
+		}
+		fmt.Printf("The session is being recorded to %s\n", pconstants.Bold(input.RecordingPath))
+		return nil
+	}
+
+	if strings.ToLower(args[0]) == recordArgStop {
+		if input.RecordingPath == "" {
+			fmt.Println("The session is not being recorded.")
+			return nil
+		}
+		if err := input.Record(""); err != nil {
+			return err
+		}
+		fmt.Printf("Stopped recording to %s. Replay the session with: %s\n", input.RecordingPath, pconstants.Bold(fmt.Sprintf("steampipe query --replay %s", input.RecordingPath)))
+		return nil
+	}
+
+	path := args[1]
+	if err := input.Record(path); err != nil {
+		return err
+	}
+	fmt.Printf("Recording the session to %s\n", pconstants.Bold(path))
+	return nil
+}
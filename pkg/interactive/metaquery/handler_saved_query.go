@@ -0,0 +1,122 @@
+package metaquery
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/turbot/pipe-fittings/v2/querydisplay"
+	"github.com/turbot/steampipe/v2/pkg/constants"
+	"github.com/turbot/steampipe/v2/pkg/query"
+	"github.com/turbot/steampipe/v2/pkg/query/savedquery"
+)
+
+// .save
+// save the given query, or the last query which was executed, by name
+func saveQuery(_ context.Context, input *HandlerInput) error {
+	name := input.args()[0]
+	if err := savedquery.ValidateName(name); err != nil {
+		return err
+	}
+
+	// the sql is the raw text following the name, so it is saved exactly as typed
+	sql := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(input.Query), constants.CmdSave))
+	sql = strings.TrimSpace(strings.TrimPrefix(sql, name))
+	if sql == "" {
+		sql = lastQuery(input)
+		if sql == "" {
+			return fmt.Errorf("there is no query to save - specify the sql to save, e.g. %s %s select * from my_table", constants.CmdSave, name)
+		}
+	}
+
+	replaced, err := savedquery.Save(name, sql)
+	if err != nil {
+		return err
+	}
+	if replaced {
+		fmt.Printf("Updated saved query '%s'\n", name)
+	} else {
+		fmt.Printf("Saved query '%s'\n", name)
+	}
+	return nil
+}
+
+// lastQuery returns the most recent query in the history which is not a metaquery
+func lastQuery(input *HandlerInput) string {
+	if input.History == nil {
+		return ""
+	}
+	entries := input.History.Entries()
+	for i := len(entries) - 1; i >= 0; i-- {
+		if q := strings.TrimSpace(entries[i].Query); !IsMetaQuery(q) {
+			return q
+		}
+	}
+	return ""
+}
+
+// .run
+// run a saved query, passing the remaining arguments as query args
+func runSavedQuery(ctx context.Context, input *HandlerInput) error {
+	args := input.args()
+	name := args[0]
+	savedQuery, err := savedquery.Load(name)
+	if err != nil {
+		return err
+	}
+	if savedQuery == nil {
+		return fmt.Errorf("saved query '%s' not found - use %s to list saved queries", name, constants.CmdQueries)
+	}
+	queryArgs, err := query.ParseQueryArgs(args[1:])
+	if err != nil {
+		return err
+	}
+
+	for _, q := range query.StatementQueries(savedQuery.SQL, queryArgs) {
+		// stop if the execution has been cancelled
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		input.ExecuteQuery(ctx, q, nil)
+	}
+	return nil
+}
+
+// .queries
+// list the saved queries, or show the sql of a saved query
+func listSavedQueries(_ context.Context, input *HandlerInput) error {
+	if args := input.args(); len(args) == 1 {
+		savedQuery, err := savedquery.Load(args[0])
+		if err != nil {
+			return err
+		}
+		if savedQuery == nil {
+			return fmt.Errorf("saved query '%s' not found", args[0])
+		}
+		fmt.Println(savedQuery.SQL)
+		return nil
+	}
+
+	queries, err := savedquery.List()
+	if err != nil {
+		return err
+	}
+	if len(queries) == 0 {
+		fmt.Printf("No saved queries. Use %s <name> to save the last query.\n", constants.CmdSave)
+		return nil
+	}
+
+	header := []string{"Name", "Modified", "Lines", "Query"}
+	var rows [][]string
+	for _, q := range queries {
+		lines := strings.Split(q.SQL, "\n")
+		firstLine := lines[0]
+		if len(lines) > 1 {
+			firstLine += " …"
+		}
+		rows = append(rows, []string{q.Name, q.ModTime.Local().Format(time.DateTime), fmt.Sprintf("%d", len(lines)), firstLine})
+	}
+	querydisplay.ShowWrappedTable(header, rows, &querydisplay.ShowWrappedTableOptions{AutoMerge: false})
+	return nil
+}
@@ -0,0 +1,96 @@
+package metaquery
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/sperr"
+	"github.com/turbot/steampipe/v2/pkg/error_helpers"
+	"github.com/turbot/steampipe/v2/pkg/query"
+)
+
+// the maximum depth of files sourced by sourced files - this stops a file which sources itself from recursing forever
+const maxSourceDepth = 16
+
+type sourceDepthKey struct{}
+
+// sourceCommand is a block of sql statements, or a single metaquery, read from a sourced file
+type sourceCommand struct {
+	text        string
+	isMetaquery bool
+}
+
+// .source
+// execute the sql statements and metaqueries in a file, in the current session
+func sourceFile(ctx context.Context, input *HandlerInput) error {
+	path := input.args()[0]
+	depth, _ := ctx.Value(sourceDepthKey{}).(int)
+	if depth >= maxSourceDepth {
+		return fmt.Errorf("cannot source '%s' - files are nested more than %d deep", path, maxSourceDepth)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return sperr.WrapWithMessage(err, "failed to read '%s'", path)
+	}
+
+	ctx = context.WithValue(ctx, sourceDepthKey{}, depth+1)
+	for _, command := range parseSourceFile(string(data)) {
+		// stop if the execution has been cancelled
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if command.isMetaquery {
+			// show the error and continue, in the same way as a failed sql statement
+			if err := input.ExecuteMetaquery(ctx, command.text); err != nil {
+				error_helpers.ShowError(ctx, err)
+			}
+			continue
+		}
+		for _, q := range query.StatementQueries(command.text, nil) {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			input.ExecuteQuery(ctx, q, nil)
+		}
+	}
+	return nil
+}
+
+// parseSourceFile splits the contents of a sourced file into blocks of sql and metaqueries.
+// A line is a metaquery if it starts with a metaquery command and is not part of an incomplete sql statement
+func parseSourceFile(content string) []sourceCommand {
+	var commands []sourceCommand
+	var sqlLines []string
+	flushSQL := func() {
+		if sql := strings.TrimSpace(strings.Join(sqlLines, "\n")); sql != "" {
+			commands = append(commands, sourceCommand{text: sql})
+		}
+		sqlLines = nil
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if IsMetaQuery(trimmed) && isStatementBoundary(sqlLines) {
+			flushSQL()
+			commands = append(commands, sourceCommand{text: trimmed, isMetaquery: true})
+			continue
+		}
+		sqlLines = append(sqlLines, line)
+	}
+	flushSQL()
+	return commands
+}
+
+// isStatementBoundary returns whether the sql lines are empty or end with a complete statement, ignoring comment lines
+func isStatementBoundary(sqlLines []string) bool {
+	for i := len(sqlLines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(sqlLines[i])
+		if line == "" || strings.HasPrefix(line, "--") {
+			continue
+		}
+		return strings.HasSuffix(line, ";")
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package metaquery
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/turbot/pipe-fittings/v2/modconfig"
+	"github.com/turbot/steampipe/v2/pkg/query/queryhistory"
+)
+
+func TestParseSourceFile(t *testing.T) {
+	content := `-- setup
+.timing on
+create temp table t as select 1 as a;
+.header off
+select *
+  from t
+.not_a_metaquery
+;
+-- done
+.output json
+select 2;
+`
+	expected := []sourceCommand{
+		// comment only blocks are dropped when the statements are split
+		{text: "-- setup"},
+		{text: ".timing on", isMetaquery: true},
+		{text: "create temp table t as select 1 as a;"},
+		{text: ".header off", isMetaquery: true},
+		{text: "select *\n  from t\n.not_a_metaquery\n;\n-- done"},
+		{text: ".output json", isMetaquery: true},
+		{text: "select 2;"},
+	}
+	assert.Equal(t, expected, parseSourceFile(content))
+}
+
+func TestParseSourceFile_MetaqueryInsideStatement(t *testing.T) {
+	// a line starting with a metaquery command which continues a statement is sql
+	content := "select 1\n.header off\n;"
+	assert.Equal(t, []sourceCommand{{text: content}}, parseSourceFile(content))
+}
+
+func TestSourceFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "setup.sql")
+	require.NoError(t, os.WriteFile(path, []byte("select 1; select 2;\n.header off\nselect 3;\n"), 0600))
+
+	var executed []string
+	input := &HandlerInput{
+		Query: ".source " + path,
+		ExecuteQuery: func(_ context.Context, q *modconfig.ResolvedQuery, _ *queryhistory.HistoryEntry) {
+			executed = append(executed, q.ExecuteSQL)
+		},
+		ExecuteMetaquery: func(_ context.Context, q string) error {
+			executed = append(executed, q)
+			return nil
+		},
+	}
+	require.NoError(t, sourceFile(context.Background(), input))
+	assert.Equal(t, []string{"select 1", "select 2", ".header off", "select 3"}, executed)
+}
+
+func TestSourceFile_Recursive(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "loop.sql")
+	require.NoError(t, os.WriteFile(path, []byte(".source "+path+"\n"), 0600))
+
+	var sourceErr error
+	input := &HandlerInput{Query: ".source " + path}
+	input.ExecuteMetaquery = func(ctx context.Context, q string) error {
+		nested := *input
+		nested.Query = q
+		if err := sourceFile(ctx, &nested); err != nil && sourceErr == nil {
+			sourceErr = err
+		}
+		return nil
+	}
+	require.NoError(t, sourceFile(context.Background(), input))
+	assert.ErrorContains(t, sourceErr, "nested more than")
+}
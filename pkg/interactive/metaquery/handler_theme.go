@@ -0,0 +1,33 @@
+package metaquery
+
+import (
+	"context"
+	"fmt"
+
+	pconstants "github.com/turbot/pipe-fittings/v2/constants"
+	"github.com/turbot/steampipe/v2/pkg/display"
+	"github.com/turbot/steampipe/v2/pkg/filepaths"
+)
+
+// .theme
+// set the theme used to highlight sql and show table output, or list the themes if no theme is given
+func setTheme(_ context.Context, input *HandlerInput) error {
+	args := input.args()
+	if len(args) == 0 {
+		showThemes(input.Theme)
+		return nil
+	}
+	return input.SetTheme(args[0])
+}
+
+func showThemes(current string) {
+	fmt.Println("Themes:")
+	for _, name := range display.ThemeNames() {
+		if name == current {
+			fmt.Printf("  %s (current)\n", pconstants.Bold(name))
+			continue
+		}
+		fmt.Printf("  %s\n", name)
+	}
+	fmt.Printf("\nAdd theme files to %s, or use any chroma style.\n", filepaths.ThemesDir())
+}
@@ -72,6 +72,12 @@ func setViperConfigFromArg(viperKey string) handler {
 	}
 }
 
+// .viewer
+func setResultViewer(_ context.Context, input *HandlerInput) error {
+	cmdconfig.Viper().Set(constants.ArgResultViewer, typeHelpers.StringToBool(input.args()[0]))
+	return nil
+}
+
 // .exit
 func doExit(_ context.Context, input *HandlerInput) error {
 	input.ClosePrompt()
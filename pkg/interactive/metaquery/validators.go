@@ -8,6 +8,7 @@ import (
 	pconstants "github.com/turbot/pipe-fittings/v2/constants"
 	"github.com/turbot/pipe-fittings/v2/utils"
 	"github.com/turbot/steampipe/v2/pkg/cmdconfig"
+	"github.com/turbot/steampipe/v2/pkg/constants"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 )
@@ -103,6 +104,34 @@ func validatorFromArgsOf(cmd string) validator {
 	}
 }
 
+// cacheValidator validates the args of .cache - 'clear' may be followed by the name of a connection
+func cacheValidator(args []string) ValidationResult {
+	if len(args) == 2 && strings.ToLower(args[0]) == pconstants.ArgClear {
+		return ValidationResult{ShouldRun: true}
+	}
+	return composeValidator(atMostNArgs(1), validatorFromArgsOf(constants.CmdCache))(args)
+}
+
+// recordValidator validates the args of .record - 'start' must be followed by the transcript file
+func recordValidator(args []string) ValidationResult {
+	if len(args) == 0 {
+		return ValidationResult{ShouldRun: true}
+	}
+	switch strings.ToLower(args[0]) {
+	case recordArgStart:
+		if len(args) != 2 {
+			return ValidationResult{Err: fmt.Errorf("%s needs the path of the transcript file, e.g. %s %s session.jsonl", recordArgStart, constants.CmdRecord, recordArgStart)}
+		}
+	case recordArgStop:
+		if len(args) != 1 {
+			return ValidationResult{Err: fmt.Errorf("%s does not take any arguments", recordArgStop)}
+		}
+	default:
+		return ValidationResult{Err: fmt.Errorf("valid values for this command are %s and %s", recordArgStart, recordArgStop)}
+	}
+	return ValidationResult{ShouldRun: true}
+}
+
 var atLeastNArgs = func(n int) validator {
 	return func(args []string) ValidationResult {
 		numArgs := len(args)
@@ -0,0 +1,43 @@
+package metaquery
+
+import (
+	"testing"
+)
+
+func TestValidate_Cache(t *testing.T) {
+	cases := map[string]bool{
+		`.cache`:                true,
+		`.cache on`:             true,
+		`.cache clear`:          true,
+		`.cache clear aws_prod`: true,
+		`.cache stats`:          true,
+		`.cache foo`:            false,
+		`.cache on aws_prod`:    false,
+		`.cache clear a b`:      false,
+	}
+
+	for input, valid := range cases {
+		res := Validate(input)
+		if (res.Err == nil) != valid {
+			t.Errorf("%s: expected valid=%v, got err %v", input, valid, res.Err)
+		}
+	}
+}
+
+func TestValidate_Record(t *testing.T) {
+	cases := map[string]bool{
+		`.record`:                     true,
+		`.record start session.jsonl`: true,
+		`.record stop`:                true,
+		`.record start`:               false,
+		`.record stop session.jsonl`:  false,
+		`.record pause`:               false,
+	}
+
+	for input, valid := range cases {
+		res := Validate(input)
+		if (res.Err == nil) != valid {
+			t.Errorf("%s: expected valid=%v, got err %v", input, valid, res.Err)
+		}
+	}
+}
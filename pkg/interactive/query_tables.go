@@ -0,0 +1,195 @@
+package interactive
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/turbot/steampipe/v2/pkg/db/db_common"
+)
+
+// tableReference is a table referenced in the FROM or JOIN clause of a query
+type tableReference struct {
+	// the schema of the table - empty if the table is unqualified
+	schema string
+	name   string
+	// the alias of the table - empty if there is none
+	alias string
+}
+
+// keywords which may follow a table reference, and so cannot be an alias
+var tableReferenceTerminators = map[string]struct{}{
+	"where": {}, "join": {}, "left": {}, "right": {}, "inner": {}, "outer": {}, "full": {}, "cross": {},
+	"natural": {}, "on": {}, "using": {}, "group": {}, "order": {}, "limit": {}, "offset": {}, "having": {},
+	"union": {}, "intersect": {}, "except": {}, "window": {}, "for": {}, "fetch": {}, "lateral": {},
+}
+
+// getTableReferences returns the tables referenced in the FROM and JOIN clauses of a query.
+// This is a lightweight tokenizer rather than a full parser - it is used for autocomplete and history metadata
+func getTableReferences(sql string) []tableReference {
+	tokens := tokenizeSQL(sql)
+	var res []tableReference
+	for i := 0; i < len(tokens); i++ {
+		keyword := strings.ToLower(tokens[i])
+		if keyword != "from" && keyword != "join" {
+			continue
+		}
+		// parse the list of tables - JOIN is followed by a single table, FROM may be followed by a comma separated list
+		for i++; i < len(tokens); i++ {
+			if strings.EqualFold(tokens[i], "lateral") || strings.EqualFold(tokens[i], "only") {
+				i++
+			}
+			if i >= len(tokens) || !isIdentifier(tokens[i]) {
+				break
+			}
+			ref := newTableReference(tokens[i])
+
+			// is there an alias
+			if i+1 < len(tokens) && strings.EqualFold(tokens[i+1], "as") {
+				i++
+			}
+			if i+1 < len(tokens) && isIdentifier(tokens[i+1]) && !isTableReferenceTerminator(tokens[i+1]) {
+				i++
+				ref.alias = unquoteIdentifier(tokens[i])
+			}
+			res = append(res, ref)
+
+			if keyword == "join" || i+1 >= len(tokens) || tokens[i+1] != "," {
+				break
+			}
+			// skip the comma
+			i++
+		}
+	}
+	return res
+}
+
+func newTableReference(token string) tableReference {
+	parts := splitQualifiedIdentifier(token)
+	if len(parts) == 1 {
+		return tableReference{name: parts[0]}
+	}
+	return tableReference{schema: parts[len(parts)-2], name: parts[len(parts)-1]}
+}
+
+// tokenizeSQL splits sql into identifiers (which may be quoted and qualified) and punctuation,
+// skipping whitespace, comments and string literals
+func tokenizeSQL(sql string) []string {
+	var tokens []string
+	runes := []rune(sql)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			// line comment
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case r == '\'':
+			// string literal - a quote is escaped by doubling it, which is handled by starting a new literal
+			for i++; i < len(runes) && runes[i] != '\''; i++ {
+			}
+		case r == '"' || isIdentifierRune(r):
+			start := i
+			i = scanQualifiedIdentifier(runes, i)
+			tokens = append(tokens, string(runes[start:i]))
+			i--
+		default:
+			tokens = append(tokens, string(r))
+		}
+	}
+	return tokens
+}
+
+// scanQualifiedIdentifier returns the index after the (possibly quoted and qualified) identifier starting at i
+func scanQualifiedIdentifier(runes []rune, i int) int {
+	for {
+		if i < len(runes) && runes[i] == '"' {
+			for i++; i < len(runes) && runes[i] != '"'; i++ {
+			}
+			i++
+		} else {
+			for i < len(runes) && isIdentifierRune(runes[i]) {
+				i++
+			}
+		}
+		if i < len(runes) && runes[i] == '.' && i+1 < len(runes) && (runes[i+1] == '"' || isIdentifierRune(runes[i+1])) {
+			i++
+			continue
+		}
+		return min(i, len(runes))
+	}
+}
+
+func isIdentifierRune(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func isIdentifier(token string) bool {
+	r := []rune(token)[0]
+	return r == '"' || r == '_' || unicode.IsLetter(r)
+}
+
+func isTableReferenceTerminator(token string) bool {
+	_, ok := tableReferenceTerminators[strings.ToLower(token)]
+	return ok
+}
+
+// splitQualifiedIdentifier splits a qualified identifier into its (unquoted) parts
+func splitQualifiedIdentifier(token string) []string {
+	var parts []string
+	inQuote := false
+	start := 0
+	for i, r := range token {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+		case r == '.' && !inQuote:
+			parts = append(parts, unquoteIdentifier(token[start:i]))
+			start = i + 1
+		}
+	}
+	return append(parts, unquoteIdentifier(token[start:]))
+}
+
+// unquoteIdentifier removes the quotes from a quoted identifier, or lower cases an unquoted identifier
+func unquoteIdentifier(identifier string) string {
+	if len(identifier) >= 2 && strings.HasPrefix(identifier, `"`) && strings.HasSuffix(identifier, `"`) {
+		return identifier[1 : len(identifier)-1]
+	}
+	return strings.ToLower(identifier)
+}
+
+// getQueryConnections returns the connections used by the tables referenced by a query.
+// Unqualified tables are resolved using the search path
+func getQueryConnections(sql string, schemaMetadata *db_common.SchemaMetadata, searchPath []string) []string {
+	if schemaMetadata == nil {
+		return nil
+	}
+	var res []string
+	for _, ref := range getTableReferences(sql) {
+		connection := resolveTableSchema(ref, schemaMetadata, searchPath)
+		if connection != "" && !slices.Contains(res, connection) {
+			res = append(res, connection)
+		}
+	}
+	slices.Sort(res)
+	return res
+}
+
+// resolveTableSchema returns the schema of a referenced table, or an empty string if it is not a known table
+func resolveTableSchema(ref tableReference, schemaMetadata *db_common.SchemaMetadata, searchPath []string) string {
+	if ref.schema != "" {
+		if _, ok := schemaMetadata.Schemas[ref.schema][ref.name]; ok {
+			return ref.schema
+		}
+		return ""
+	}
+	for _, schema := range searchPath {
+		if _, ok := schemaMetadata.Schemas[schema][ref.name]; ok {
+			return schema
+		}
+	}
+	return ""
+}
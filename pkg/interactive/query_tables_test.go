@@ -0,0 +1,107 @@
+package interactive
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/turbot/steampipe/v2/pkg/db/db_common"
+)
+
+func TestGetTableReferences(t *testing.T) {
+	tests := []struct {
+		name     string
+		sql      string
+		expected []tableReference
+	}{
+		{
+			name:     "unqualified table",
+			sql:      "select * from aws_s3_bucket",
+			expected: []tableReference{{name: "aws_s3_bucket"}},
+		},
+		{
+			name:     "qualified table with alias",
+			sql:      "select b.name from aws.aws_s3_bucket as b where b.region = 'us-east-1'",
+			expected: []tableReference{{schema: "aws", name: "aws_s3_bucket", alias: "b"}},
+		},
+		{
+			name: "joins",
+			sql:  "select * from aws_account a join aws_s3_bucket b on a.account_id = b.account_id left outer join \"My Schema\".\"My Table\" t using (id)",
+			expected: []tableReference{
+				{name: "aws_account", alias: "a"},
+				{name: "aws_s3_bucket", alias: "b"},
+				{schema: "My Schema", name: "My Table", alias: "t"},
+			},
+		},
+		{
+			name:     "comma separated from list",
+			sql:      "SELECT * FROM github_repo r, github_user WHERE r.owner = github_user.login",
+			expected: []tableReference{{name: "github_repo", alias: "r"}, {name: "github_user"}},
+		},
+		{
+			name:     "subquery",
+			sql:      "select * from (select * from aws_iam_user) u",
+			expected: []tableReference{{name: "aws_iam_user"}},
+		},
+		{
+			name:     "from in string literal and comment",
+			sql:      "select 'from nowhere' -- from here\nfrom csv.data",
+			expected: []tableReference{{schema: "csv", name: "data"}},
+		},
+		{
+			name:     "incomplete query",
+			sql:      "select * from ",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := getTableReferences(tt.sql)
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestGetQueryConnections(t *testing.T) {
+	schemaMetadata := &db_common.SchemaMetadata{
+		Schemas: map[string]map[string]db_common.TableSchema{
+			"aws":     {"aws_s3_bucket": {}, "aws_account": {}},
+			"aws_dev": {"aws_s3_bucket": {}, "aws_account": {}},
+			"github":  {"github_repo": {}},
+		},
+	}
+	searchPath := []string{"public", "aws", "aws_dev", "github"}
+
+	tests := []struct {
+		name     string
+		sql      string
+		expected []string
+	}{
+		{
+			name:     "unqualified tables are resolved using the search path",
+			sql:      "select * from aws_s3_bucket join github_repo on true",
+			expected: []string{"aws", "github"},
+		},
+		{
+			name:     "qualified tables",
+			sql:      "select * from aws_dev.aws_s3_bucket, aws.aws_account",
+			expected: []string{"aws", "aws_dev"},
+		},
+		{
+			name:     "unknown tables are ignored",
+			sql:      "select * from generate_series(1, 10), pg_tables",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := getQueryConnections(tt.sql, schemaMetadata, searchPath)
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package interactive
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/turbot/pipe-fittings/v2/modconfig"
+	pqueryresult "github.com/turbot/pipe-fittings/v2/queryresult"
+	"github.com/turbot/steampipe/v2/pkg/constants"
+	"github.com/turbot/steampipe/v2/pkg/error_helpers"
+	"github.com/turbot/steampipe/v2/pkg/query/transcript"
+	"github.com/turbot/steampipe/v2/pkg/snapshot"
+)
+
+// record starts recording the session to a transcript file, stopping any current recording.
+// If the path is empty, the current recording is stopped
+func (c *InteractiveClient) record(path string) error {
+	if c.recorder != nil {
+		err := c.recorder.Close()
+		c.recorder = nil
+		if err != nil {
+			return err
+		}
+	}
+	if path == "" {
+		return nil
+	}
+	recorder, err := transcript.NewRecorder(path, c.sessionSettings())
+	if err != nil {
+		return err
+	}
+	c.recorder = recorder
+	return nil
+}
+
+// recordingPath returns the path of the transcript file the session is recorded to - empty if the session is not being recorded
+func (c *InteractiveClient) recordingPath() string {
+	if c.recorder == nil {
+		return ""
+	}
+	return c.recorder.Path()
+}
+
+// sessionSettings returns the settings of the session which are recorded in a transcript
+func (c *InteractiveClient) sessionSettings() transcript.Settings {
+	return transcript.CurrentSettings(c.client().GetRequiredSessionSearchPath())
+}
+
+// recordQuery records a query and its result in the transcript, if the session is being recorded
+func (c *InteractiveClient) recordQuery(resolvedQuery *modconfig.ResolvedQuery, startTime time.Time, cols []*pqueryresult.ColumnDef, rows [][]any, err error) {
+	if c.recorder == nil {
+		return
+	}
+	entry := &transcript.Entry{
+		Type:       transcript.EntryQuery,
+		Time:       startTime,
+		Input:      resolvedQuery.RawSQL,
+		SQL:        resolvedQuery.ExecuteSQL,
+		Args:       resolvedQuery.Args,
+		DurationMs: time.Since(startTime).Milliseconds(),
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if cols != nil {
+		entry.Columns = snapshot.ColumnDefs(cols)
+		entry.Rows = make([]map[string]any, len(rows))
+		for i, row := range rows {
+			entry.Rows[i] = snapshot.RowData(row, cols)
+		}
+	}
+	c.writeTranscriptEntry(entry)
+}
+
+// recordMetaquery records a metaquery, and the settings it changed, in the transcript, if the session is being recorded.
+// The .record metaquery is not recorded
+func (c *InteractiveClient) recordMetaquery(query string, startTime time.Time, previousSettings transcript.Settings, err error) {
+	if c.recorder == nil || strings.HasPrefix(query, constants.CmdRecord) {
+		return
+	}
+	entry := &transcript.Entry{
+		Type:       transcript.EntryMetaquery,
+		Time:       startTime,
+		Input:      query,
+		DurationMs: time.Since(startTime).Milliseconds(),
+		Settings:   c.sessionSettings().Changed(previousSettings),
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	c.writeTranscriptEntry(entry)
+}
+
+// writeTranscriptEntry writes an entry to the transcript - if the write fails, the recording is stopped
+func (c *InteractiveClient) writeTranscriptEntry(entry *transcript.Entry) {
+	if err := c.recorder.Record(entry); err != nil {
+		path := c.recorder.Path()
+		_ = c.record("")
+		error_helpers.ShowWarning(fmt.Sprintf("stopped recording to %s: %s", path, err.Error()))
+	}
+}
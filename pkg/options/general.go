@@ -7,6 +7,7 @@ import (
 	"github.com/turbot/go-kit/helpers"
 	"github.com/turbot/pipe-fittings/v2/constants"
 	"github.com/turbot/pipe-fittings/v2/options"
+	localconstants "github.com/turbot/steampipe/v2/pkg/constants"
 )
 
 type General struct {
@@ -15,6 +16,11 @@ type General struct {
 	Telemetry   *string `hcl:"telemetry" cty:"telemetry"`
 	LogLevel    *string `hcl:"log_level" cty:"log_level"`
 	MemoryMaxMb *int    `hcl:"memory_max_mb" cty:"memory_max_mb"`
+	KeyBindMode *string `hcl:"key_bind_mode" cty:"key_bind_mode"`
+	// map of interactive prompt action to key
+	KeyBindings map[string]string `hcl:"key_bindings,optional" cty:"key_bindings"`
+	// show interactive query results which do not fit on the screen in a full-screen viewer
+	ResultViewer *bool `hcl:"result_viewer" cty:"result_viewer"`
 }
 
 // TODO KAI what is the difference between merge and SetBaseProperties
@@ -38,6 +44,15 @@ func (g *General) SetBaseProperties(otherOptions options.Options) {
 		if g.MemoryMaxMb == nil && o.MemoryMaxMb != nil {
 			g.MemoryMaxMb = o.MemoryMaxMb
 		}
+		if g.KeyBindMode == nil && o.KeyBindMode != nil {
+			g.KeyBindMode = o.KeyBindMode
+		}
+		if g.KeyBindings == nil && o.KeyBindings != nil {
+			g.KeyBindings = o.KeyBindings
+		}
+		if g.ResultViewer == nil && o.ResultViewer != nil {
+			g.ResultViewer = o.ResultViewer
+		}
 
 	}
 }
@@ -61,6 +76,15 @@ func (g *General) ConfigMap() map[string]interface{} {
 	if g.MemoryMaxMb != nil {
 		res[constants.ArgMemoryMaxMb] = g.MemoryMaxMb
 	}
+	if g.KeyBindMode != nil {
+		res[localconstants.ArgKeyBindMode] = g.KeyBindMode
+	}
+	if g.KeyBindings != nil {
+		res[localconstants.ArgKeyBindings] = g.KeyBindings
+	}
+	if g.ResultViewer != nil {
+		res[localconstants.ArgResultViewer] = g.ResultViewer
+	}
 
 	return res
 }
@@ -111,5 +135,23 @@ func (g *General) String() string {
 	} else {
 		str = append(str, fmt.Sprintf("  MemoryMaxMb: %d", *g.MemoryMaxMb))
 	}
+
+	if g.KeyBindMode == nil {
+		str = append(str, "  KeyBindMode: nil")
+	} else {
+		str = append(str, fmt.Sprintf("  KeyBindMode: %s", *g.KeyBindMode))
+	}
+
+	if g.KeyBindings == nil {
+		str = append(str, "  KeyBindings: nil")
+	} else {
+		str = append(str, fmt.Sprintf("  KeyBindings: %v", g.KeyBindings))
+	}
+
+	if g.ResultViewer == nil {
+		str = append(str, "  ResultViewer: nil")
+	} else {
+		str = append(str, fmt.Sprintf("  ResultViewer: %t", *g.ResultViewer))
+	}
 	return strings.Join(str, "\n")
 }
@@ -16,6 +16,7 @@ import (
 	"github.com/turbot/steampipe/v2/pkg/error_helpers"
 	"github.com/turbot/steampipe/v2/pkg/export"
 	"github.com/turbot/steampipe/v2/pkg/initialisation"
+	"github.com/turbot/steampipe/v2/pkg/query/savedquery"
 	"github.com/turbot/steampipe/v2/pkg/statushooks"
 )
 
@@ -47,7 +48,14 @@ func NewInitData(ctx context.Context, args []string) *InitData {
 }
 
 func queryExporters() []export.Exporter {
-	return []export.Exporter{&export.SnapshotExporter{}}
+	return []export.Exporter{
+		&export.SnapshotExporter{},
+		&export.CsvExporter{},
+		&export.JsonExporter{},
+		&export.JsonlExporter{},
+		&export.ParquetExporter{},
+		&export.SqliteExporter{},
+	}
 }
 
 func (i *InitData) Cancel() {
@@ -84,10 +92,11 @@ func (i *InitData) init(ctx context.Context, args []string) {
 		i.cancelInitialisation = nil
 	}()
 
+	// register the exporters - these are also used by the .export metaquery in interactive mode
+	i.RegisterExporters(queryExporters()...)
+
 	// validate export args
 	if len(viper.GetStringSlice(pconstants.ArgExport)) > 0 {
-		i.RegisterExporters(queryExporters()...)
-
 		// validate required export formats
 		if err := i.ExportManager.ValidateExportFormat(viper.GetStringSlice(pconstants.ArgExport)); err != nil {
 			i.Result.Error = err
@@ -101,7 +110,12 @@ func (i *InitData) init(ctx context.Context, args []string) {
 	statushooks.SetStatus(ctx, "Resolving arguments")
 
 	// convert the query or sql file arg into an array of executable queries - check names queries in the current workspace
-	resolvedQueries, err := getQueriesFromArgs(args)
+	queryArgs, err := ParseQueryArgs(viper.GetStringSlice(pconstants.ArgArg))
+	if err != nil {
+		i.Result.Error = err
+		return
+	}
+	resolvedQueries, err := getQueriesFromArgs(args, queryArgs)
 	if err != nil {
 		i.Result.Error = err
 		return
@@ -129,56 +143,126 @@ func (i *InitData) init(ctx context.Context, args []string) {
 	)
 }
 
-// getQueriesFromArgs retrieves queries from args
+// getQueriesFromArgs retrieves queries from args, setting the query args of each resolved query
 //
 // For each arg check if it is a named query or a file, before falling back to treating it as sql
-func getQueriesFromArgs(args []string) ([]*modconfig.ResolvedQuery, error) {
-
-	var queries = make([]*modconfig.ResolvedQuery, len(args))
-	for idx, arg := range args {
-		resolvedQuery, err := ResolveQueryAndArgsFromSQLString(arg)
+// SQL files containing multiple statements are split into a query per statement
+func getQueriesFromArgs(args []string, queryArgs []any) ([]*modconfig.ResolvedQuery, error) {
+	var queries []*modconfig.ResolvedQuery
+	for _, arg := range args {
+		resolvedQuery, isFile, err := resolveQueryFromSQLString(arg)
 		if err != nil {
 			return nil, err
 		}
-		if len(resolvedQuery.ExecuteSQL) > 0 {
-			// default name to the query text
-			resolvedQuery.Name = resolvedQuery.ExecuteSQL
+		// is this the name of a saved query
+		// (saved queries are only resolved from the command args - in an interactive session they are run with .run)
+		if !isFile {
+			savedQuery, err := getSavedQuery(arg)
+			if err != nil {
+				return nil, err
+			}
+			if savedQuery != nil {
+				// saved queries may contain multiple statements - treat them like a file so they are split
+				resolvedQuery, isFile = savedQuery, true
+			}
+		}
+		if len(resolvedQuery.ExecuteSQL) == 0 {
+			continue
+		}
 
-			queries[idx] = resolvedQuery
+		if !isFile {
+			queries = append(queries, &modconfig.ResolvedQuery{
+				// default name to the query text
+				Name:       resolvedQuery.ExecuteSQL,
+				RawSQL:     resolvedQuery.ExecuteSQL,
+				ExecuteSQL: resolvedQuery.ExecuteSQL,
+				Args:       queryArgs,
+			})
+			continue
+		}
+		statementQueries := StatementQueries(resolvedQuery.ExecuteSQL, queryArgs)
+		// name the queries of a file or saved query after it - if it has more than one statement, number them
+		if resolvedQuery.Name != "" {
+			for i, q := range statementQueries {
+				q.Name = resolvedQuery.Name
+				if len(statementQueries) > 1 {
+					q.Name = fmt.Sprintf("%s_%d", resolvedQuery.Name, i+1)
+				}
+			}
 		}
+		queries = append(queries, statementQueries...)
 	}
 	return queries, nil
 }
 
+// StatementQueries splits SQL which may contain multiple statements (e.g. the contents of a file) into a query per statement
+func StatementQueries(sql string, queryArgs []any) []*modconfig.ResolvedQuery {
+	statements := SplitStatements(sql)
+	queries := make([]*modconfig.ResolvedQuery, len(statements))
+	for i, statement := range statements {
+		q := &modconfig.ResolvedQuery{
+			// default name to the query text
+			Name:       statement,
+			RawSQL:     statement,
+			ExecuteSQL: statement,
+			Args:       queryArgs,
+		}
+		// if the sql has been split, only pass each statement the args it uses
+		if len(statements) > 1 {
+			q.Args = statementQueryArgs(statement, queryArgs)
+		}
+		queries[i] = q
+	}
+	return queries
+}
+
 // ResolveQueryAndArgsFromSQLString attempts to resolve 'arg' to a query and query args
 func ResolveQueryAndArgsFromSQLString(sqlString string) (*modconfig.ResolvedQuery, error) {
+	resolvedQuery, _, err := resolveQueryFromSQLString(sqlString)
+	return resolvedQuery, err
+}
+
+// resolveQueryFromSQLString attempts to resolve 'arg' to a query, also returning whether the query was read from a file
+func resolveQueryFromSQLString(sqlString string) (*modconfig.ResolvedQuery, bool, error) {
 	var err error
 
-	// 2) is this a file
+	// 1) is this a file
 	// get absolute filename
 	filePath, err := filepath.Abs(sqlString)
 	if err != nil {
-		return nil, fmt.Errorf("%s", err.Error())
+		return nil, false, fmt.Errorf("%s", err.Error())
 	}
 	fileQuery, fileExists, err := getQueryFromFile(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("%s", err.Error())
+		return nil, false, fmt.Errorf("%s", err.Error())
 	}
 	if fileExists {
 		if fileQuery.ExecuteSQL == "" {
 			error_helpers.ShowWarning(fmt.Sprintf("file '%s' does not contain any data", filePath))
 			// (just return the empty query - it will be filtered above)
 		}
-		return fileQuery, nil
+		return fileQuery, true, nil
 	}
 	// the argument cannot be resolved as an existing file
 	// if it has a sql suffix (i.e we believe the user meant to specify a file) return a file not found error
 	if strings.HasSuffix(strings.ToLower(sqlString), ".sql") {
-		return nil, fmt.Errorf("file '%s' does not exist", filePath)
+		return nil, false, fmt.Errorf("file '%s' does not exist", filePath)
 	}
 
 	// 2) just use the query string as is and assume it is valid SQL
-	return &modconfig.ResolvedQuery{RawSQL: sqlString, ExecuteSQL: sqlString}, nil
+	return &modconfig.ResolvedQuery{RawSQL: sqlString, ExecuteSQL: sqlString}, false, nil
+}
+
+// getSavedQuery returns the saved query with the given name, or nil if there is no such query
+func getSavedQuery(name string) (*modconfig.ResolvedQuery, error) {
+	if !savedquery.IsValidName(name) {
+		return nil, nil
+	}
+	savedQuery, err := savedquery.Load(name)
+	if err != nil || savedQuery == nil {
+		return nil, err
+	}
+	return &modconfig.ResolvedQuery{Name: savedQuery.Name, RawSQL: savedQuery.SQL, ExecuteSQL: savedQuery.SQL}, nil
 }
 
 // try to treat the input string as a file name and if it exists, return its contents
@@ -203,6 +287,8 @@ func getQueryFromFile(input string) (*modconfig.ResolvedQuery, bool, error) {
 	}
 
 	res := &modconfig.ResolvedQuery{
+		// name the query after the file
+		Name:       strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
 		RawSQL:     string(fileBytes),
 		ExecuteSQL: string(fileBytes),
 	}
@@ -0,0 +1,77 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// namedQueryArgRegex matches a named query arg, i.e. name=value, where name is a valid placeholder name
+var namedQueryArgRegex = regexp.MustCompile(`^([a-zA-Z_][a-zA-Z0-9_]*)=(.*)$`)
+
+// ParseQueryArgs converts the values of the --arg flag into query args
+//
+// Args are either positional (--arg value), which are bound to the $1, $2... placeholders in the order given,
+// or named (--arg name=value), which are bound to @name placeholders.
+// Named and positional args cannot be combined.
+//
+// All values are passed as text - Postgres converts them to the type of the placeholder
+func ParseQueryArgs(args []string) ([]any, error) {
+	if len(args) == 0 {
+		return nil, nil
+	}
+
+	var positional []any
+	named := pgx.StrictNamedArgs{}
+	for _, arg := range args {
+		if match := namedQueryArgRegex.FindStringSubmatch(arg); match != nil {
+			name, value := match[1], match[2]
+			if _, ok := named[name]; ok {
+				return nil, fmt.Errorf("query arg '%s' is specified more than once", name)
+			}
+			named[name] = value
+			continue
+		}
+		positional = append(positional, arg)
+	}
+
+	if len(named) > 0 && len(positional) > 0 {
+		return nil, fmt.Errorf("named and positional query args cannot be combined")
+	}
+	if len(named) > 0 {
+		// the named args are passed as the only query arg - the placeholders are rewritten by pgx
+		return []any{named}, nil
+	}
+	return positional, nil
+}
+
+// statementQueryArgs returns the query args used by a single statement of a SQL file containing multiple statements
+// - positional args are passed up to the highest placeholder used by the statement
+// - named args are passed if the statement uses them
+func statementQueryArgs(sql string, queryArgs []any) []any {
+	if len(queryArgs) == 0 {
+		return nil
+	}
+	named, maxPositional := statementPlaceholders(sql)
+
+	if namedArgs, ok := queryArgs[0].(pgx.StrictNamedArgs); ok {
+		if len(named) == 0 {
+			return nil
+		}
+		res := pgx.StrictNamedArgs{}
+		for name := range named {
+			// if the arg is not specified, pgx will report an error
+			if value, ok := namedArgs[name]; ok {
+				res[name] = value
+			}
+		}
+		return []any{res}
+	}
+
+	// if the statement uses more args than are specified, pass them all and let postgres report the error
+	if maxPositional > len(queryArgs) {
+		return queryArgs
+	}
+	return queryArgs[:maxPositional]
+}
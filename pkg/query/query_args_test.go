@@ -0,0 +1,134 @@
+package query
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/turbot/pipe-fittings/v2/app_specific"
+	"github.com/turbot/steampipe/v2/pkg/query/savedquery"
+)
+
+func TestParseQueryArgs(t *testing.T) {
+	tests := map[string]struct {
+		args     []string
+		expected []any
+		wantErr  string
+	}{
+		"no args": {
+			args:     nil,
+			expected: nil,
+		},
+		"positional": {
+			args:     []string{"us-east-1", "2"},
+			expected: []any{"us-east-1", "2"},
+		},
+		"positional with commas and spaces": {
+			args:     []string{"a, b", " c "},
+			expected: []any{"a, b", " c "},
+		},
+		"named": {
+			args:     []string{"region=us-east-1", "filter=a=b", "empty="},
+			expected: []any{pgx.StrictNamedArgs{"region": "us-east-1", "filter": "a=b", "empty": ""}},
+		},
+		"value which is not a valid name is positional": {
+			args:     []string{"1=1"},
+			expected: []any{"1=1"},
+		},
+		"mixed": {
+			args:    []string{"region=us-east-1", "2"},
+			wantErr: "named and positional query args cannot be combined",
+		},
+		"duplicate name": {
+			args:    []string{"region=us-east-1", "region=eu-west-1"},
+			wantErr: "query arg 'region' is specified more than once",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			res, err := ParseQueryArgs(tc.args)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, res)
+		})
+	}
+}
+
+func TestGetQueriesFromArgs_SetsQueryArgs(t *testing.T) {
+	sqlFile := filepath.Join(t.TempDir(), "query.sql")
+	require.NoError(t, os.WriteFile(sqlFile, []byte("select * from aws_ec2_instance where region = @region"), 0600))
+
+	queryArgs := []any{pgx.StrictNamedArgs{"region": "us-east-1"}}
+	queries, err := getQueriesFromArgs([]string{sqlFile, "select $1"}, queryArgs)
+	require.NoError(t, err)
+	require.Len(t, queries, 2)
+	for _, q := range queries {
+		assert.Equal(t, queryArgs, q.Args)
+	}
+}
+
+func TestStatementQueryArgs(t *testing.T) {
+	positional := []any{"a", "b", "c"}
+	named := []any{pgx.StrictNamedArgs{"region": "us-east-1", "limit": "10"}}
+
+	assert.Nil(t, statementQueryArgs("select 1", nil))
+	assert.Equal(t, []any{}, statementQueryArgs("select 1", positional))
+	assert.Equal(t, []any{"a", "b"}, statementQueryArgs("select $2, $1", positional))
+	assert.Equal(t, positional, statementQueryArgs("select $4", positional))
+	assert.Nil(t, statementQueryArgs("select 1", named))
+	assert.Equal(t, []any{pgx.StrictNamedArgs{"region": "us-east-1"}}, statementQueryArgs("select @region", named))
+	assert.Equal(t, []any{pgx.StrictNamedArgs{}}, statementQueryArgs("select @missing", named))
+}
+
+func TestGetQueriesFromArgs_SplitsFiles(t *testing.T) {
+	sqlFile := filepath.Join(t.TempDir(), "queries.sql")
+	require.NoError(t, os.WriteFile(sqlFile, []byte("select $1;\n-- second\nselect 2;\n"), 0600))
+
+	queries, err := getQueriesFromArgs([]string{sqlFile, "select 3; select 4"}, []any{"x"})
+	require.NoError(t, err)
+	require.Len(t, queries, 3)
+
+	assert.Equal(t, "select $1", queries[0].ExecuteSQL)
+	assert.Equal(t, []any{"x"}, queries[0].Args)
+	assert.Equal(t, "-- second\nselect 2", queries[1].ExecuteSQL)
+	assert.Equal(t, []any{}, queries[1].Args)
+	// sql passed directly is not split
+	assert.Equal(t, "select 3; select 4", queries[2].ExecuteSQL)
+	assert.Equal(t, []any{"x"}, queries[2].Args)
+
+	// the statements of the file are named after it - sql passed directly is not named
+	assert.Equal(t, "queries_1", queries[0].Name)
+	assert.Equal(t, "queries_2", queries[1].Name)
+	assert.Equal(t, "select 3; select 4", queries[2].Name)
+}
+
+func TestGetQueriesFromArgs_SavedQuery(t *testing.T) {
+	previous := app_specific.InstallDir
+	app_specific.InstallDir = filepath.Join(t.TempDir(), ".steampipe")
+	t.Cleanup(func() { app_specific.InstallDir = previous })
+
+	_, err := savedquery.Save("my_query", "select $1;\nselect 2;")
+	require.NoError(t, err)
+
+	queries, err := getQueriesFromArgs([]string{"my_query"}, []any{"x"})
+	require.NoError(t, err)
+	require.Len(t, queries, 2)
+	assert.Equal(t, "select $1", queries[0].ExecuteSQL)
+	assert.Equal(t, []any{"x"}, queries[0].Args)
+	assert.Equal(t, "select 2", queries[1].ExecuteSQL)
+	// the statements are named after the saved query
+	assert.Equal(t, "my_query_1", queries[0].Name)
+	assert.Equal(t, "my_query_2", queries[1].Name)
+
+	// saved queries are not resolved in an interactive session - they are run with .run
+	resolved, err := ResolveQueryAndArgsFromSQLString("my_query")
+	require.NoError(t, err)
+	assert.Equal(t, "my_query", resolved.ExecuteSQL)
+}
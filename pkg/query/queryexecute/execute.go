@@ -20,9 +20,11 @@ import (
 	"github.com/turbot/pipe-fittings/v2/utils"
 	"github.com/turbot/steampipe/v2/pkg/cmdconfig"
 	"github.com/turbot/steampipe/v2/pkg/connection_sync"
+	localconstants "github.com/turbot/steampipe/v2/pkg/constants"
 	"github.com/turbot/steampipe/v2/pkg/db/db_common"
 	"github.com/turbot/steampipe/v2/pkg/display"
 	"github.com/turbot/steampipe/v2/pkg/error_helpers"
+	"github.com/turbot/steampipe/v2/pkg/export"
 	"github.com/turbot/steampipe/v2/pkg/interactive"
 	"github.com/turbot/steampipe/v2/pkg/query"
 	"github.com/turbot/steampipe/v2/pkg/query/queryresult"
@@ -40,35 +42,64 @@ func RunInteractiveSession(ctx context.Context, initData *query.InitData) error
 	for r := range result.Streamer.Results {
 		// wrap the result from pipe-fittings with our wrapper that has idempotent Close
 		wrapped := queryresult.WrapResult(r)
-		rowCount, _ := querydisplay.ShowOutput(ctx, r)
+		// if the output has been redirected to a file, write the result there
+		var rowCount int
+		show := func() { rowCount, _ = querydisplay.ShowOutput(ctx, r) }
+		if display.UseResultViewer() {
+			show = func() { rowCount = display.ViewResult(ctx, r) }
+		}
+		if err := display.WithOutputRedirect(show); err != nil {
+			error_helpers.ShowError(ctx, err)
+		}
 		// show timing
 		display.DisplayTiming(wrapped, rowCount)
 		// signal to the resultStreamer that we are done with this chunk of the stream
 		result.Streamer.AllResultsRead()
 	}
+	// close the output file if the output is still redirected
+	if err := display.StopOutputRedirect(); err != nil {
+		error_helpers.ShowError(ctx, err)
+	}
 	return result.PromptErr
 }
 
-func RunBatchSession(ctx context.Context, initData *query.InitData) (int, error) {
+// RunBatchSession executes the queries, returning the number of query failures and
+// the number of queries which returned an unexpected number of rows
+func RunBatchSession(ctx context.Context, initData *query.InitData) (int, int, error) {
 	if initData == nil {
-		return 0, fmt.Errorf("initData cannot be nil")
+		return 0, 0, fmt.Errorf("initData cannot be nil")
 	}
 
 	// start cancel handler to intercept interrupts and cancel the context
 	// NOTE: use the initData Cancel function to ensure any initialisation is cancelled if needed
 	contexthelpers.StartCancelHandler(initData.Cancel)
 
-	// wait for init, respecting context cancellation
+	if err := waitForInitialisation(ctx, initData); err != nil {
+		return 0, 0, err
+	}
+
+	failures, assertionFailures := 0, 0
+	if len(initData.Queries) > 0 {
+		// if we have resolved any queries, run them
+		failures, assertionFailures = executeQueries(ctx, initData)
+	}
+	// return the number of query failures (including rows that returned errors) and the number of row count assertion failures
+	return failures, assertionFailures, nil
+}
+
+// waitForInitialisation waits for the initialisation to complete, respecting context cancellation,
+// and for the schemas of any custom search path to be loaded
+func waitForInitialisation(ctx context.Context, initData *query.InitData) error {
 	select {
 	case <-initData.Loaded:
 		// initialization complete, continue
 	case <-ctx.Done():
 		// context cancelled before initialization completed
-		return 0, ctx.Err()
+		return ctx.Err()
 	}
 
 	if err := initData.Result.Error; err != nil {
-		return 0, err
+		return err
 	}
 
 	// display any initialisation messages/warnings
@@ -76,51 +107,68 @@ func RunBatchSession(ctx context.Context, initData *query.InitData) (int, error)
 
 	// validate that Client is not nil
 	if initData.Client == nil {
-		return 0, fmt.Errorf("client is required but not initialized")
+		return fmt.Errorf("client is required but not initialized")
 	}
 
 	// if there is a custom search path, wait until the first connection of each plugin has loaded
 	if customSearchPath := initData.Client.GetCustomSearchPath(); customSearchPath != nil {
 		if err := connection_sync.WaitForSearchPathSchemas(ctx, initData.Client, customSearchPath); err != nil {
-			return 0, err
+			return err
 		}
 	}
-
-	failures := 0
-	if len(initData.Queries) > 0 {
-		// if we have resolved any queries, run them
-		failures = executeQueries(ctx, initData)
-	}
-	// return the number of query failures and the number of rows that returned errors
-	return failures, nil
+	return nil
 }
 
-func executeQueries(ctx context.Context, initData *query.InitData) int {
+// executeQueries executes the queries, returning failures - the number of queries that failed plus the number of
+// rows that returned errors - and assertionFailures - the number of queries that returned an unexpected number of rows
+func executeQueries(ctx context.Context, initData *query.InitData) (failures, assertionFailures int) {
 	utils.LogTime("queryexecute.executeQueries start")
 	defer utils.LogTime("queryexecute.executeQueries end")
 
 	// Check if Client is nil - this can happen if initialization failed
 	if initData.Client == nil {
 		error_helpers.ShowWarning("cannot execute queries: database client is not initialized")
-		return len(initData.Queries)
+		return len(initData.Queries), 0
 	}
 
-	// failures return the number of queries that failed and also the number of rows that
-	// returned errors
-	failures := 0
-	t := time.Now()
+	// the queries are a single export run, e.g. the results of all queries are exported to the same sqlite file
+	if initData.ExportManager != nil {
+		initData.ExportManager.StartRun()
+	}
+
+	// the row count each query is expected to return, if set
+	expected, err := expectedRowCount()
+	if err != nil {
+		error_helpers.ShowWarning(fmt.Sprintf("cannot execute queries: %v", err))
+		return len(initData.Queries), 0
+	}
 
-	var err error
+	stopOnError := cmdconfig.Viper().GetString(localconstants.ArgOnError) == localconstants.OnErrorStop
 
 	for i, q := range initData.Queries {
-		// if executeQuery fails it returns err, else it returns the number of rows that returned errors while execution
-		if err, failures = executeQuery(ctx, initData, q); err != nil {
+		t := time.Now()
+		// if executeQuery fails it returns err, else it returns the number of rows (which is checked against the
+		// expected row count) and the number of rows that returned errors (which are counted as failures)
+		rowCount, rowErrors, err := executeQuery(ctx, initData, q)
+		failures += rowErrors
+		// only check the row count of queries which succeeded
+		if err == nil && rowErrors == 0 && expected != nil && !expected.Contains(rowCount) {
+			assertionFailures++
+			error_helpers.ShowWarning(fmt.Sprintf("query %d of %d %s", i+1, len(initData.Queries), rowCountAssertionMessage(*expected, rowCount)))
+		}
+		if err != nil {
 			failures++
 			error_helpers.ShowWarning(fmt.Sprintf("query %d of %d failed: %v", i+1, len(initData.Queries), error_helpers.DecodePgError(err)))
 			// if timing flag is enabled, show the time taken for the query to fail
 			if cmdconfig.Viper().GetString(pconstants.ArgTiming) != pconstants.ArgOff {
 				querydisplay.DisplayErrorTiming(t)
 			}
+			if stopOnError {
+				if remaining := len(initData.Queries) - i - 1; remaining > 0 {
+					error_helpers.ShowWarning(fmt.Sprintf("skipping %d remaining %s", remaining, utils.Pluralize("query", remaining)))
+				}
+				break
+			}
 		}
 		// TODO move into display layer
 		// Only show the blank line between queries, not after the last one
@@ -129,10 +177,12 @@ func executeQueries(ctx context.Context, initData *query.InitData) int {
 		}
 	}
 
-	return failures
+	// failures are counted separately from row count assertion failures, so the exit code can distinguish them
+	return failures, assertionFailures
 }
 
-func executeQuery(ctx context.Context, initData *query.InitData, resolvedQuery *modconfig.ResolvedQuery) (error, int) {
+// executeQuery executes a query and displays the result, returning the number of rows and the number of rows that returned errors
+func executeQuery(ctx context.Context, initData *query.InitData, resolvedQuery *modconfig.ResolvedQuery) (int, int, error) {
 	utils.LogTime("query.execute.executeQuery start")
 	defer utils.LogTime("query.execute.executeQuery end")
 
@@ -141,27 +191,46 @@ func executeQuery(ctx context.Context, initData *query.InitData, resolvedQuery *
 	// the db executor sends result data over resultsStreamer
 	resultsStreamer, err := db_common.ExecuteQuery(ctx, initData.Client, resolvedQuery.ExecuteSQL, resolvedQuery.Args...)
 	if err != nil {
-		return err, 0
+		return 0, 0, err
 	}
 
+	rowCount := 0
 	rowErrors := 0 // get the number of rows that returned an error
 	// print the data as it comes
 	for r := range resultsStreamer.Results {
 		// wrap the result from pipe-fittings with our wrapper that has idempotent Close
 		wrapped := queryresult.WrapResult(r)
 
+		// if the only reason we need a snapshot is to export the result, and all the export formats support streaming,
+		// stream the rows to the exporters while displaying them, rather than holding the full result in memory
+		if canStreamExport(initData) {
+			rows, rowErrs, err := displayAndStreamExport(ctx, initData, resolvedQuery, r)
+			if err != nil {
+				resultsStreamer.AllResultsRead()
+				return 0, 0, err
+			}
+			// show timing
+			display.DisplayTiming(wrapped, rows)
+
+			// signal to the resultStreamer that we are done with this result
+			resultsStreamer.AllResultsRead()
+			rowCount += rows
+			rowErrors = rowErrs
+			continue
+		}
+
 		// if the output format is snapshot or export is set or share/snapshot args are set, we need to generate a snapshot
 		if needSnapshot() {
 			snap, err = snapshot.QueryResultToSnapshot(ctx, r, resolvedQuery, initData.Client.GetRequiredSessionSearchPath(), initData.StartTime)
 			if err != nil {
-				return err, 0
+				return 0, 0, err
 			}
 
 			// re-generate the query result from the snapshot. since the row stream in the actual queryresult has been exhausted(while generating the snapshot),
 			// we need to re-generate it for other output formats
 			newQueryResult, err := snapshot.SnapshotToQueryResult[pqueryresult.TimingContainer](snap, initData.StartTime)
 			if err != nil {
-				return err, 0
+				return 0, 0, err
 			}
 
 			// if the output format is snapshot we don't call the querydisplay code in pipe-fittings, instead we
@@ -176,7 +245,7 @@ func executeQuery(ctx context.Context, initData *query.InitData, resolvedQuery *
 				if err := encoder.Encode(snap); err != nil {
 					//nolint:forbidigo // acceptable
 					fmt.Print("Error displaying result as snapshot", err)
-					return err, 0
+					return 0, 0, err
 				}
 			}
 
@@ -185,41 +254,162 @@ func executeQuery(ctx context.Context, initData *query.InitData, resolvedQuery *
 				exportArgs := viper.GetStringSlice(pconstants.ArgExport)
 				exportMsg, err := initData.ExportManager.DoExport(ctx, "query", snap, exportArgs)
 				if err != nil {
-					return err, 0
-				}
-				// print the location where the file is exported
-				if len(exportMsg) > 0 && viper.GetBool(pconstants.ArgProgress) {
-					fmt.Printf("\n")                           //nolint:forbidigo // intentional use of fmt
-					fmt.Println(strings.Join(exportMsg, "\n")) //nolint:forbidigo // intentional use of fmt
-					fmt.Printf("\n")                           //nolint:forbidigo // intentional use of fmt
+					return 0, 0, err
 				}
+				showExportMessages(exportMsg)
 			}
 
 			// if we need to publish the snapshot, we publish it directly from here
 			if err := publishSnapshotIfNeeded(ctx, snap); err != nil {
-				return err, 0
+				return 0, 0, err
 			}
 
 			// if other output formats are also needed, we call the querydisplay using the re-generated query result
-			rowCount, _ := querydisplay.ShowOutput(ctx, newQueryResult)
+			rows, _ := showOutput(ctx, newQueryResult, r.Timing)
 			// show timing
-			display.DisplayTiming(wrapped, rowCount)
+			display.DisplayTiming(wrapped, rows)
 
 			// signal to the resultStreamer that we are done with this result
 			resultsStreamer.AllResultsRead()
-			return nil, rowErrors
+
+			// the display does not read the rows for some output formats, so count the rows of the snapshot
+			if panel, err := snapshot.QueryTablePanel(snap); err == nil {
+				rowCount += len(panel.Data.Rows)
+			}
+			return rowCount, rowErrors, nil
 		}
 
 		// for other output formats, we call the querydisplay code in pipe-fittings
-		rowCount, rowErrs := querydisplay.ShowOutput(ctx, r)
+		rows, rowErrs := showOutput(ctx, r, r.Timing)
+		// the display does not read any rows if there is no output, so read them here
+		if viper.GetString(pconstants.ArgOutput) == pconstants.OutputFormatNone {
+			rows, rowErrs = countRows(r)
+		}
 		// show timing
-		display.DisplayTiming(wrapped, rowCount)
+		display.DisplayTiming(wrapped, rows)
 
 		// signal to the resultStreamer that we are done with this result
 		resultsStreamer.AllResultsRead()
+		rowCount += rows
 		rowErrors = rowErrs
 	}
-	return nil, rowErrors
+	return rowCount, rowErrors, nil
+}
+
+// showOutput displays a query result in the current output format, returning the row count and number of row errors
+// timing is the timing stream of the query, as the result may have been regenerated from a snapshot
+func showOutput[T pqueryresult.TimingContainer](ctx context.Context, result *pqueryresult.Result[T], timing queryresult.TimingResultStream) (int, int) {
+	if viper.GetString(pconstants.ArgOutput) == localconstants.OutputFormatTemplate {
+		return display.ShowTemplateOutput(ctx, result, func() *queryresult.TimingResult {
+			t := <-timing.Stream
+			// put the timing back, so it can also be displayed
+			timing.Stream <- t
+			return t
+		})
+	}
+	return querydisplay.ShowOutput(ctx, result)
+}
+
+// countRows reads all rows of a result, returning the number of rows and the number of rows that returned errors
+func countRows(r *pqueryresult.Result[queryresult.TimingResultStream]) (rowCount, rowErrors int) {
+	for row := range r.RowChan {
+		if row.Error != nil {
+			rowErrors++
+			continue
+		}
+		rowCount++
+	}
+	return rowCount, rowErrors
+}
+
+// displayAndStreamExport displays the query result while passing each row to the exporters as it is received
+// returns the number of rows received before any row error and the number of row errors from the display
+func displayAndStreamExport(ctx context.Context, initData *query.InitData, resolvedQuery *modconfig.ResolvedQuery, r *pqueryresult.Result[queryresult.TimingResultStream]) (int, int, error) {
+	// the display reads from its own result, which shares the timing stream with the source result
+	displayResult := pqueryresult.NewResult(r.Cols, r.Timing)
+	// closed when the display has finished reading rows
+	// (the display stops reading after a row error and does not read at all for some output formats)
+	displayDone := make(chan struct{})
+
+	exportRows := make(chan map[string]any)
+	stream := &export.RowStream{
+		Columns: snapshot.ColumnDefs(r.Cols),
+		SQL:     resolvedQuery.RawSQL,
+		Name:    snapshot.QueryName(resolvedQuery),
+		Rows:    exportRows,
+	}
+
+	// the number of rows received before any row error - this is only read once the export is complete,
+	// which is after all rows have been read
+	rowCount := 0
+
+	// read rows from the source result, sending each to the exporters and the display
+	go func() {
+		defer close(exportRows)
+		defer displayResult.Close()
+
+		failed := false
+		for row := range r.RowChan {
+			// after an error, just drain the source result
+			// (this matches the snapshot export, which includes the rows received before the error)
+			if failed {
+				continue
+			}
+			if row.Error != nil {
+				failed = true
+			} else {
+				rowCount++
+				exportRows <- snapshot.RowData(row.Data, r.Cols)
+			}
+			select {
+			case displayResult.RowChan <- row:
+			case <-displayDone:
+			}
+		}
+	}()
+
+	var exportMsg []string
+	var exportErr error
+	exportComplete := make(chan struct{})
+	go func() {
+		defer close(exportComplete)
+		exportMsg, exportErr = initData.ExportManager.DoStreamingExport(ctx, "query", stream, viper.GetStringSlice(pconstants.ArgExport))
+	}()
+
+	_, rowErrors := showOutput(ctx, displayResult, r.Timing)
+	close(displayDone)
+	<-exportComplete
+
+	if exportErr != nil {
+		return rowCount, rowErrors, exportErr
+	}
+	showExportMessages(exportMsg)
+	return rowCount, rowErrors, nil
+}
+
+// showExportMessages prints the location of the exported files
+func showExportMessages(exportMsg []string) {
+	if len(exportMsg) > 0 && viper.GetBool(pconstants.ArgProgress) {
+		fmt.Printf("\n")                           //nolint:forbidigo // intentional use of fmt
+		fmt.Println(strings.Join(exportMsg, "\n")) //nolint:forbidigo // intentional use of fmt
+		fmt.Printf("\n")                           //nolint:forbidigo // intentional use of fmt
+	}
+}
+
+// canStreamExport returns true if a snapshot is only needed to export the result and
+// all the export formats support streaming
+func canStreamExport(initData *query.InitData) bool {
+	outputFormat := viper.GetString(pconstants.ArgOutput)
+	if outputFormat == pconstants.OutputFormatSnapshot || outputFormat == pconstants.OutputFormatSteampipeSnapshotShort {
+		return false
+	}
+	if viper.GetBool(pconstants.ArgShare) || viper.GetBool(pconstants.ArgSnapshot) {
+		return false
+	}
+	if !viper.IsSet(pconstants.ArgExport) || initData.ExportManager == nil {
+		return false
+	}
+	return initData.ExportManager.CanStreamExport(viper.GetStringSlice(pconstants.ArgExport))
 }
 
 func needSnapshot() bool {
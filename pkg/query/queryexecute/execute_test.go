@@ -2,14 +2,18 @@ package queryexecute
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
+	pconstants "github.com/turbot/pipe-fittings/v2/constants"
 	"github.com/turbot/pipe-fittings/v2/modconfig"
 	pqueryresult "github.com/turbot/pipe-fittings/v2/queryresult"
+	"github.com/turbot/steampipe/v2/pkg/constants"
 	"github.com/turbot/steampipe/v2/pkg/db/db_common"
 	"github.com/turbot/steampipe/v2/pkg/export"
 	"github.com/turbot/steampipe/v2/pkg/initialisation"
@@ -53,7 +57,7 @@ func TestRunBatchSession_NilInitData(t *testing.T) {
 	ctx := context.Background()
 
 	// This should not panic - function should validate initData is non-nil
-	failures, err := RunBatchSession(ctx, nil)
+	failures, _, err := RunBatchSession(ctx, nil)
 
 	if err == nil {
 		t.Fatal("Expected error when initData is nil, got nil")
@@ -74,7 +78,7 @@ func TestRunBatchSession_EmptyQueries(t *testing.T) {
 	closeInitDataLoaded(initData)
 
 	// ACT: Run batch session
-	failures, err := RunBatchSession(ctx, initData)
+	failures, _, err := RunBatchSession(ctx, initData)
 
 	// ASSERT: Should return 0 failures and no error
 	assert.NoError(t, err, "RunBatchSession should not error with empty queries")
@@ -92,7 +96,7 @@ func TestRunBatchSession_InitError(t *testing.T) {
 	closeInitDataLoaded(initData)
 
 	// ACT: Run batch session
-	failures, err := RunBatchSession(ctx, initData)
+	failures, _, err := RunBatchSession(ctx, initData)
 
 	// ASSERT: Should return the init error immediately
 	assert.Equal(t, expectedErr, err, "Should return initialization error")
@@ -114,7 +118,7 @@ func TestRunBatchSession_NilClient(t *testing.T) {
 	close(initData.Loaded)
 
 	// This should not panic - it should handle nil Client gracefully
-	_, err := RunBatchSession(context.Background(), initData)
+	_, _, err := RunBatchSession(context.Background(), initData)
 
 	// We expect an error indicating that Client is required, not a panic
 	if err == nil {
@@ -145,7 +149,7 @@ func TestRunBatchSession_LoadedTimeout(t *testing.T) {
 	var err error
 
 	go func() {
-		failures, err = RunBatchSession(ctx, initData)
+		failures, _, err = RunBatchSession(ctx, initData)
 		done <- true
 	}()
 
@@ -216,7 +220,7 @@ func TestExecuteQueries_EmptyQueriesList(t *testing.T) {
 	initData.Queries = []*modconfig.ResolvedQuery{}
 
 	// ACT: Execute queries directly
-	failures := executeQueries(ctx, initData)
+	failures, _ := executeQueries(ctx, initData)
 
 	// ASSERT: Should return 0 failures
 	assert.Equal(t, 0, failures, "Should return 0 failures for empty queries list")
@@ -244,7 +248,7 @@ func TestExecuteQueries_NilClient(t *testing.T) {
 
 	// This should not panic - it should handle nil Client gracefully
 	// Currently this will panic with nil pointer dereference
-	failures := executeQueries(ctx, initData)
+	failures, _ := executeQueries(ctx, initData)
 
 	// We expect 1 failure (the query should fail gracefully, not panic)
 	if failures != 1 {
@@ -252,6 +256,95 @@ func TestExecuteQueries_NilClient(t *testing.T) {
 	}
 }
 
+// failingClient is a mock client where every query fails
+type failingClient struct {
+	mockClient
+	executeCount int
+}
+
+func (m *failingClient) Execute(ctx context.Context, query string, args ...any) (*queryresult.Result, error) {
+	m.executeCount++
+	return nil, errors.New("query failed")
+}
+
+func TestExecuteQueries_OnError(t *testing.T) {
+	tests := map[string]struct {
+		onError          string
+		expectedExecuted int
+	}{
+		"continue": {onError: constants.OnErrorContinue, expectedExecuted: 3},
+		"stop":     {onError: constants.OnErrorStop, expectedExecuted: 1},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer viper.Reset()
+			viper.Set(constants.ArgOnError, tc.onError)
+
+			client := &failingClient{}
+			initData := createMockInitData(t)
+			initData.Client = client
+			for i := 0; i < 3; i++ {
+				initData.Queries = append(initData.Queries, &modconfig.ResolvedQuery{ExecuteSQL: "select 1", RawSQL: "select 1"})
+			}
+
+			failures, _ := executeQueries(context.Background(), initData)
+
+			// every executed query counts as a failure
+			assert.Equal(t, tc.expectedExecuted, client.executeCount)
+			assert.Equal(t, tc.expectedExecuted, failures)
+		})
+	}
+}
+
+// rowsClient is a mock client where each query returns the next of the given number of rows
+type rowsClient struct {
+	mockClient
+	rowCounts []int
+}
+
+func (m *rowsClient) Execute(ctx context.Context, query string, args ...any) (*queryresult.Result, error) {
+	rows := make([][]any, m.rowCounts[0])
+	for i := range rows {
+		rows[i] = []any{int64(i)}
+	}
+	m.rowCounts = m.rowCounts[1:]
+	return queryresult.WrapResult(newStreamExportTestResult(rows, nil)), nil
+}
+
+func TestExecuteQueries_RowAssertions(t *testing.T) {
+	tests := map[string]struct {
+		failIfRows                bool
+		expectRows                string
+		expectedAssertionFailures int
+	}{
+		"no assertion":  {expectedAssertionFailures: 0},
+		"fail if rows":  {failIfRows: true, expectedAssertionFailures: 2},
+		"expect rows":   {expectRows: "1-2", expectedAssertionFailures: 2},
+		"expect 3 rows": {expectRows: "3", expectedAssertionFailures: 2},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer viper.Reset()
+			// the rows must be counted even though they are not displayed
+			viper.Set(pconstants.ArgOutput, pconstants.OutputFormatNone)
+			viper.Set(pconstants.ArgTiming, pconstants.ArgOff)
+			viper.Set(constants.ArgFailIfRows, tc.failIfRows)
+			viper.Set(constants.ArgExpectRows, tc.expectRows)
+
+			initData := createMockInitData(t)
+			initData.Client = &rowsClient{rowCounts: []int{0, 1, 3}}
+			for i := 0; i < 3; i++ {
+				initData.Queries = append(initData.Queries, &modconfig.ResolvedQuery{ExecuteSQL: "select 1", RawSQL: "select 1"})
+			}
+
+			failures, assertionFailures := executeQueries(context.Background(), initData)
+
+			assert.Equal(t, 0, failures)
+			assert.Equal(t, tc.expectedAssertionFailures, assertionFailures)
+		})
+	}
+}
+
 // Test Suite: Context and Cancellation
 
 func TestRunBatchSession_CancelHandlerSetup(t *testing.T) {
@@ -266,7 +359,7 @@ func TestRunBatchSession_CancelHandlerSetup(t *testing.T) {
 	// ACT: Run batch session
 	// Note: This test just verifies no panic occurs when setting up cancel handler
 	assert.NotPanics(t, func() {
-		_, _ = RunBatchSession(ctx, initData)
+		_, _, _ = RunBatchSession(ctx, initData)
 	}, "Should not panic when setting up cancel handler")
 }
 
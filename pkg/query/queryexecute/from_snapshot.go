@@ -0,0 +1,61 @@
+package queryexecute
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/viper"
+	pconstants "github.com/turbot/pipe-fittings/v2/constants"
+	"github.com/turbot/pipe-fittings/v2/querydisplay"
+	localconstants "github.com/turbot/steampipe/v2/pkg/constants"
+	"github.com/turbot/steampipe/v2/pkg/display"
+	"github.com/turbot/steampipe/v2/pkg/query/queryresult"
+	"github.com/turbot/steampipe/v2/pkg/snapshot"
+)
+
+// RunSnapshotSession displays the query result stored in a snapshot file using the current output format.
+// The database is not started, so snapshots can be viewed and converted without a Steampipe service.
+// Returns the number of rows with errors
+func RunSnapshotSession(ctx context.Context, filePath string) (int, error) {
+	snap, err := snapshot.Load(filePath)
+	if err != nil {
+		return 0, err
+	}
+
+	outputFormat := viper.GetString(pconstants.ArgOutput)
+	if outputFormat == pconstants.OutputFormatSnapshot || outputFormat == pconstants.OutputFormatSteampipeSnapshotShort {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(snap); err != nil {
+			return 0, fmt.Errorf("error displaying result as snapshot: %w", err)
+		}
+		return 0, nil
+	}
+
+	panel, err := snapshot.QueryTablePanel(snap)
+	if err != nil {
+		return 0, err
+	}
+	result, err := snapshot.SnapshotToQueryResult[queryresult.TimingResult](snap, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	// the only timing data stored in a snapshot is the duration of the query
+	timing := queryresult.TimingResult{
+		DurationMs:   snap.EndTime.Sub(snap.StartTime).Milliseconds(),
+		RowsReturned: int64(len(panel.Data.Rows)),
+	}
+	result.Timing = timing
+
+	var rowErrors int
+	if outputFormat == localconstants.OutputFormatTemplate {
+		_, rowErrors = display.ShowTemplateOutput(ctx, result, func() *queryresult.TimingResult { return &timing })
+	} else {
+		_, rowErrors = querydisplay.ShowOutput(ctx, result)
+	}
+	return rowErrors, nil
+}
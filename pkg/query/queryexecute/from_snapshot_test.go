@@ -0,0 +1,74 @@
+package queryexecute
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pconstants "github.com/turbot/pipe-fittings/v2/constants"
+	localconstants "github.com/turbot/steampipe/v2/pkg/constants"
+)
+
+const testSnapshot = `{"schema_version":"20221222","start_time":"2025-01-01T00:00:00Z","end_time":"2025-01-01T00:00:02Z","panels":{"custom.table.results":{"name":"custom.table.results","panel_type":"table","data":{"columns":[{"name":"id","data_type":"INT8"},{"name":"state","data_type":"TEXT"}],"rows":[{"id":1,"state":"running"},{"id":2,"state":"stopped"}]}}}}`
+
+// runSnapshotSessionOutput runs RunSnapshotSession for the test snapshot and returns the output
+func runSnapshotSessionOutput(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.sps")
+	require.NoError(t, os.WriteFile(path, []byte(testSnapshot), 0600))
+
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	rowErrors, err := RunSnapshotSession(context.Background(), path)
+	require.NoError(t, err)
+	assert.Equal(t, 0, rowErrors)
+
+	require.NoError(t, w.Close())
+	out, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(out)
+}
+
+func TestRunSnapshotSession_CSV(t *testing.T) {
+	viper.Set(pconstants.ArgOutput, pconstants.OutputFormatCSV)
+	viper.Set(pconstants.ArgSeparator, ",")
+	viper.Set(pconstants.ArgHeader, true)
+	defer viper.Reset()
+
+	assert.Equal(t, "id,state\n1,running\n2,stopped\n", runSnapshotSessionOutput(t))
+}
+
+func TestRunSnapshotSession_JSONWithTiming(t *testing.T) {
+	viper.Set(pconstants.ArgOutput, pconstants.OutputFormatJSON)
+	viper.Set(pconstants.ArgTiming, pconstants.ArgOn)
+	defer viper.Reset()
+
+	out := runSnapshotSessionOutput(t)
+	assert.Contains(t, out, `"state": "stopped"`)
+	assert.Contains(t, out, `"duration_ms": 2000`)
+	assert.Contains(t, out, `"rows_returned": 2`)
+}
+
+func TestRunSnapshotSession_Template(t *testing.T) {
+	templatePath := filepath.Join(t.TempDir(), "output.tmpl")
+	require.NoError(t, os.WriteFile(templatePath, []byte(`{{range .Rows}}{{.id}}={{.state}} {{end}}({{.Timing.RowsReturned}} rows, {{.Timing.DurationMs}}ms)`), 0600))
+	viper.Set(pconstants.ArgOutput, localconstants.OutputFormatTemplate)
+	viper.Set(localconstants.ConfigKeyOutputTemplate, templatePath)
+	defer viper.Reset()
+
+	assert.Equal(t, "1=running 2=stopped (2 rows, 2000ms)", runSnapshotSessionOutput(t))
+}
+
+func TestRunSnapshotSession_MissingFile(t *testing.T) {
+	_, err := RunSnapshotSession(context.Background(), filepath.Join(t.TempDir(), "missing.sps"))
+	assert.ErrorContains(t, err, "failed to read snapshot file")
+}
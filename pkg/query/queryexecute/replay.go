@@ -0,0 +1,223 @@
+package queryexecute
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	pconstants "github.com/turbot/pipe-fittings/v2/constants"
+	"github.com/turbot/pipe-fittings/v2/contexthelpers"
+	"github.com/turbot/pipe-fittings/v2/modconfig"
+	pqueryresult "github.com/turbot/pipe-fittings/v2/queryresult"
+	"github.com/turbot/pipe-fittings/v2/utils"
+	"github.com/turbot/steampipe/v2/pkg/connection_sync"
+	"github.com/turbot/steampipe/v2/pkg/db/db_common"
+	"github.com/turbot/steampipe/v2/pkg/display"
+	"github.com/turbot/steampipe/v2/pkg/query"
+	"github.com/turbot/steampipe/v2/pkg/query/transcript"
+	"github.com/turbot/steampipe/v2/pkg/snapshot"
+)
+
+// replayComparison is the result of comparing a replayed query with its recording
+type replayComparison struct {
+	// a description of the difference - empty if the replayed result is the same as the recording
+	difference string
+	// if the rows differ, the diff of the recorded and replayed rows
+	columns []*pqueryresult.ColumnDef
+	diffs   []snapshot.RowDiff
+}
+
+func (c replayComparison) differs() bool {
+	return c.difference != ""
+}
+
+// RunReplaySession re-runs the queries of a session transcript recorded with the .record metaquery, applying the
+// session settings in force when each query was recorded, and reports the queries whose results differ from the recording.
+// Rows are compared ignoring their order. Returns the number of queries whose results differ
+func RunReplaySession(ctx context.Context, initData *query.InitData, filePath string) (int, error) {
+	if initData == nil {
+		return 0, fmt.Errorf("initData cannot be nil")
+	}
+	entries, err := transcript.Load(filePath)
+	if err != nil {
+		return 0, err
+	}
+
+	// start cancel handler to intercept interrupts and cancel the context
+	contexthelpers.StartCancelHandler(initData.Cancel)
+
+	if err := waitForInitialisation(ctx, initData); err != nil {
+		return 0, err
+	}
+	client := initData.Client
+
+	start := entries[0]
+	fmt.Printf("Replaying the session recorded at %s\n", start.Time.Format(time.DateTime)) //nolint:forbidigo // intentional use of fmt
+	if err := applyReplaySettings(ctx, client, start.Settings); err != nil {
+		return 0, err
+	}
+
+	queryCount := 0
+	for _, entry := range entries[1:] {
+		if entry.Type == transcript.EntryQuery {
+			queryCount++
+		}
+	}
+
+	queryIdx, differences := 0, 0
+	for _, entry := range entries[1:] {
+		if ctx.Err() != nil {
+			return differences, ctx.Err()
+		}
+		switch entry.Type {
+		case transcript.EntryMetaquery:
+			fmt.Printf("\n%s\n", entry.Input) //nolint:forbidigo // intentional use of fmt
+			// only the setting changes of a metaquery are replayed
+			if len(entry.Settings) == 0 {
+				fmt.Println("  not replayed - no settings were changed") //nolint:forbidigo // intentional use of fmt
+				continue
+			}
+			if err := applyReplaySettings(ctx, client, entry.Settings); err != nil {
+				return differences, err
+			}
+			fmt.Printf("  set %s\n", entry.Settings) //nolint:forbidigo // intentional use of fmt
+		case transcript.EntryQuery:
+			queryIdx++
+			fmt.Printf("\nQuery %d of %d: %s\n", queryIdx, queryCount, entry.Input) //nolint:forbidigo // intentional use of fmt
+			startTime := time.Now()
+			// as in an interactive session, each query acquires its own session, so setting changes apply to it
+			cols, rows, err := executeQueryRows(ctx, client, nil, &modconfig.ResolvedQuery{
+				RawSQL:     entry.Input,
+				ExecuteSQL: entry.SQL,
+				Args:       replayArgs(entry.Args),
+			})
+			duration := time.Since(startTime)
+
+			comparison := compareReplayedQuery(entry, cols, rows, err)
+			timing := fmt.Sprintf("recorded %s, replayed %s", entry.Duration(), duration.Round(time.Millisecond))
+			if !comparison.differs() {
+				fmt.Printf("  same (%s)\n", timing) //nolint:forbidigo // intentional use of fmt
+				continue
+			}
+			differences++
+			fmt.Printf("  %s: %s (%s)\n", pconstants.ColoredWarn, comparison.difference, timing) //nolint:forbidigo // intentional use of fmt
+			if len(comparison.diffs) > 0 {
+				table, err := display.BuildRowDiffTable(comparison.columns, comparison.diffs, true)
+				if err != nil {
+					return differences, err
+				}
+				fmt.Print(table) //nolint:forbidigo // intentional use of fmt
+			}
+		}
+	}
+
+	fmt.Printf("\nReplayed %d %s: %d same, %d differ\n", queryCount, utils.Pluralize("query", queryCount), queryCount-differences, differences) //nolint:forbidigo // intentional use of fmt
+	return differences, nil
+}
+
+// compareReplayedQuery compares the result of a replayed query with the recorded result
+func compareReplayedQuery(entry *transcript.Entry, cols []*pqueryresult.ColumnDef, rows []map[string]any, err error) replayComparison {
+	replayedErr := ""
+	if err != nil {
+		replayedErr = err.Error()
+	}
+	switch {
+	case entry.Error != replayedErr && entry.Error == "":
+		return replayComparison{difference: fmt.Sprintf("the query failed: %s", replayedErr)}
+	case entry.Error != replayedErr && replayedErr == "":
+		return replayComparison{difference: fmt.Sprintf("the query succeeded, but was recorded as failing: %s", entry.Error)}
+	case entry.Error != replayedErr:
+		return replayComparison{difference: fmt.Sprintf("the query failed with a different error: %s", replayedErr)}
+	case entry.Error != "":
+		// the query failed with the recorded error
+		return replayComparison{}
+	}
+
+	recordedNames, replayedNames := columnNames(entry.Columns), columnNames(cols)
+	if !slices.Equal(recordedNames, replayedNames) {
+		return replayComparison{difference: fmt.Sprintf("the columns differ - recorded %s, replayed %s", strings.Join(recordedNames, ", "), strings.Join(replayedNames, ", "))}
+	}
+
+	diffs, _ := snapshot.DiffRows(entry.Rows, rows, cols, nil)
+	summary := snapshot.SummariseRowDiffs(diffs)
+	if !summary.HasChanges() {
+		return replayComparison{}
+	}
+	// only show the rows which differ
+	var changed []snapshot.RowDiff
+	for _, d := range diffs {
+		if d.Status != snapshot.RowUnchanged {
+			changed = append(changed, d)
+		}
+	}
+	return replayComparison{
+		difference: fmt.Sprintf("the rows differ - %s", display.RowDiffSummaryString(summary)),
+		columns:    cols,
+		diffs:      changed,
+	}
+}
+
+func columnNames(cols []*pqueryresult.ColumnDef) []string {
+	res := make([]string, len(cols))
+	for i, c := range cols {
+		res[i] = c.Name
+	}
+	return res
+}
+
+// replayArgs converts the recorded args of a query to query parameters - numbers are read from the transcript file as json numbers
+func replayArgs(args []any) []any {
+	res := make([]any, len(args))
+	for i, arg := range args {
+		res[i] = arg
+		number, ok := arg.(json.Number)
+		if !ok {
+			continue
+		}
+		if n, err := number.Int64(); err == nil {
+			res[i] = n
+		} else if f, err := number.Float64(); err == nil {
+			res[i] = f
+		}
+	}
+	return res
+}
+
+// applyReplaySettings applies recorded session settings, so queries are replayed as they were recorded
+func applyReplaySettings(ctx context.Context, client db_common.Client, settings transcript.Settings) error {
+	if value, ok := settings.GetString(transcript.SettingOutput); ok {
+		viper.Set(pconstants.ArgOutput, value)
+	}
+	if value, ok := settings.GetBool(transcript.SettingHeader); ok {
+		viper.Set(pconstants.ArgHeader, value)
+	}
+	if value, ok := settings.GetString(transcript.SettingSeparator); ok {
+		viper.Set(pconstants.ArgSeparator, value)
+	}
+	if value, ok := settings.GetString(transcript.SettingTiming); ok {
+		viper.Set(pconstants.ArgTiming, value)
+	}
+	if value, ok := settings.GetBool(transcript.SettingCache); ok {
+		viper.Set(pconstants.ArgClientCacheEnabled, value)
+	}
+	// a ttl of 0 means the ttl was not set
+	if value, ok := settings.GetInt(transcript.SettingCacheTTL); ok && value > 0 {
+		viper.Set(pconstants.ArgCacheTtl, value)
+	}
+	if searchPath, ok := settings.GetStringSlice(transcript.SettingSearchPath); ok && len(searchPath) > 0 {
+		// the recorded search path is the full session search path, so any prefix is already included
+		viper.Set(pconstants.ArgSearchPath, searchPath)
+		viper.Set(pconstants.ArgSearchPathPrefix, []string{})
+		if err := client.SetRequiredSessionSearchPath(ctx); err != nil {
+			return err
+		}
+		if err := connection_sync.WaitForSearchPathSchemas(ctx, client, searchPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package queryexecute
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	pqueryresult "github.com/turbot/pipe-fittings/v2/queryresult"
+	"github.com/turbot/steampipe/v2/pkg/query/transcript"
+	"github.com/turbot/steampipe/v2/pkg/snapshot"
+)
+
+func TestCompareReplayedQuery(t *testing.T) {
+	cols := []*pqueryresult.ColumnDef{{Name: "id", DataType: "INT8"}, {Name: "name", DataType: "TEXT"}}
+	// recorded rows are read from the transcript file, so numbers are json numbers
+	recorded := &transcript.Entry{
+		Type:    transcript.EntryQuery,
+		Columns: cols,
+		Rows: []map[string]any{
+			{"id": json.Number("1"), "name": "a"},
+			{"id": json.Number("2"), "name": "b"},
+		},
+	}
+
+	// the same rows, in a different order
+	comparison := compareReplayedQuery(recorded, cols, []map[string]any{{"id": int64(2), "name": "b"}, {"id": int64(1), "name": "a"}}, nil)
+	assert.False(t, comparison.differs())
+
+	comparison = compareReplayedQuery(recorded, cols, []map[string]any{{"id": int64(1), "name": "a"}, {"id": int64(3), "name": "c"}}, nil)
+	assert.True(t, comparison.differs())
+	assert.Contains(t, comparison.difference, "the rows differ")
+	// only the added and removed rows are shown
+	assert.Equal(t, snapshot.RowDiffSummary{Added: 1, Removed: 1}, snapshot.SummariseRowDiffs(comparison.diffs))
+
+	comparison = compareReplayedQuery(recorded, []*pqueryresult.ColumnDef{{Name: "id"}}, []map[string]any{{"id": int64(1)}}, nil)
+	assert.Equal(t, "the columns differ - recorded id, name, replayed id", comparison.difference)
+
+	comparison = compareReplayedQuery(recorded, nil, nil, errors.New("relation \"foo\" does not exist"))
+	assert.Equal(t, "the query failed: relation \"foo\" does not exist", comparison.difference)
+}
+
+func TestCompareReplayedQuery_RecordedError(t *testing.T) {
+	recorded := &transcript.Entry{Type: transcript.EntryQuery, Error: "relation \"foo\" does not exist"}
+
+	comparison := compareReplayedQuery(recorded, nil, nil, errors.New("relation \"foo\" does not exist"))
+	assert.False(t, comparison.differs())
+
+	comparison = compareReplayedQuery(recorded, nil, nil, errors.New("permission denied"))
+	assert.Equal(t, "the query failed with a different error: permission denied", comparison.difference)
+
+	comparison = compareReplayedQuery(recorded, []*pqueryresult.ColumnDef{{Name: "id"}}, nil, nil)
+	assert.Contains(t, comparison.difference, "the query succeeded")
+}
+
+func TestReplayArgs(t *testing.T) {
+	args := replayArgs([]any{json.Number("1"), json.Number("1.5"), "us-east-1", true})
+	assert.Equal(t, []any{int64(1), 1.5, "us-east-1", true}, args)
+}
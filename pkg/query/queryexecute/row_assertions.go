@@ -0,0 +1,113 @@
+package queryexecute
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/turbot/pipe-fittings/v2/utils"
+	"github.com/turbot/steampipe/v2/pkg/cmdconfig"
+	localconstants "github.com/turbot/steampipe/v2/pkg/constants"
+)
+
+// RowCountRange is the range of row counts a query is expected to return
+type RowCountRange struct {
+	Min int
+	// the maximum row count - -1 if there is no maximum
+	Max int
+}
+
+// ParseRowCountRange parses the value of the --expect-rows arg, one of:
+//   - N: exactly N rows
+//   - N-M: between N and M rows (inclusive)
+//   - N-: at least N rows
+//   - >N, >=N, <N, <=N
+func ParseRowCountRange(value string) (RowCountRange, error) {
+	invalidErr := fmt.Errorf("invalid row count '%s' - must be N, N-M, N-, >N, >=N, <N or <=N", value)
+
+	parseCount := func(s string) (int, error) {
+		n, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil || n < 0 {
+			return 0, invalidErr
+		}
+		return n, nil
+	}
+
+	value = strings.TrimSpace(value)
+	var res RowCountRange
+	var err error
+	switch {
+	case strings.HasPrefix(value, ">="):
+		res.Min, err = parseCount(value[2:])
+		res.Max = -1
+	case strings.HasPrefix(value, ">"):
+		res.Min, err = parseCount(value[1:])
+		res.Min++
+		res.Max = -1
+	case strings.HasPrefix(value, "<="):
+		res.Max, err = parseCount(value[2:])
+	case strings.HasPrefix(value, "<"):
+		res.Max, err = parseCount(value[1:])
+		if err == nil && res.Max == 0 {
+			return res, fmt.Errorf("invalid row count '%s' - a query cannot return fewer than 0 rows", value)
+		}
+		res.Max--
+	case strings.HasSuffix(value, "-"):
+		res.Min, err = parseCount(strings.TrimSuffix(value, "-"))
+		res.Max = -1
+	case strings.Contains(value, "-"):
+		minValue, maxValue, _ := strings.Cut(value, "-")
+		if res.Min, err = parseCount(minValue); err != nil {
+			return res, err
+		}
+		if res.Max, err = parseCount(maxValue); err != nil {
+			return res, err
+		}
+		if res.Max < res.Min {
+			return res, fmt.Errorf("invalid row count '%s' - the minimum is greater than the maximum", value)
+		}
+	default:
+		res.Min, err = parseCount(value)
+		res.Max = res.Min
+	}
+	return res, err
+}
+
+// Contains returns true if the row count is within the range
+func (r RowCountRange) Contains(rowCount int) bool {
+	return rowCount >= r.Min && (r.Max == -1 || rowCount <= r.Max)
+}
+
+func (r RowCountRange) String() string {
+	switch {
+	case r.Max == -1:
+		return fmt.Sprintf("at least %d", r.Min)
+	case r.Min == r.Max:
+		return strconv.Itoa(r.Min)
+	case r.Min == 0:
+		return fmt.Sprintf("at most %d", r.Max)
+	default:
+		return fmt.Sprintf("between %d and %d", r.Min, r.Max)
+	}
+}
+
+// expectedRowCount returns the expected row count range set by the --fail-if-rows or --expect-rows args, if any
+func expectedRowCount() (*RowCountRange, error) {
+	if cmdconfig.Viper().GetBool(localconstants.ArgFailIfRows) {
+		return &RowCountRange{Min: 0, Max: 0}, nil
+	}
+	value := cmdconfig.Viper().GetString(localconstants.ArgExpectRows)
+	if value == "" {
+		return nil, nil
+	}
+	expected, err := ParseRowCountRange(value)
+	if err != nil {
+		return nil, err
+	}
+	return &expected, nil
+}
+
+// rowCountAssertionMessage returns a description of a row count assertion failure
+func rowCountAssertionMessage(expected RowCountRange, rowCount int) string {
+	return fmt.Sprintf("returned %d %s, expected %s", rowCount, utils.Pluralize("row", rowCount), expected)
+}
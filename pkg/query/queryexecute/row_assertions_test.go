@@ -0,0 +1,56 @@
+package queryexecute
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRowCountRange(t *testing.T) {
+	tests := map[string]struct {
+		value   string
+		want    RowCountRange
+		wantErr string
+	}{
+		"exact":            {value: "3", want: RowCountRange{Min: 3, Max: 3}},
+		"range":            {value: "1-10", want: RowCountRange{Min: 1, Max: 10}},
+		"at least":         {value: "5-", want: RowCountRange{Min: 5, Max: -1}},
+		"greater":          {value: ">2", want: RowCountRange{Min: 3, Max: -1}},
+		"greater or equal": {value: ">=2", want: RowCountRange{Min: 2, Max: -1}},
+		"less":             {value: "<2", want: RowCountRange{Min: 0, Max: 1}},
+		"less or equal":    {value: "<=2", want: RowCountRange{Min: 0, Max: 2}},
+		"less than zero":   {value: "<0", wantErr: "a query cannot return fewer than 0 rows"},
+		"inverted range":   {value: "10-1", wantErr: "the minimum is greater than the maximum"},
+		"invalid":          {value: "some", wantErr: "invalid row count 'some'"},
+		"invalid range":    {value: "1-x", wantErr: "invalid row count '1-x'"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseRowCountRange(tc.value)
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestRowCountRange(t *testing.T) {
+	atLeastTwo := RowCountRange{Min: 2, Max: -1}
+	assert.False(t, atLeastTwo.Contains(1))
+	assert.True(t, atLeastTwo.Contains(200))
+	assert.Equal(t, "at least 2", atLeastTwo.String())
+
+	between := RowCountRange{Min: 1, Max: 3}
+	assert.False(t, between.Contains(0))
+	assert.True(t, between.Contains(3))
+	assert.False(t, between.Contains(4))
+	assert.Equal(t, "between 1 and 3", between.String())
+
+	assert.Equal(t, "0", RowCountRange{}.String())
+	assert.Equal(t, "at most 4", RowCountRange{Max: 4}.String())
+	assert.Equal(t, "returned 1 row, expected 0", rowCountAssertionMessage(RowCountRange{}, 1))
+}
@@ -0,0 +1,98 @@
+package queryexecute
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pconstants "github.com/turbot/pipe-fittings/v2/constants"
+	"github.com/turbot/pipe-fittings/v2/modconfig"
+	pqueryresult "github.com/turbot/pipe-fittings/v2/queryresult"
+	"github.com/turbot/steampipe/v2/pkg/export"
+	"github.com/turbot/steampipe/v2/pkg/query/queryresult"
+)
+
+func newStreamExportTestResult(rows [][]any, rowErr error) *pqueryresult.Result[queryresult.TimingResultStream] {
+	cols := []*pqueryresult.ColumnDef{{Name: "id", DataType: "INT8"}}
+	r := queryresult.NewResult(cols)
+	go func() {
+		defer r.Close()
+		for _, row := range rows {
+			r.StreamRow(row)
+		}
+		if rowErr != nil {
+			r.StreamError(rowErr)
+		}
+	}()
+	return r.Result
+}
+
+func TestCanStreamExport(t *testing.T) {
+	defer viper.Reset()
+	initData := createMockInitData(t)
+	require.NoError(t, initData.ExportManager.Register(&export.CsvExporter{}))
+	require.NoError(t, initData.ExportManager.Register(&export.SnapshotExporter{}))
+
+	viper.Set(pconstants.ArgExport, []string{"out.csv"})
+	assert.True(t, canStreamExport(initData))
+
+	viper.Set(pconstants.ArgExport, []string{"out.csv", "out.sps"})
+	assert.False(t, canStreamExport(initData), "snapshot export requires a snapshot")
+
+	viper.Set(pconstants.ArgExport, []string{"out.csv"})
+	viper.Set(pconstants.ArgOutput, pconstants.OutputFormatSnapshot)
+	assert.False(t, canStreamExport(initData), "snapshot output requires a snapshot")
+}
+
+func TestDisplayAndStreamExport(t *testing.T) {
+	defer viper.Reset()
+	target := filepath.Join(t.TempDir(), "out.csv")
+	viper.Set(pconstants.ArgExport, []string{target})
+	// an output format which does not read the rows - the export must still receive them all
+	viper.Set(pconstants.ArgOutput, pconstants.OutputFormatNone)
+
+	initData := createMockInitData(t)
+	require.NoError(t, initData.ExportManager.Register(&export.CsvExporter{}))
+
+	rows := make([][]any, 500)
+	for i := range rows {
+		rows[i] = []any{int64(i)}
+	}
+	q := &modconfig.ResolvedQuery{RawSQL: "select id from test", ExecuteSQL: "select id from test"}
+	_, _, err := displayAndStreamExport(context.Background(), initData, q, newStreamExportTestResult(rows, nil))
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(target)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	// header plus one line per row
+	assert.Len(t, lines, 501)
+}
+
+func TestDisplayAndStreamExport_RowError(t *testing.T) {
+	defer viper.Reset()
+	target := filepath.Join(t.TempDir(), "out.csv")
+	viper.Set(pconstants.ArgExport, []string{target})
+	viper.Set(pconstants.ArgOutput, pconstants.OutputFormatCSV)
+	viper.Set(pconstants.ArgSeparator, ",")
+
+	initData := createMockInitData(t)
+	require.NoError(t, initData.ExportManager.Register(&export.CsvExporter{}))
+
+	q := &modconfig.ResolvedQuery{RawSQL: "select id from test", ExecuteSQL: "select id from test"}
+	rowCount, rowErrors, err := displayAndStreamExport(context.Background(), initData, q, newStreamExportTestResult([][]any{{int64(1)}, {int64(2)}}, errors.New("row failed")))
+	require.NoError(t, err)
+	assert.Equal(t, 2, rowCount)
+	assert.Equal(t, 1, rowErrors)
+
+	// the rows received before the error are exported
+	content, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Equal(t, "id\n1\n2\n", string(content))
+}
@@ -0,0 +1,190 @@
+package queryexecute
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/viper"
+	pconstants "github.com/turbot/pipe-fittings/v2/constants"
+	"github.com/turbot/pipe-fittings/v2/contexthelpers"
+	"github.com/turbot/pipe-fittings/v2/modconfig"
+	pqueryresult "github.com/turbot/pipe-fittings/v2/queryresult"
+	localconstants "github.com/turbot/steampipe/v2/pkg/constants"
+	"github.com/turbot/steampipe/v2/pkg/db/db_common"
+	"github.com/turbot/steampipe/v2/pkg/display"
+	"github.com/turbot/steampipe/v2/pkg/error_helpers"
+	"github.com/turbot/steampipe/v2/pkg/query"
+	"github.com/turbot/steampipe/v2/pkg/query/queryresult"
+	"github.com/turbot/steampipe/v2/pkg/snapshot"
+)
+
+const (
+	minWatchInterval = time.Second
+	// ANSI escape sequence to move the cursor to the top left and clear the screen
+	clearScreen = "\033[H\033[2J"
+)
+
+// ParseWatchInterval parses the value of the --watch arg - either a duration (e.g. 30s, 5m) or a number of seconds
+func ParseWatchInterval(value string) (time.Duration, error) {
+	var interval time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		interval = time.Duration(seconds) * time.Second
+	} else if interval, err = time.ParseDuration(value); err != nil {
+		return 0, fmt.Errorf("invalid watch interval '%s' - must be a duration (e.g. 30s, 5m) or a number of seconds", value)
+	}
+	if interval < minWatchInterval {
+		return 0, fmt.Errorf("watch interval must be at least %s", minWatchInterval)
+	}
+	return interval, nil
+}
+
+// watchedQuery is a query which is being watched, along with the rows returned by its previous execution
+type watchedQuery struct {
+	query        *modconfig.ResolvedQuery
+	previousRows []map[string]any
+	executed     bool
+}
+
+// RunWatchSession executes the queries repeatedly at the watch interval until cancelled, redrawing the results
+// each time and highlighting the rows which have been added, removed or changed since the previous execution.
+// Rows are matched using the watch key columns, if given
+func RunWatchSession(ctx context.Context, initData *query.InitData) error {
+	if initData == nil {
+		return fmt.Errorf("initData cannot be nil")
+	}
+
+	// watch runs until interrupted, so as well as cancelling any initialisation, cancel our context
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	contexthelpers.StartCancelHandler(func() {
+		initData.Cancel()
+		cancel()
+	})
+
+	if err := waitForInitialisation(ctx, initData); err != nil {
+		return err
+	}
+
+	interval, err := ParseWatchInterval(viper.GetString(localconstants.ArgWatch))
+	if err != nil {
+		return err
+	}
+	keyColumns := viper.GetStringSlice(localconstants.ArgWatchKey)
+
+	// execute every refresh in the same session, so session state persists between refreshes
+	sessionResult := initData.Client.AcquireSession(ctx)
+	if sessionResult.Error != nil {
+		return sessionResult.Error
+	}
+	defer func() {
+		// we need to do this in a closure, otherwise the ctx will be evaluated immediately
+		// and not in call-time
+		sessionResult.Session.Close(error_helpers.IsContextCanceled(ctx))
+	}()
+
+	queries := make([]*watchedQuery, len(initData.Queries))
+	for i, q := range initData.Queries {
+		queries[i] = &watchedQuery{query: q}
+	}
+
+	isTerminal := isatty.IsTerminal(os.Stdout.Fd())
+	for {
+		output := refreshWatchedQueries(ctx, initData.Client, sessionResult.Session, queries, keyColumns, interval)
+		if ctx.Err() != nil {
+			return nil
+		}
+		// redraw the screen - if the output is not a terminal, just separate each execution with a blank line
+		if isTerminal {
+			fmt.Print(clearScreen + output) //nolint:forbidigo // intentional use of fmt
+		} else {
+			fmt.Println(output) //nolint:forbidigo // intentional use of fmt
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// refreshWatchedQueries executes each of the watched queries in the given session and returns the output to display
+func refreshWatchedQueries(ctx context.Context, client db_common.Client, session *db_common.DatabaseSession, queries []*watchedQuery, keyColumns []string, interval time.Duration) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Every %s: %s\n", interval, time.Now().Format(time.DateTime))
+
+	for i, q := range queries {
+		b.WriteString("\n")
+		if len(queries) > 1 {
+			fmt.Fprintf(&b, "Query %d of %d\n", i+1, len(queries))
+		}
+
+		cols, rows, err := executeQueryRows(ctx, client, session, q.query)
+		if err != nil {
+			// keep the previous rows, so changes are shown against the last successful execution
+			fmt.Fprintf(&b, "%s: %v\n", pconstants.ColoredErr, error_helpers.DecodePgError(err))
+			continue
+		}
+
+		var diffs []snapshot.RowDiff
+		if q.executed {
+			diffs, err = snapshot.DiffRows(q.previousRows, rows, cols, keyColumns)
+			if err != nil {
+				fmt.Fprintf(&b, "%s: %v - comparing all columns\n", pconstants.ColoredWarn, err)
+				diffs, _ = snapshot.DiffRows(q.previousRows, rows, cols, nil)
+			}
+		} else {
+			// nothing to compare the first execution with
+			diffs = make([]snapshot.RowDiff, len(rows))
+			for i, row := range rows {
+				diffs[i] = snapshot.RowDiff{Status: snapshot.RowUnchanged, Row: row}
+			}
+		}
+		q.previousRows = rows
+		q.executed = true
+
+		table, err := display.BuildRowDiffTable(cols, diffs, viper.GetBool(pconstants.ArgHeader))
+		if err != nil {
+			fmt.Fprintf(&b, "%s: %v\n", pconstants.ColoredErr, err)
+			continue
+		}
+		b.WriteString(table)
+		b.WriteString(display.RowDiffSummaryString(snapshot.SummariseRowDiffs(diffs)) + "\n")
+	}
+	return b.String()
+}
+
+// executeQueryRows executes a query and returns all of its rows, in the snapshot row format.
+// The query is executed in the given session - if the session is nil, a session is acquired for the query
+func executeQueryRows(ctx context.Context, client db_common.Client, session *db_common.DatabaseSession, q *modconfig.ResolvedQuery) ([]*pqueryresult.ColumnDef, []map[string]any, error) {
+	var result *queryresult.Result
+	var err error
+	if session != nil {
+		result, err = client.ExecuteInSession(ctx, session, nil, q.ExecuteSQL, q.Args...)
+	} else {
+		result, err = client.Execute(ctx, q.ExecuteSQL, q.Args...)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cols := snapshot.ColumnDefs(result.Cols)
+	var rows []map[string]any
+	var rowErr error
+	// read all the rows, even after an error, so the session is not blocked
+	for row := range result.RowChan {
+		if row.Error != nil {
+			if rowErr == nil {
+				rowErr = row.Error
+			}
+			continue
+		}
+		rows = append(rows, snapshot.RowData(row.Data, result.Cols))
+	}
+	return cols, rows, rowErr
+}
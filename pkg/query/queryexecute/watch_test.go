@@ -0,0 +1,68 @@
+package queryexecute
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/turbot/pipe-fittings/v2/modconfig"
+	"github.com/turbot/steampipe/v2/pkg/db/db_common"
+	"github.com/turbot/steampipe/v2/pkg/query/queryresult"
+)
+
+func TestParseWatchInterval(t *testing.T) {
+	tests := map[string]struct {
+		value   string
+		want    time.Duration
+		wantErr string
+	}{
+		"seconds":   {value: "10", want: 10 * time.Second},
+		"duration":  {value: "1m30s", want: 90 * time.Second},
+		"too short": {value: "500ms", wantErr: "watch interval must be at least 1s"},
+		"zero":      {value: "0", wantErr: "watch interval must be at least 1s"},
+		"invalid":   {value: "often", wantErr: "invalid watch interval 'often'"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseWatchInterval(tc.value)
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+// sessionClient is a mock client which records the sessions queries are executed in
+type sessionClient struct {
+	mockClient
+	sessions []*db_common.DatabaseSession
+}
+
+func (m *sessionClient) ExecuteInSession(ctx context.Context, session *db_common.DatabaseSession, onComplete func(), query string, args ...any) (*queryresult.Result, error) {
+	m.sessions = append(m.sessions, session)
+	return queryresult.WrapResult(newStreamExportTestResult([][]any{{int64(1)}}, nil)), nil
+}
+
+func (m *sessionClient) Execute(ctx context.Context, query string, args ...any) (*queryresult.Result, error) {
+	return nil, errors.New("queries should be executed in the watch session")
+}
+
+func TestRefreshWatchedQueries_UsesSession(t *testing.T) {
+	client := &sessionClient{}
+	session := &db_common.DatabaseSession{}
+	queries := []*watchedQuery{
+		{query: &modconfig.ResolvedQuery{ExecuteSQL: "select 1"}},
+		{query: &modconfig.ResolvedQuery{ExecuteSQL: "select 2"}},
+	}
+
+	for i := 0; i < 2; i++ {
+		output := refreshWatchedQueries(context.Background(), client, session, queries, nil, time.Second)
+		assert.NotContains(t, output, "should be executed in the watch session")
+	}
+	assert.Equal(t, []*db_common.DatabaseSession{session, session, session, session}, client.sessions)
+}
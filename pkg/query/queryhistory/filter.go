@@ -0,0 +1,224 @@
+package queryhistory
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultFilterLimit is the number of entries returned by Find if the filter has no limit
+const DefaultFilterLimit = 20
+
+// Filter selects history entries.
+// It is parsed from a list of terms, each of which is either a condition (e.g. rows=12, duration>1s, status=error)
+// or text which the query must contain
+type Filter struct {
+	// text which the query must contain (case-insensitive)
+	Text []string
+	// the status of the execution - empty to match any status
+	Status HistoryEntryStatus
+	// a connection which the query must have used
+	Connection string
+	Rows       *comparison
+	DurationMs *comparison
+	// the time range of the execution - zero values are unbounded
+	Since time.Time
+	Until time.Time
+	// the maximum number of entries to return (the most recent matches are returned)
+	Limit int
+}
+
+// comparison is a numeric condition, e.g. rows>10
+type comparison struct {
+	operator string
+	value    int64
+}
+
+func (c *comparison) matches(v int64) bool {
+	switch c.operator {
+	case ">":
+		return v > c.value
+	case ">=":
+		return v >= c.value
+	case "<":
+		return v < c.value
+	case "<=":
+		return v <= c.value
+	default:
+		return v == c.value
+	}
+}
+
+// regex to split a filter term into key, operator and value
+var filterTermRegex = regexp.MustCompile(`^(\w+)(>=|<=|=|>|<)(.*)$`)
+
+var filterKeys = []string{"status", "connection", "rows", "duration", "date", "since", "until", "limit"}
+
+// ParseFilter parses the terms of a filter. Relative dates (e.g. yesterday, 7d) are evaluated relative to now.
+// Supported conditions are:
+//
+//	status=success|error
+//	connection=<name>
+//	rows=<n> (also >, >=, <, <=)
+//	duration=<duration> (also >, >=, <, <=), e.g. duration>500ms
+//	date=<date> - a date (2006-01-02), today, yesterday or a day of the week (the most recent such day)
+//	since=<date or duration>, until=<date or duration> - e.g. since=2006-01-02, since=24h, since=7d
+//	limit=<n>
+func ParseFilter(terms []string, now time.Time) (*Filter, error) {
+	f := &Filter{Limit: DefaultFilterLimit}
+	for _, term := range terms {
+		// terms which are not conditions (including sql such as name='foo') are text to search for
+		match := filterTermRegex.FindStringSubmatch(term)
+		if match == nil || !slices.Contains(filterKeys, strings.ToLower(match[1])) {
+			f.Text = append(f.Text, strings.ToLower(term))
+			continue
+		}
+		key, operator, value := strings.ToLower(match[1]), match[2], match[3]
+		if err := f.setCondition(key, operator, value, now); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+func (f *Filter) setCondition(key, operator, value string, now time.Time) error {
+	// conditions which only support '='
+	if operator != "=" && !slices.Contains([]string{"rows", "duration"}, key) {
+		return fmt.Errorf("'%s' only supports the '=' operator", key)
+	}
+
+	switch key {
+	case "status":
+		status := HistoryEntryStatus(strings.ToLower(value))
+		if status != StatusSuccess && status != StatusError {
+			return fmt.Errorf("invalid status '%s' - must be one of %s, %s", value, StatusSuccess, StatusError)
+		}
+		f.Status = status
+	case "connection":
+		f.Connection = value
+	case "rows":
+		rows, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || rows < 0 {
+			return fmt.Errorf("invalid row count '%s'", value)
+		}
+		f.Rows = &comparison{operator: operator, value: rows}
+	case "duration":
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration '%s' - must be a duration such as 500ms or 2s", value)
+		}
+		f.DurationMs = &comparison{operator: operator, value: d.Milliseconds()}
+	case "date":
+		day, err := parseDay(value, now)
+		if err != nil {
+			return err
+		}
+		f.Since, f.Until = day, day.AddDate(0, 0, 1)
+	case "since", "until":
+		t, err := parseTime(value, now)
+		if err != nil {
+			return err
+		}
+		if key == "since" {
+			f.Since = t
+		} else {
+			f.Until = t
+		}
+	case "limit":
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			return fmt.Errorf("invalid limit '%s' - must be a positive number", value)
+		}
+		f.Limit = limit
+	}
+	return nil
+}
+
+// parseDay parses a date, 'today', 'yesterday' or a day of the week, returning the start of the day
+func parseDay(value string, now time.Time) (time.Time, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch v := strings.ToLower(value); v {
+	case "today":
+		return today, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	default:
+		for d := 0; d < 7; d++ {
+			day := today.AddDate(0, 0, -d)
+			if strings.ToLower(day.Weekday().String()) == v {
+				return day, nil
+			}
+		}
+	}
+	day, err := time.ParseInLocation(time.DateOnly, value, now.Location())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date '%s' - must be a date (YYYY-MM-DD), today, yesterday or a day of the week", value)
+	}
+	return day, nil
+}
+
+// parseTime parses a date, a date and time, or a duration before now (including a number of days, e.g. 7d)
+func parseTime(value string, now time.Time) (time.Time, error) {
+	if days, found := strings.CutSuffix(value, "d"); found {
+		if n, err := strconv.Atoi(days); err == nil {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.ParseInLocation(time.DateTime, value, now.Location()); err == nil {
+		return t, nil
+	}
+	if day, err := parseDay(value, now); err == nil {
+		return day, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time '%s' - must be a date (YYYY-MM-DD), a date and time (YYYY-MM-DD HH:MM:SS) or a duration (e.g. 24h, 7d)", value)
+}
+
+// Matches returns whether the entry satisfies the filter
+func (f *Filter) Matches(e *HistoryEntry) bool {
+	query := strings.ToLower(e.Query)
+	for _, text := range f.Text {
+		if !strings.Contains(query, text) {
+			return false
+		}
+	}
+	if f.Status != "" && e.Status != f.Status {
+		return false
+	}
+	if f.Connection != "" && !slices.Contains(e.Connections, f.Connection) {
+		return false
+	}
+	// entries which have not been executed (e.g. loaded from a previous version) have no metadata
+	hasResult := e.Status != ""
+	if f.Rows != nil && (!hasResult || !f.Rows.matches(e.Rows)) {
+		return false
+	}
+	if f.DurationMs != nil && (!hasResult || !f.DurationMs.matches(e.DurationMs)) {
+		return false
+	}
+	if !f.Since.IsZero() && e.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !e.Timestamp.Before(f.Until) {
+		return false
+	}
+	return true
+}
+
+// Find returns the most recent entries which match the filter, oldest first
+func (q *QueryHistory) Find(f *Filter) []*HistoryEntry {
+	var res []*HistoryEntry
+	entries := q.Entries()
+	for i := len(entries) - 1; i >= 0 && len(res) < f.Limit; i-- {
+		if f.Matches(entries[i]) {
+			res = append(res, entries[i])
+		}
+	}
+	slices.Reverse(res)
+	return res
+}
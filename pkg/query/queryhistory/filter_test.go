@@ -0,0 +1,85 @@
+package queryhistory
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFilter(t *testing.T) {
+	// a saturday
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	tuesday := time.Date(2026, 10, 13, 9, 30, 0, 0, time.UTC)
+
+	entries := map[string]*HistoryEntry{
+		"tuesday": {Query: "select * from aws_s3_bucket", Timestamp: tuesday, DurationMs: 1500, Rows: 12, Status: StatusSuccess, Connections: []string{"aws"}},
+		"today":   {Query: "select * from github_repo where name = 'steampipe'", Timestamp: now.Add(-time.Hour), DurationMs: 200, Rows: 1, Status: StatusSuccess, Connections: []string{"github"}},
+		"error":   {Query: "select * from aws_ec2_instance", Timestamp: now.Add(-2 * time.Hour), Status: StatusError, Error: "boom", Connections: []string{"aws"}},
+		"legacy":  {Query: "select 1"},
+	}
+
+	cases := map[string]struct {
+		terms    []string
+		expected []string
+	}{
+		"no filter":                   {terms: nil, expected: []string{"tuesday", "today", "error", "legacy"}},
+		"text":                        {terms: []string{"AWS_"}, expected: []string{"tuesday", "error"}},
+		"sql text":                    {terms: []string{"name", "=", "'steampipe'"}, expected: []string{"today"}},
+		"status":                      {terms: []string{"status=error"}, expected: []string{"error"}},
+		"connection":                  {terms: []string{"connection=aws"}, expected: []string{"tuesday", "error"}},
+		"rows":                        {terms: []string{"rows=12"}, expected: []string{"tuesday"}},
+		"rows greater than":           {terms: []string{"rows>0"}, expected: []string{"tuesday", "today"}},
+		"duration":                    {terms: []string{"duration>=1s"}, expected: []string{"tuesday"}},
+		"day of week":                 {terms: []string{"date=tuesday", "rows=12"}, expected: []string{"tuesday"}},
+		"date":                        {terms: []string{"date=2026-10-13"}, expected: []string{"tuesday"}},
+		"today":                       {terms: []string{"date=today"}, expected: []string{"today", "error"}},
+		"since duration":              {terms: []string{"since=90m"}, expected: []string{"today"}},
+		"since days":                  {terms: []string{"since=7d"}, expected: []string{"tuesday", "today", "error"}},
+		"until":                       {terms: []string{"until=2026-10-14"}, expected: []string{"tuesday", "legacy"}},
+		"combined text and condition": {terms: []string{"aws", "status=success"}, expected: []string{"tuesday"}},
+	}
+
+	for name, test := range cases {
+		t.Run(name, func(t *testing.T) {
+			f, err := ParseFilter(test.terms, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for entryName, entry := range entries {
+				expected := false
+				for _, e := range test.expected {
+					expected = expected || e == entryName
+				}
+				if actual := f.Matches(entry); actual != expected {
+					t.Errorf("entry '%s': expected match %v, got %v", entryName, expected, actual)
+				}
+			}
+		})
+	}
+}
+
+func TestParseFilter_Errors(t *testing.T) {
+	for _, term := range []string{"status=pending", "rows=many", "duration>soon", "date=someday", "since=whenever", "limit=0", "status>error"} {
+		t.Run(term, func(t *testing.T) {
+			if _, err := ParseFilter([]string{term}, time.Now()); err == nil {
+				t.Errorf("expected an error parsing '%s'", term)
+			}
+		})
+	}
+}
+
+func TestQueryHistory_Find(t *testing.T) {
+	history := &QueryHistory{}
+	for _, q := range []string{"select 1", "select 2", "select 3", "select 4"} {
+		history.Push(q)
+	}
+	history.Push("select now()")
+
+	f, err := ParseFilter([]string{"select", "limit=2"}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := history.Find(f)
+	if len(res) != 2 || res[0].Query != "select 4" || res[1].Query != "select now()" {
+		t.Errorf("expected the 2 most recent matches, oldest first, got %+v", res)
+	}
+}
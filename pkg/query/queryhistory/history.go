@@ -6,19 +6,70 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/turbot/steampipe/v2/pkg/constants"
 	"github.com/turbot/steampipe/v2/pkg/filepaths"
 )
 
+// HistoryEntryStatus is the outcome of executing a history entry
+type HistoryEntryStatus string
+
+const (
+	StatusSuccess HistoryEntryStatus = "success"
+	StatusError   HistoryEntryStatus = "error"
+)
+
+// HistoryEntry is a single entry of the query history, along with the metadata of its execution.
+// Entries loaded from a history file written by a previous version only have a query
+type HistoryEntry struct {
+	Query     string    `json:"query"`
+	Timestamp time.Time `json:"timestamp"`
+	// the duration of the execution in milliseconds
+	DurationMs  int64              `json:"duration_ms"`
+	Rows        int64              `json:"rows"`
+	Status      HistoryEntryStatus `json:"status,omitempty"`
+	Error       string             `json:"error,omitempty"`
+	Connections []string           `json:"connections,omitempty"`
+}
+
+// SetResult records the result of executing the entry
+func (e *HistoryEntry) SetResult(duration time.Duration, rows int64, connections []string, err error) {
+	// the entry is nil if the query was not stored, e.g. if it was blank
+	if e == nil {
+		return
+	}
+	e.DurationMs = duration.Milliseconds()
+	e.Rows = rows
+	e.Connections = connections
+	e.Status = StatusSuccess
+	e.Error = ""
+	if err != nil {
+		e.Status = StatusError
+		e.Error = err.Error()
+	}
+}
+
+// UnmarshalJSON supports history files written by previous versions, which stored each entry as a string
+func (e *HistoryEntry) UnmarshalJSON(data []byte) error {
+	var query string
+	if err := json.Unmarshal(data, &query); err == nil {
+		*e = HistoryEntry{Query: query}
+		return nil
+	}
+	// use an alias type to avoid recursing into this function
+	type historyEntry HistoryEntry
+	return json.Unmarshal(data, (*historyEntry)(e))
+}
+
 // QueryHistory :: struct for working with history in the interactive mode
 type QueryHistory struct {
-	history []string
+	history []*HistoryEntry
 }
 
 // New creates a new QueryHistory object
 func New() (*QueryHistory, error) {
-	history := &QueryHistory{history: []string{}}
+	history := &QueryHistory{history: []*HistoryEntry{}}
 	err := history.load()
 	if err != nil {
 		return nil, err
@@ -26,32 +77,37 @@ func New() (*QueryHistory, error) {
 	return history, nil
 }
 
-// Push adds a string to the history queue trimming to maxHistorySize if necessary
-func (q *QueryHistory) Push(query string) {
+// Push adds a query to the history queue trimming to maxHistorySize if necessary,
+// and returns the entry so the result of the execution can be recorded.
+// Returns nil if the query is blank
+func (q *QueryHistory) Push(query string) *HistoryEntry {
 	if len(strings.TrimSpace(query)) == 0 {
 		// do not store a blank query
-		return
+		return nil
 	}
 
 	// do a strict compare to see if we have this same exact query as the most recent history item
-	if lastElement := q.Peek(); lastElement != nil && (*lastElement) == query {
-		return
+	// - if so, replace it so the history reflects the latest execution
+	if lastElement := q.Peek(); lastElement != nil && lastElement.Query == query {
+		q.history = q.history[:len(q.history)-1]
 	}
 
 	// append the new entry
-	q.history = append(q.history, query)
+	entry := &HistoryEntry{Query: query, Timestamp: time.Now()}
+	q.history = append(q.history, entry)
 
 	// enforce the size limit after adding
 	q.enforceLimit()
+	return entry
 }
 
 // Peek returns the last element of the history stack.
 // returns nil if there is no history
-func (q *QueryHistory) Peek() *string {
+func (q *QueryHistory) Peek() *HistoryEntry {
 	if len(q.history) == 0 {
 		return nil
 	}
-	return &q.history[len(q.history)-1]
+	return q.history[len(q.history)-1]
 }
 
 // Persist writes the history to the filesystem
@@ -75,8 +131,18 @@ func (q *QueryHistory) Persist() error {
 	return jsonEncoder.Encode(q.history)
 }
 
-// Get returns the full history, enforcing the size limit
+// Get returns the queries of the full history, enforcing the size limit
 func (q *QueryHistory) Get() []string {
+	entries := q.Entries()
+	res := make([]string, len(entries))
+	for i, entry := range entries {
+		res[i] = entry.Query
+	}
+	return res
+}
+
+// Entries returns the full history, oldest first, enforcing the size limit
+func (q *QueryHistory) Entries() []*HistoryEntry {
 	// Ensure history doesn't exceed the limit before returning
 	q.enforceLimit()
 	return q.history
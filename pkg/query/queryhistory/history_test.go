@@ -1,8 +1,11 @@
 package queryhistory
 
 import (
+	"encoding/json"
 	"fmt"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/turbot/steampipe/v2/pkg/constants"
 )
@@ -17,9 +20,9 @@ func TestQueryHistory_BoundedSize(t *testing.T) {
 
 	// Simulate a scenario where history is pre-populated (e.g., from a corrupted file or direct manipulation)
 	// This represents the in-memory history during a long-running session
-	oversizedHistory := make([]string, constants.HistorySize+100)
+	oversizedHistory := make([]*HistoryEntry, constants.HistorySize+100)
 	for i := 0; i < len(oversizedHistory); i++ {
-		oversizedHistory[i] = fmt.Sprintf("SELECT %d;", i)
+		oversizedHistory[i] = &HistoryEntry{Query: fmt.Sprintf("SELECT %d;", i)}
 	}
 
 	history := &QueryHistory{history: oversizedHistory}
@@ -37,3 +40,52 @@ func TestQueryHistory_BoundedSize(t *testing.T) {
 		t.Errorf("After Push(), history size %d exceeds limit %d", len(history.history), constants.HistorySize)
 	}
 }
+
+func TestQueryHistory_Push(t *testing.T) {
+	history := &QueryHistory{}
+
+	if entry := history.Push("  "); entry != nil {
+		t.Errorf("expected a blank query not to be stored")
+	}
+
+	first := history.Push("select 1")
+	first.SetResult(0, 1, nil, nil)
+	history.Push("select 2")
+	// pushing the most recent query again replaces it, so the entry reflects the latest execution
+	latest := history.Push("select 2")
+
+	if got := history.Get(); !reflect.DeepEqual(got, []string{"select 1", "select 2"}) {
+		t.Errorf("unexpected history %v", got)
+	}
+	if history.Peek() != latest {
+		t.Errorf("expected Peek() to return the latest entry")
+	}
+	if first.Status != StatusSuccess || first.Rows != 1 {
+		t.Errorf("expected the result to be recorded, got %+v", first)
+	}
+}
+
+func TestHistoryEntry_UnmarshalJSON(t *testing.T) {
+	// history files written by previous versions are an array of strings
+	data := `["select 1", {"query": "select 2", "timestamp": "2026-10-13T10:00:00Z", "duration_ms": 150, "rows": 12, "status": "success", "connections": ["aws"]}]`
+
+	var entries []*HistoryEntry
+	if err := json.Unmarshal([]byte(data), &entries); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []*HistoryEntry{
+		{Query: "select 1"},
+		{
+			Query:       "select 2",
+			Timestamp:   time.Date(2026, 10, 13, 10, 0, 0, 0, time.UTC),
+			DurationMs:  150,
+			Rows:        12,
+			Status:      StatusSuccess,
+			Connections: []string{"aws"},
+		},
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("expected %+v, got %+v", expected, entries)
+	}
+}
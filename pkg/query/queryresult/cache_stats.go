@@ -0,0 +1,85 @@
+package queryresult
+
+import (
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ConnectionScanSummary is the total of the scans of a connection made by a query,
+// split by whether the scans were served from the cache
+type ConnectionScanSummary struct {
+	// the fields of this struct need to be public since these are populated by pgx using RowsToStruct
+	Connection  string `db:"connection"`
+	CacheHit    bool   `db:"cache_hit"`
+	RowsFetched int64  `db:"rows_fetched"`
+	ScanCount   int64  `db:"scan_count"`
+}
+
+// ConnectionCacheStats is the cache usage of a connection, accumulated over the queries of a session
+type ConnectionCacheStats struct {
+	Connection   string
+	CachedRows   int64
+	FetchedRows  int64
+	CachedScans  int64
+	FetchedScans int64
+}
+
+// RowHitRatio returns the proportion of rows which were served from the cache
+func (s ConnectionCacheStats) RowHitRatio() float64 {
+	return hitRatio(s.CachedRows, s.FetchedRows)
+}
+
+// ScanHitRatio returns the proportion of scans which were served from the cache
+func (s ConnectionCacheStats) ScanHitRatio() float64 {
+	return hitRatio(s.CachedScans, s.FetchedScans)
+}
+
+func hitRatio(cached, fetched int64) float64 {
+	if cached+fetched == 0 {
+		return 0
+	}
+	return float64(cached) / float64(cached+fetched)
+}
+
+// CacheStats accumulates the cache usage of each connection from the scan summaries of executed queries
+type CacheStats struct {
+	connections map[string]*ConnectionCacheStats
+	lock        sync.Mutex
+}
+
+func NewCacheStats() *CacheStats {
+	return &CacheStats{connections: make(map[string]*ConnectionCacheStats)}
+}
+
+// Add adds the scan summaries of a query to the stats
+func (c *CacheStats) Add(summaries []ConnectionScanSummary) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, summary := range summaries {
+		stats, ok := c.connections[summary.Connection]
+		if !ok {
+			stats = &ConnectionCacheStats{Connection: summary.Connection}
+			c.connections[summary.Connection] = stats
+		}
+		if summary.CacheHit {
+			stats.CachedRows += summary.RowsFetched
+			stats.CachedScans += summary.ScanCount
+		} else {
+			stats.FetchedRows += summary.RowsFetched
+			stats.FetchedScans += summary.ScanCount
+		}
+	}
+}
+
+// Stats returns the stats of each connection, sorted by connection name
+func (c *CacheStats) Stats() []ConnectionCacheStats {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	res := make([]ConnectionCacheStats, 0, len(c.connections))
+	for _, stats := range c.connections {
+		res = append(res, *stats)
+	}
+	slices.SortFunc(res, func(a, b ConnectionCacheStats) int { return strings.Compare(a.Connection, b.Connection) })
+	return res
+}
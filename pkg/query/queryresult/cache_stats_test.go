@@ -0,0 +1,35 @@
+package queryresult
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheStats_Add(t *testing.T) {
+	stats := NewCacheStats()
+	// first query - aws fetched, then served from the cache by the second query
+	stats.Add([]ConnectionScanSummary{
+		{Connection: "aws", CacheHit: false, RowsFetched: 100, ScanCount: 2},
+		{Connection: "github", CacheHit: false, RowsFetched: 10, ScanCount: 1},
+	})
+	stats.Add([]ConnectionScanSummary{
+		{Connection: "aws", CacheHit: true, RowsFetched: 300, ScanCount: 2},
+	})
+
+	expected := []ConnectionCacheStats{
+		{Connection: "aws", CachedRows: 300, FetchedRows: 100, CachedScans: 2, FetchedScans: 2},
+		{Connection: "github", FetchedRows: 10, FetchedScans: 1},
+	}
+	res := stats.Stats()
+	assert.Equal(t, expected, res)
+	assert.Equal(t, 0.75, res[0].RowHitRatio())
+	assert.Equal(t, 0.5, res[0].ScanHitRatio())
+	assert.Equal(t, 0.0, res[1].RowHitRatio())
+}
+
+func TestConnectionCacheStats_NoRows(t *testing.T) {
+	stats := ConnectionCacheStats{Connection: "aws", FetchedScans: 1}
+	assert.Equal(t, 0.0, stats.RowHitRatio())
+	assert.Equal(t, 0.0, stats.ScanHitRatio())
+}
@@ -0,0 +1,129 @@
+package savedquery
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/turbot/steampipe/v2/pkg/filepaths"
+)
+
+// the file extension of saved query files
+const fileExtension = ".sql"
+
+// nameRegex matches a valid saved query name
+var nameRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_-]{0,63}$`)
+
+// SavedQuery is a query saved by name in the saved queries directory.
+// Each query is stored as a sql file, so saved queries can be shared by copying the files
+type SavedQuery struct {
+	Name    string
+	SQL     string
+	ModTime time.Time
+}
+
+// ValidateName returns an error if the name is not a valid saved query name
+func ValidateName(name string) error {
+	if !nameRegex.MatchString(name) {
+		return fmt.Errorf("invalid query name '%s' - names must start with a letter, contain only letters, digits, '_' and '-', and be at most 64 characters", name)
+	}
+	return nil
+}
+
+// IsValidName returns whether the name is a valid saved query name
+func IsValidName(name string) bool {
+	return nameRegex.MatchString(name)
+}
+
+// Save saves the sql under the given name, replacing any existing query with that name.
+// Returns whether an existing query was replaced
+func Save(name, sql string) (bool, error) {
+	if err := ValidateName(name); err != nil {
+		return false, err
+	}
+	if strings.TrimSpace(sql) == "" {
+		return false, fmt.Errorf("cannot save an empty query")
+	}
+	// only saving a query creates the saved queries directory
+	filepaths.EnsureSavedQueriesDir()
+	path := filePath(name)
+	_, err := os.Stat(path)
+	exists := err == nil
+
+	if !strings.HasSuffix(sql, "\n") {
+		sql += "\n"
+	}
+	if err := os.WriteFile(path, []byte(sql), 0600); err != nil {
+		return false, fmt.Errorf("failed to save query '%s': %s", name, err.Error())
+	}
+	return exists, nil
+}
+
+// Load returns the saved query with the given name, or nil if there is no such query
+func Load(name string) (*SavedQuery, error) {
+	if !IsValidName(name) {
+		return nil, nil
+	}
+	path := filePath(name)
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load query '%s': %s", name, err.Error())
+	}
+	return &SavedQuery{Name: name, SQL: strings.TrimSpace(string(data)), ModTime: info.ModTime()}, nil
+}
+
+// List returns all saved queries, sorted by name
+func List() ([]*SavedQuery, error) {
+	entries, err := os.ReadDir(filepaths.SavedQueriesDir())
+	if os.IsNotExist(err) {
+		// no queries have been saved
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var res []*SavedQuery
+	for _, entry := range entries {
+		name, isSQL := strings.CutSuffix(entry.Name(), fileExtension)
+		if entry.IsDir() || !isSQL || !IsValidName(name) {
+			continue
+		}
+		q, err := Load(name)
+		if err != nil {
+			return nil, err
+		}
+		if q != nil {
+			res = append(res, q)
+		}
+	}
+	slices.SortFunc(res, func(a, b *SavedQuery) int { return strings.Compare(a.Name, b.Name) })
+	return res, nil
+}
+
+// Names returns the names of all saved queries, sorted by name
+func Names() []string {
+	queries, err := List()
+	if err != nil {
+		return nil
+	}
+	res := make([]string, len(queries))
+	for i, q := range queries {
+		res[i] = q.Name
+	}
+	return res
+}
+
+func filePath(name string) string {
+	return filepath.Join(filepaths.SavedQueriesDir(), name+fileExtension)
+}
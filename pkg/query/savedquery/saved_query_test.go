@@ -0,0 +1,76 @@
+package savedquery
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/turbot/pipe-fittings/v2/app_specific"
+	"github.com/turbot/steampipe/v2/pkg/filepaths"
+)
+
+func setupInstallDir(t *testing.T) {
+	previous := app_specific.InstallDir
+	app_specific.InstallDir = filepath.Join(t.TempDir(), ".steampipe")
+	t.Cleanup(func() { app_specific.InstallDir = previous })
+}
+
+func TestValidateName(t *testing.T) {
+	for _, name := range []string{"a", "running_instances", "top-10", "Q1"} {
+		assert.NoError(t, ValidateName(name), name)
+	}
+	for _, name := range []string{"", "1abc", "_abc", "a b", "a.sql", "../a", "select *"} {
+		assert.Error(t, ValidateName(name), name)
+	}
+}
+
+func TestSaveLoadList(t *testing.T) {
+	setupInstallDir(t)
+
+	replaced, err := Save("b_query", "select 2")
+	require.NoError(t, err)
+	assert.False(t, replaced)
+	_, err = Save("a_query", "select 1;\nselect 3;")
+	require.NoError(t, err)
+
+	replaced, err = Save("b_query", "select 22")
+	require.NoError(t, err)
+	assert.True(t, replaced)
+
+	q, err := Load("b_query")
+	require.NoError(t, err)
+	require.NotNil(t, q)
+	assert.Equal(t, "select 22", q.SQL)
+
+	q, err = Load("missing")
+	require.NoError(t, err)
+	assert.Nil(t, q)
+
+	assert.Equal(t, []string{"a_query", "b_query"}, Names())
+}
+
+func TestLoadList_DoesNotCreateDir(t *testing.T) {
+	setupInstallDir(t)
+
+	q, err := Load("missing")
+	require.NoError(t, err)
+	assert.Nil(t, q)
+	queries, err := List()
+	require.NoError(t, err)
+	assert.Empty(t, queries)
+
+	_, err = os.Stat(filepaths.SavedQueriesDir())
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestSave_Invalid(t *testing.T) {
+	setupInstallDir(t)
+
+	_, err := Save("not valid", "select 1")
+	assert.Error(t, err)
+	_, err = Save("empty", "  \n")
+	assert.Error(t, err)
+	assert.Empty(t, Names())
+}
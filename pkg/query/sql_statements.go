@@ -0,0 +1,181 @@
+package query
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// sqlCodeSegment is a range of SQL text which is code, i.e. not within a string literal,
+// quoted identifier, dollar quoted string or comment
+type sqlCodeSegment struct {
+	start, end int
+}
+
+var dollarQuoteTagRegex = regexp.MustCompile(`^\$([a-zA-Z_][a-zA-Z0-9_]*)?\$`)
+
+// sqlCodeSegments scans the SQL text and returns the segments which are code
+func sqlCodeSegments(sql string) []sqlCodeSegment {
+	var segments []sqlCodeSegment
+	start := 0
+	// closeSegment ends the current code segment at pos - the next segment starts after the skip bytes
+	// of the quoted string or comment which starts at pos
+	closeSegment := func(pos, skip int) {
+		if pos > start {
+			segments = append(segments, sqlCodeSegment{start, pos})
+		}
+		start = pos + skip
+	}
+
+	for i := 0; i < len(sql); i++ {
+		switch c := sql[i]; {
+		case c == '\'':
+			// escape strings (E'...') support backslash escapes
+			escape := i > 0 && (sql[i-1] == 'e' || sql[i-1] == 'E') && (i == 1 || !isIdentifierChar(sql[i-2]))
+			end := endOfQuoted(sql, i+1, '\'', escape)
+			closeSegment(i, end-i)
+			i = end - 1
+		case c == '"':
+			end := endOfQuoted(sql, i+1, '"', false)
+			closeSegment(i, end-i)
+			i = end - 1
+		case c == '-' && strings.HasPrefix(sql[i:], "--"):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end == -1 {
+				end = len(sql)
+			} else {
+				end += i + 1
+			}
+			closeSegment(i, end-i)
+			i = end - 1
+		case c == '/' && strings.HasPrefix(sql[i:], "/*"):
+			end := endOfBlockComment(sql, i)
+			closeSegment(i, end-i)
+			i = end - 1
+		case c == '$' && (i == 0 || !isIdentifierChar(sql[i-1])):
+			tag := dollarQuoteTagRegex.FindString(sql[i:])
+			if tag == "" {
+				// this is a positional placeholder
+				continue
+			}
+			end := strings.Index(sql[i+len(tag):], tag)
+			if end == -1 {
+				end = len(sql)
+			} else {
+				end += i + 2*len(tag)
+			}
+			closeSegment(i, end-i)
+			i = end - 1
+		}
+	}
+	closeSegment(len(sql), 0)
+	return segments
+}
+
+// endOfQuoted returns the position after the closing quote of a quoted string or identifier which starts at pos
+// a doubled quote is an escaped quote
+func endOfQuoted(sql string, pos int, quote byte, backslashEscapes bool) int {
+	for i := pos; i < len(sql); i++ {
+		switch sql[i] {
+		case '\\':
+			if backslashEscapes {
+				i++
+			}
+		case quote:
+			if i+1 < len(sql) && sql[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(sql)
+}
+
+// endOfBlockComment returns the position after the end of the (possibly nested) block comment which starts at pos
+func endOfBlockComment(sql string, pos int) int {
+	depth := 0
+	for i := pos; i < len(sql)-1; i++ {
+		switch {
+		case sql[i] == '/' && sql[i+1] == '*':
+			depth++
+			i++
+		case sql[i] == '*' && sql[i+1] == '/':
+			depth--
+			i++
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(sql)
+}
+
+func isIdentifierChar(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c >= 0x80
+}
+
+// SplitStatements splits SQL text into individual statements, separated by semicolons which are not
+// within a string literal, quoted identifier, dollar quoted string or comment.
+// Statements are trimmed, and statements containing only whitespace and comments are removed
+func SplitStatements(sql string) []string {
+	var statements []string
+	start := 0
+	hasCode := false
+	addStatement := func(end int) {
+		if hasCode {
+			statements = append(statements, strings.TrimSpace(sql[start:end]))
+		}
+		start = end + 1
+		hasCode = false
+	}
+
+	for _, segment := range sqlCodeSegments(sql) {
+		code := sql[segment.start:segment.end]
+		for i := 0; i < len(code); i++ {
+			if code[i] == ';' {
+				addStatement(segment.start + i)
+				continue
+			}
+			if !isSpace(code[i]) {
+				hasCode = true
+			}
+		}
+		// a quoted string or identifier following this segment is code
+		if segment.end < len(sql) && (sql[segment.end] == '\'' || sql[segment.end] == '"' || sql[segment.end] == '$') {
+			hasCode = true
+		}
+	}
+	addStatement(len(sql))
+	return statements
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v'
+}
+
+var (
+	positionalPlaceholderRegex = regexp.MustCompile(`\$([0-9]+)`)
+	namedPlaceholderRegex      = regexp.MustCompile(`@([a-zA-Z_][a-zA-Z0-9_]*)`)
+)
+
+// statementPlaceholders returns the named placeholders and the highest positional placeholder used by a statement
+func statementPlaceholders(sql string) (named map[string]struct{}, maxPositional int) {
+	named = make(map[string]struct{})
+	for _, segment := range sqlCodeSegments(sql) {
+		code := sql[segment.start:segment.end]
+		for _, match := range positionalPlaceholderRegex.FindAllStringSubmatchIndex(code, -1) {
+			// ignore $ within identifiers
+			if match[0] > 0 && isIdentifierChar(code[match[0]-1]) {
+				continue
+			}
+			if n, err := strconv.Atoi(code[match[2]:match[3]]); err == nil && n > maxPositional {
+				maxPositional = n
+			}
+		}
+		for _, match := range namedPlaceholderRegex.FindAllStringSubmatch(code, -1) {
+			named[match[1]] = struct{}{}
+		}
+	}
+	return named, maxPositional
+}
@@ -0,0 +1,70 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := map[string]struct {
+		sql      string
+		expected []string
+	}{
+		"single statement": {
+			sql:      "select 1",
+			expected: []string{"select 1"},
+		},
+		"single statement with trailing semicolon": {
+			sql:      "select 1;\n",
+			expected: []string{"select 1"},
+		},
+		"multiple statements": {
+			sql:      "select 1;\nselect 2;\n\nselect 3",
+			expected: []string{"select 1", "select 2", "select 3"},
+		},
+		"semicolon in string": {
+			sql:      "select 'a;b'; select 'it''s;'",
+			expected: []string{"select 'a;b'", "select 'it''s;'"},
+		},
+		"semicolon in escape string": {
+			sql:      `select E'a\';b'; select 2`,
+			expected: []string{`select E'a\';b'`, "select 2"},
+		},
+		"semicolon in quoted identifier": {
+			sql:      `select 1 as "a;b"; select 2`,
+			expected: []string{`select 1 as "a;b"`, "select 2"},
+		},
+		"semicolon in dollar quoted string": {
+			sql:      "select $$a;b$$; select $tag$ $$; $tag$; select 3",
+			expected: []string{"select $$a;b$$", "select $tag$ $$; $tag$", "select 3"},
+		},
+		"semicolon in comments": {
+			sql:      "select 1 -- comment;\n; /* a; /* nested; */ b; */ select 2",
+			expected: []string{"select 1 -- comment;", "/* a; /* nested; */ b; */ select 2"},
+		},
+		"comment only statements are removed": {
+			sql:      "-- header comment\nselect 1;\n-- trailing comment;\n/* block */;",
+			expected: []string{"-- header comment\nselect 1"},
+		},
+		"positional placeholders are not dollar quotes": {
+			sql:      "select $1; select $2",
+			expected: []string{"select $1", "select $2"},
+		},
+		"empty": {
+			sql:      " \n ",
+			expected: nil,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, SplitStatements(tc.sql))
+		})
+	}
+}
+
+func TestStatementPlaceholders(t *testing.T) {
+	named, maxPositional := statementPlaceholders("select * from t where a = $2 and b = @region and c = '$3 @quoted' -- $4 @commented\n and d = $1")
+	assert.Equal(t, map[string]struct{}{"region": {}}, named)
+	assert.Equal(t, 2, maxPositional)
+}
@@ -0,0 +1,125 @@
+package transcript
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+	"github.com/turbot/go-kit/helpers"
+	pconstants "github.com/turbot/pipe-fittings/v2/constants"
+	"github.com/turbot/steampipe/v2/pkg/constants"
+)
+
+// the names of the recorded settings
+const (
+	SettingOutput     = "output"
+	SettingHeader     = "header"
+	SettingSeparator  = "separator"
+	SettingTiming     = "timing"
+	SettingSearchPath = "search_path"
+	SettingCache      = "cache"
+	SettingCacheTTL   = "cache_ttl"
+)
+
+// Settings is a map of setting name to value, for the settings of an interactive session
+// which affect the results of queries, or how they are displayed
+type Settings map[string]any
+
+// CurrentSettings returns the current settings of the session, with the given session search path
+func CurrentSettings(searchPath []string) Settings {
+	return Settings{
+		SettingOutput:     viper.GetString(pconstants.ArgOutput),
+		SettingHeader:     viper.GetBool(pconstants.ArgHeader),
+		SettingSeparator:  viper.GetString(pconstants.ArgSeparator),
+		SettingTiming:     viper.GetString(pconstants.ArgTiming),
+		SettingSearchPath: helpers.RemoveFromStringSlice(searchPath, constants.InternalSchema),
+		// caching is enabled unless it has been turned off
+		SettingCache:    !viper.IsSet(pconstants.ArgClientCacheEnabled) || viper.GetBool(pconstants.ArgClientCacheEnabled),
+		SettingCacheTTL: viper.GetInt(pconstants.ArgCacheTtl),
+	}
+}
+
+// Changed returns the settings whose value differs from the previous settings
+func (s Settings) Changed(previous Settings) Settings {
+	res := Settings{}
+	for name, value := range s {
+		if settingValue(value) != settingValue(previous[name]) {
+			res[name] = value
+		}
+	}
+	return res
+}
+
+// String returns the settings as a comma separated list of name=value, sorted by name
+func (s Settings) String() string {
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	res := make([]string, len(names))
+	for i, name := range names {
+		res[i] = fmt.Sprintf("%s=%s", name, settingValue(s[name]))
+	}
+	return strings.Join(res, ", ")
+}
+
+// GetString returns the value of a string setting
+func (s Settings) GetString(name string) (string, bool) {
+	value, ok := s[name].(string)
+	return value, ok
+}
+
+// GetBool returns the value of a boolean setting
+func (s Settings) GetBool(name string) (bool, bool) {
+	value, ok := s[name].(bool)
+	return value, ok
+}
+
+// GetInt returns the value of an integer setting - settings loaded from a transcript file are json numbers
+func (s Settings) GetInt(name string) (int, bool) {
+	switch value := s[name].(type) {
+	case int:
+		return value, true
+	case json.Number:
+		i, err := strconv.Atoi(value.String())
+		return i, err == nil
+	case float64:
+		return int(value), true
+	}
+	return 0, false
+}
+
+// GetStringSlice returns the value of a list setting - settings loaded from a transcript file are a slice of any
+func (s Settings) GetStringSlice(name string) ([]string, bool) {
+	switch value := s[name].(type) {
+	case []string:
+		return value, true
+	case []any:
+		res := make([]string, len(value))
+		for i, v := range value {
+			res[i] = fmt.Sprint(v)
+		}
+		return res, true
+	}
+	return nil, false
+}
+
+// settingValue returns the string representation of a setting value, so values loaded from a transcript file
+// can be compared with current values
+func settingValue(value any) string {
+	switch v := value.(type) {
+	case []string:
+		return strings.Join(v, ",")
+	case []any:
+		res := make([]string, len(v))
+		for i, item := range v {
+			res[i] = fmt.Sprint(item)
+		}
+		return strings.Join(res, ",")
+	}
+	return fmt.Sprint(value)
+}
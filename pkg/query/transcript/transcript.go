@@ -0,0 +1,149 @@
+package transcript
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/turbot/pipe-fittings/v2/queryresult"
+	"github.com/turbot/steampipe-plugin-sdk/v5/sperr"
+)
+
+// the version of the transcript file format
+const transcriptVersion = 1
+
+// the types of transcript entry
+const (
+	// EntryStart is the first entry of a transcript, recording the settings of the session
+	EntryStart = "start"
+	// EntryQuery is a sql query and its result
+	EntryQuery = "query"
+	// EntryMetaquery is a metaquery, and the settings it changed
+	EntryMetaquery = "metaquery"
+)
+
+// Entry is a line of a transcript file
+type Entry struct {
+	Type string `json:"type"`
+	// for the start entry, the version of the transcript format
+	Version int `json:"version,omitempty"`
+	// the time the entry was started
+	Time time.Time `json:"time"`
+	// the query or metaquery, as entered
+	Input string `json:"input,omitempty"`
+	// for queries, the sql which was executed and its args - these differ from the input for saved queries
+	SQL  string `json:"sql,omitempty"`
+	Args []any  `json:"args,omitempty"`
+	// the execution time of the query or metaquery
+	DurationMs int64 `json:"duration_ms"`
+	// for the start entry, the settings of the session - for metaqueries, the settings which the metaquery changed
+	Settings Settings `json:"settings,omitempty"`
+	// the result of a query - rows are a map of column name to value
+	Columns []*queryresult.ColumnDef `json:"columns,omitempty"`
+	Rows    []map[string]any         `json:"rows,omitempty"`
+	Error   string                   `json:"error,omitempty"`
+}
+
+// Duration returns the execution time of the entry
+func (e *Entry) Duration() time.Duration {
+	return time.Duration(e.DurationMs) * time.Millisecond
+}
+
+// Recorder writes the entries of an interactive session to a transcript file
+type Recorder struct {
+	path    string
+	file    *os.File
+	encoder *json.Encoder
+	entries int
+	mut     sync.Mutex
+}
+
+// NewRecorder creates (or truncates) the transcript file at path, and writes the start entry with the given settings
+func NewRecorder(path string, settings Settings) (*Recorder, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, sperr.WrapWithMessage(err, "failed to create transcript file")
+	}
+	encoder := json.NewEncoder(file)
+	encoder.SetEscapeHTML(false)
+	r := &Recorder{path: path, file: file, encoder: encoder}
+	if err := r.Record(&Entry{Type: EntryStart, Version: transcriptVersion, Time: time.Now(), Settings: settings}); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// Path returns the path of the transcript file
+func (r *Recorder) Path() string {
+	return r.path
+}
+
+// Entries returns the number of entries recorded, excluding the start entry
+func (r *Recorder) Entries() int {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	return r.entries - 1
+}
+
+// Record writes an entry to the transcript file
+func (r *Recorder) Record(entry *Entry) error {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	if err := r.encoder.Encode(entry); err != nil {
+		return sperr.WrapWithMessage(err, "failed to write to transcript file %s", r.path)
+	}
+	r.entries++
+	return nil
+}
+
+// Close closes the transcript file
+func (r *Recorder) Close() error {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	if err := r.file.Close(); err != nil {
+		return sperr.WrapWithMessage(err, "failed to close transcript file %s", r.path)
+	}
+	return nil
+}
+
+// Load reads the entries of a transcript file - the first entry must be the start entry
+func Load(path string) ([]*Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, sperr.WrapWithMessage(err, "failed to open transcript file")
+	}
+	defer file.Close()
+
+	var entries []*Entry
+	scanner := bufio.NewScanner(file)
+	// results may be large, so allow long lines
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry Entry
+		decoder := json.NewDecoder(bytes.NewReader(scanner.Bytes()))
+		decoder.UseNumber()
+		if err := decoder.Decode(&entry); err != nil {
+			return nil, fmt.Errorf("%s line %d is not a valid transcript entry: %s", path, line, err.Error())
+		}
+		entries = append(entries, &entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, sperr.WrapWithMessage(err, "failed to read transcript file %s", path)
+	}
+
+	if len(entries) == 0 || entries[0].Type != EntryStart {
+		return nil, fmt.Errorf("%s is not a session transcript - transcripts are recorded with the .record metaquery", path)
+	}
+	if entries[0].Version > transcriptVersion {
+		return nil, fmt.Errorf("%s was recorded by a newer version of steampipe (transcript version %d)", path, entries[0].Version)
+	}
+	return entries, nil
+}
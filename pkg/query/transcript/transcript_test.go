@@ -0,0 +1,85 @@
+package transcript
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/turbot/pipe-fittings/v2/queryresult"
+)
+
+func TestRecorder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	settings := Settings{SettingOutput: "table", SettingSearchPath: []string{"aws", "public"}, SettingCacheTTL: 300}
+	recorder, err := NewRecorder(path, settings)
+	require.NoError(t, err)
+
+	require.NoError(t, recorder.Record(&Entry{
+		Type:       EntryQuery,
+		Time:       time.Now(),
+		Input:      "select $1::int as id",
+		SQL:        "select $1::int as id",
+		Args:       []any{1},
+		DurationMs: 25,
+		Columns:    []*queryresult.ColumnDef{{Name: "id", DataType: "INT4"}},
+		Rows:       []map[string]any{{"id": 1}},
+	}))
+	require.NoError(t, recorder.Record(&Entry{Type: EntryMetaquery, Time: time.Now(), Input: ".output csv", Settings: Settings{SettingOutput: "csv"}}))
+	assert.Equal(t, 2, recorder.Entries())
+	require.NoError(t, recorder.Close())
+
+	entries, err := Load(path)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	start := entries[0]
+	assert.Equal(t, EntryStart, start.Type)
+	searchPath, _ := start.Settings.GetStringSlice(SettingSearchPath)
+	assert.Equal(t, []string{"aws", "public"}, searchPath)
+	ttl, _ := start.Settings.GetInt(SettingCacheTTL)
+	assert.Equal(t, 300, ttl)
+
+	q := entries[1]
+	assert.Equal(t, 25*time.Millisecond, q.Duration())
+	assert.Equal(t, []any{json.Number("1")}, q.Args)
+	assert.Equal(t, "id", q.Columns[0].Name)
+
+	output, _ := entries[2].Settings.GetString(SettingOutput)
+	assert.Equal(t, "csv", output)
+}
+
+func TestLoad_InvalidFile(t *testing.T) {
+	dir := t.TempDir()
+
+	notTranscript := filepath.Join(dir, "query.json")
+	require.NoError(t, os.WriteFile(notTranscript, []byte(`{"type":"query","input":"select 1"}`), 0600))
+	_, err := Load(notTranscript)
+	assert.ErrorContains(t, err, "is not a session transcript")
+
+	invalid := filepath.Join(dir, "invalid.jsonl")
+	require.NoError(t, os.WriteFile(invalid, []byte("{\"type\":\"start\",\"version\":1}\nnot json\n"), 0600))
+	_, err = Load(invalid)
+	assert.ErrorContains(t, err, "line 2 is not a valid transcript entry")
+
+	newer := filepath.Join(dir, "newer.jsonl")
+	require.NoError(t, os.WriteFile(newer, []byte(`{"type":"start","version":2}`), 0600))
+	_, err = Load(newer)
+	assert.ErrorContains(t, err, "newer version of steampipe")
+
+	_, err = Load(filepath.Join(dir, "missing.jsonl"))
+	assert.Error(t, err)
+}
+
+func TestSettings_Changed(t *testing.T) {
+	previous := Settings{SettingOutput: "table", SettingSearchPath: []string{"aws", "public"}, SettingCacheTTL: 300}
+	// settings loaded from a transcript are json values
+	current := Settings{SettingOutput: "csv", SettingSearchPath: []any{"aws", "public"}, SettingCacheTTL: json.Number("300")}
+
+	changed := current.Changed(previous)
+	assert.Equal(t, Settings{SettingOutput: "csv"}, changed)
+	assert.Equal(t, "output=csv", changed.String())
+}
@@ -0,0 +1,74 @@
+package snapshot
+
+import (
+	"time"
+
+	"github.com/turbot/pipe-fittings/v2/queryresult"
+	"github.com/turbot/pipe-fittings/v2/steampipeconfig"
+)
+
+// DiffStatusColumn is the column added to the query result of a diff snapshot, containing the status of each row
+const DiffStatusColumn = "_diff_status"
+
+// SnapshotDiff is the diff of the query results of two query snapshots
+type SnapshotDiff struct {
+	// the columns of the current result, followed by any columns only in the previous result
+	Columns []*queryresult.ColumnDef `json:"columns"`
+	Rows    []RowDiff                `json:"rows"`
+	Summary RowDiffSummary           `json:"summary"`
+	// the sql of the current snapshot - this is not present in stripped snapshots
+	SQL string `json:"-"`
+}
+
+// DiffSnapshots compares the query results of two query snapshots, matching rows using the values of the key columns.
+// If no key columns are given, rows are matched using all column values
+func DiffSnapshots(previous, current *steampipeconfig.SteampipeSnapshot, keyColumns []string) (*SnapshotDiff, error) {
+	previousPanel, err := QueryTablePanel(previous)
+	if err != nil {
+		return nil, err
+	}
+	currentPanel, err := QueryTablePanel(current)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := diffColumns(previousPanel.Data.Columns, currentPanel.Data.Columns)
+	rows, err := DiffRows(previousPanel.Data.Rows, currentPanel.Data.Rows, columns, keyColumns)
+	if err != nil {
+		return nil, err
+	}
+	return &SnapshotDiff{
+		Columns: columns,
+		Rows:    rows,
+		Summary: SummariseRowDiffs(rows),
+		SQL:     currentPanel.SQL,
+	}, nil
+}
+
+// diffColumns returns the current columns followed by any previous columns which have been removed
+func diffColumns(previous, current []*queryresult.ColumnDef) []*queryresult.ColumnDef {
+	res := append([]*queryresult.ColumnDef{}, current...)
+	for _, c := range previous {
+		if !hasColumn(res, c.Name) {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// ToSnapshot converts the diff to a query snapshot, with the status of each row in the DiffStatusColumn column
+func (d *SnapshotDiff) ToSnapshot(startTime time.Time) (*steampipeconfig.SteampipeSnapshot, error) {
+	data := LeafData{
+		Columns: append([]*queryresult.ColumnDef{{Name: DiffStatusColumn, DataType: "TEXT"}}, d.Columns...),
+		Rows:    make([]map[string]any, len(d.Rows)),
+	}
+	for i, r := range d.Rows {
+		row := make(map[string]any, len(r.Row)+1)
+		for k, v := range r.Row {
+			row[k] = v
+		}
+		row[DiffStatusColumn] = r.Status.String()
+		data.Rows[i] = row
+	}
+	return newQuerySnapshot(d.SQL, "", data, nil, startTime, time.Now())
+}
@@ -0,0 +1,85 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pqueryresult "github.com/turbot/pipe-fittings/v2/queryresult"
+)
+
+// writeTestSnapshot writes a stripped query snapshot file, as written by the snapshot exporter
+func writeTestSnapshot(t *testing.T, name string, cols []*pqueryresult.ColumnDef, rows []map[string]any) string {
+	t.Helper()
+	snap, err := newQuerySnapshot("select * from instances", "", LeafData{Columns: cols, Rows: rows}, nil, time.Now(), time.Now())
+	require.NoError(t, err)
+	data, err := snap.AsStrippedJson(false)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, data, 0600))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	cols := []*pqueryresult.ColumnDef{{Name: "id", DataType: "INT8"}, {Name: "state", DataType: "TEXT"}}
+	path := writeTestSnapshot(t, "a.sps", cols, []map[string]any{{"id": 1, "state": "running"}})
+
+	snap, err := Load(path)
+	require.NoError(t, err)
+
+	panel, err := QueryTablePanel(snap)
+	require.NoError(t, err)
+	require.Len(t, panel.Data.Columns, 2)
+	assert.Equal(t, "INT8", panel.Data.Columns[0].DataType)
+	assert.Equal(t, []map[string]any{{"id": float64(1), "state": "running"}}, panel.Data.Rows)
+}
+
+func TestLoad_InvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "invalid.sps")
+	require.NoError(t, os.WriteFile(path, []byte("not json"), 0600))
+
+	_, err := Load(path)
+	assert.ErrorContains(t, err, "failed to parse snapshot file")
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.sps"))
+	assert.ErrorContains(t, err, "failed to read snapshot file")
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	previousCols := []*pqueryresult.ColumnDef{{Name: "id", DataType: "INT8"}, {Name: "state", DataType: "TEXT"}, {Name: "zone", DataType: "TEXT"}}
+	currentCols := []*pqueryresult.ColumnDef{{Name: "id", DataType: "INT8"}, {Name: "state", DataType: "TEXT"}}
+	previous, err := Load(writeTestSnapshot(t, "previous.sps", previousCols, []map[string]any{
+		{"id": 1, "state": "running", "zone": "a"},
+		{"id": 2, "state": "running", "zone": "b"},
+	}))
+	require.NoError(t, err)
+	current, err := Load(writeTestSnapshot(t, "current.sps", currentCols, []map[string]any{
+		{"id": 1, "state": "stopped"},
+		{"id": 3, "state": "running"},
+	}))
+	require.NoError(t, err)
+
+	diff, err := DiffSnapshots(previous, current, []string{"id"})
+	require.NoError(t, err)
+
+	// removed columns are included after the current columns
+	require.Len(t, diff.Columns, 3)
+	assert.Equal(t, "zone", diff.Columns[2].Name)
+	assert.Equal(t, RowDiffSummary{Added: 1, Removed: 1, Changed: 1}, diff.Summary)
+	assert.Equal(t, []string{"state", "zone"}, diff.Rows[0].ChangedColumns)
+
+	snap, err := diff.ToSnapshot(time.Now())
+	require.NoError(t, err)
+	panel, err := QueryTablePanel(snap)
+	require.NoError(t, err)
+	assert.Equal(t, DiffStatusColumn, panel.Data.Columns[0].Name)
+	require.Len(t, panel.Data.Rows, 3)
+	assert.Equal(t, "changed", panel.Data.Rows[0][DiffStatusColumn])
+	assert.Equal(t, "added", panel.Data.Rows[1][DiffStatusColumn])
+	assert.Equal(t, "removed", panel.Data.Rows[2][DiffStatusColumn])
+	assert.Equal(t, "b", panel.Data.Rows[2]["zone"])
+}
@@ -0,0 +1,63 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	pconstants "github.com/turbot/pipe-fittings/v2/constants"
+	"github.com/turbot/pipe-fittings/v2/steampipeconfig"
+	"github.com/turbot/steampipe-plugin-sdk/v5/sperr"
+)
+
+// snapshotFile is the file representation of a SteampipeSnapshot - panels are decoded separately
+// as SnapshotPanel is an interface
+type snapshotFile struct {
+	steampipeconfig.SteampipeSnapshot
+	Panels map[string]json.RawMessage `json:"panels"`
+}
+
+// Load reads a query snapshot file, as written by the snapshot exporter or the snapshot output format
+func Load(filePath string) (*steampipeconfig.SteampipeSnapshot, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, sperr.WrapWithMessage(err, "failed to read snapshot file '%s'", filePath)
+	}
+	snap, err := parseSnapshot(data)
+	if err != nil {
+		return nil, sperr.WrapWithMessage(err, "failed to parse snapshot file '%s'", filePath)
+	}
+	return snap, nil
+}
+
+func parseSnapshot(data []byte) (*steampipeconfig.SteampipeSnapshot, error) {
+	var file snapshotFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	snap := file.SteampipeSnapshot
+	snap.Panels = make(map[string]steampipeconfig.SnapshotPanel, len(file.Panels))
+	for name, p := range file.Panels {
+		panel := &PanelData{}
+		if err := json.Unmarshal(p, panel); err != nil {
+			return nil, fmt.Errorf("invalid panel '%s': %w", name, err)
+		}
+		snap.Panels[name] = panel
+	}
+	return &snap, nil
+}
+
+// QueryTablePanel returns the panel containing the query result of a query snapshot
+func QueryTablePanel(snap *steampipeconfig.SteampipeSnapshot) (*PanelData, error) {
+	// the table of a snapshot query has a fixed name
+	tablePanel, ok := snap.Panels[pconstants.SnapshotQueryTableName]
+	if !ok {
+		return nil, sperr.New("dashboard does not contain table result for query")
+	}
+	panel, ok := tablePanel.(*PanelData)
+	if !ok {
+		return nil, sperr.New("failed to read query result from snapshot")
+	}
+	return panel, nil
+}
@@ -0,0 +1,176 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/turbot/pipe-fittings/v2/queryresult"
+)
+
+// RowDiffStatus describes how a row differs between two query results
+type RowDiffStatus int
+
+const (
+	RowUnchanged RowDiffStatus = iota
+	RowAdded
+	RowRemoved
+	RowChanged
+)
+
+func (s RowDiffStatus) String() string {
+	switch s {
+	case RowAdded:
+		return "added"
+	case RowRemoved:
+		return "removed"
+	case RowChanged:
+		return "changed"
+	default:
+		return "unchanged"
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler, so the status is serialised as its name
+func (s RowDiffStatus) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// RowDiff is a row of the diff of two query results
+type RowDiff struct {
+	Status RowDiffStatus `json:"status"`
+	// the row from the current result - for removed rows, the row from the previous result
+	Row map[string]any `json:"row"`
+	// for changed rows, the row from the previous result
+	PreviousRow map[string]any `json:"previous_row,omitempty"`
+	// for changed rows, the names of the columns whose values have changed
+	ChangedColumns []string `json:"changed_columns,omitempty"`
+}
+
+// RowDiffSummary is the number of rows of a diff with each status
+type RowDiffSummary struct {
+	Added     int `json:"added"`
+	Removed   int `json:"removed"`
+	Changed   int `json:"changed"`
+	Unchanged int `json:"unchanged"`
+}
+
+// HasChanges returns true if any rows were added, removed or changed
+func (s RowDiffSummary) HasChanges() bool {
+	return s.Added+s.Removed+s.Changed > 0
+}
+
+// SummariseRowDiffs returns the number of rows of each status
+func SummariseRowDiffs(diffs []RowDiff) RowDiffSummary {
+	var res RowDiffSummary
+	for _, d := range diffs {
+		switch d.Status {
+		case RowAdded:
+			res.Added++
+		case RowRemoved:
+			res.Removed++
+		case RowChanged:
+			res.Changed++
+		default:
+			res.Unchanged++
+		}
+	}
+	return res
+}
+
+// DiffRows compares the rows of two query results, matching rows using the values of the key columns.
+// If no key columns are given, rows are matched using all column values, so a changed row is reported
+// as a removed row and an added row.
+//
+// The diff contains the current rows, in order, followed by the removed rows.
+// Rows are in the snapshot format, i.e. a map of column name to value
+func DiffRows(previous, current []map[string]any, columns []*queryresult.ColumnDef, keyColumns []string) ([]RowDiff, error) {
+	for _, k := range keyColumns {
+		if !hasColumn(columns, k) {
+			return nil, fmt.Errorf("key column '%s' is not in the query result", k)
+		}
+	}
+	if len(keyColumns) == 0 {
+		for _, c := range columns {
+			keyColumns = append(keyColumns, c.Name)
+		}
+	}
+
+	// build a map of key to previous rows - there may be more than one row with the same key,
+	// in which case they are matched in order
+	previousByKey := make(map[string][]int)
+	for i, row := range previous {
+		key := rowKey(row, keyColumns)
+		previousByKey[key] = append(previousByKey[key], i)
+	}
+	matched := make([]bool, len(previous))
+
+	res := make([]RowDiff, 0, len(current))
+	for _, row := range current {
+		key := rowKey(row, keyColumns)
+		candidates := previousByKey[key]
+		if len(candidates) == 0 {
+			res = append(res, RowDiff{Status: RowAdded, Row: row})
+			continue
+		}
+		previousIdx := candidates[0]
+		previousByKey[key] = candidates[1:]
+		matched[previousIdx] = true
+
+		changedColumns := changedColumns(previous[previousIdx], row, columns)
+		if len(changedColumns) == 0 {
+			res = append(res, RowDiff{Status: RowUnchanged, Row: row})
+			continue
+		}
+		res = append(res, RowDiff{
+			Status:         RowChanged,
+			Row:            row,
+			PreviousRow:    previous[previousIdx],
+			ChangedColumns: changedColumns,
+		})
+	}
+
+	for i, row := range previous {
+		if !matched[i] {
+			res = append(res, RowDiff{Status: RowRemoved, Row: row})
+		}
+	}
+	return res, nil
+}
+
+func hasColumn(columns []*queryresult.ColumnDef, name string) bool {
+	for _, c := range columns {
+		if c.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// rowKey returns a string representation of the values of the key columns
+// NOTE: values are compared using their JSON representation, so that rows read from a snapshot file
+// (where all numbers are float64) can be compared with rows from a query result
+func rowKey(row map[string]any, keyColumns []string) string {
+	values := make([]any, len(keyColumns))
+	for i, k := range keyColumns {
+		values[i] = row[k]
+	}
+	return jsonValue(values)
+}
+
+func changedColumns(previous, current map[string]any, columns []*queryresult.ColumnDef) []string {
+	var res []string
+	for _, c := range columns {
+		if jsonValue(previous[c.Name]) != jsonValue(current[c.Name]) {
+			res = append(res, c.Name)
+		}
+	}
+	return res
+}
+
+func jsonValue(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
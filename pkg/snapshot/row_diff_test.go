@@ -0,0 +1,73 @@
+package snapshot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pqueryresult "github.com/turbot/pipe-fittings/v2/queryresult"
+)
+
+func TestDiffRows(t *testing.T) {
+	cols := []*pqueryresult.ColumnDef{
+		{Name: "id", DataType: "INT8"},
+		{Name: "state", DataType: "TEXT"},
+	}
+	previous := []map[string]any{
+		{"id": float64(1), "state": "running"},
+		{"id": float64(2), "state": "running"},
+		{"id": float64(3), "state": "stopped"},
+	}
+	// ints in the current result must match the float64 values read from a snapshot
+	current := []map[string]any{
+		{"id": int64(1), "state": "running"},
+		{"id": int64(2), "state": "stopped"},
+		{"id": int64(4), "state": "pending"},
+	}
+
+	diffs, err := DiffRows(previous, current, cols, []string{"id"})
+	require.NoError(t, err)
+	require.Len(t, diffs, 4)
+
+	assert.Equal(t, RowUnchanged, diffs[0].Status)
+	assert.Equal(t, RowChanged, diffs[1].Status)
+	assert.Equal(t, []string{"state"}, diffs[1].ChangedColumns)
+	assert.Equal(t, "running", diffs[1].PreviousRow["state"])
+	assert.Equal(t, RowAdded, diffs[2].Status)
+	assert.Equal(t, RowRemoved, diffs[3].Status)
+	assert.Equal(t, float64(3), diffs[3].Row["id"])
+
+	assert.Equal(t, RowDiffSummary{Added: 1, Removed: 1, Changed: 1, Unchanged: 1}, SummariseRowDiffs(diffs))
+}
+
+func TestDiffRows_NoKeyColumns(t *testing.T) {
+	cols := []*pqueryresult.ColumnDef{{Name: "id"}, {Name: "state"}}
+	previous := []map[string]any{{"id": 1, "state": "running"}}
+	current := []map[string]any{{"id": 1, "state": "stopped"}}
+
+	// without key columns, a changed row is a removed row and an added row
+	diffs, err := DiffRows(previous, current, cols, nil)
+	require.NoError(t, err)
+	require.Len(t, diffs, 2)
+	assert.Equal(t, RowAdded, diffs[0].Status)
+	assert.Equal(t, RowRemoved, diffs[1].Status)
+}
+
+func TestDiffRows_DuplicateKeys(t *testing.T) {
+	cols := []*pqueryresult.ColumnDef{{Name: "id"}, {Name: "state"}}
+	previous := []map[string]any{{"id": 1, "state": "a"}, {"id": 1, "state": "b"}}
+	current := []map[string]any{{"id": 1, "state": "a"}}
+
+	diffs, err := DiffRows(previous, current, cols, []string{"id"})
+	require.NoError(t, err)
+	require.Len(t, diffs, 2)
+	assert.Equal(t, RowUnchanged, diffs[0].Status)
+	assert.Equal(t, RowRemoved, diffs[1].Status)
+	assert.Equal(t, "b", diffs[1].Row["state"])
+}
+
+func TestDiffRows_MissingKeyColumn(t *testing.T) {
+	cols := []*pqueryresult.ColumnDef{{Name: "id"}}
+	_, err := DiffRows(nil, nil, cols, []string{"name"})
+	assert.ErrorContains(t, err, "key column 'name' is not in the query result")
+}
@@ -3,7 +3,6 @@ package snapshot
 import (
 	"context"
 	"fmt"
-	pconstants "github.com/turbot/pipe-fittings/v2/constants"
 	"strings"
 	"time"
 
@@ -14,7 +13,6 @@ import (
 	pqueryresult "github.com/turbot/pipe-fittings/v2/queryresult"
 	"github.com/turbot/pipe-fittings/v2/steampipeconfig"
 	"github.com/turbot/pipe-fittings/v2/utils"
-	"github.com/turbot/steampipe-plugin-sdk/v5/sperr"
 )
 
 const schemaVersion = "20221222"
@@ -43,10 +41,35 @@ type LeafData struct {
 func (*PanelData) IsSnapshotPanel() {}
 
 // QueryResultToSnapshot function to generate a snapshot from a query result
+// The title of the query result table is the name of the query, if it is a named query
 func QueryResultToSnapshot[T queryresult.TimingContainer](ctx context.Context, result *queryresult.Result[T], resolvedQuery *modconfig.ResolvedQuery, searchPath []string, startTime time.Time) (*steampipeconfig.SteampipeSnapshot, error) {
+	return newQuerySnapshot(resolvedQuery.RawSQL, QueryName(resolvedQuery), getData(ctx, result), searchPath, startTime, time.Now())
+}
+
+// QueryName returns the name of a named query, i.e. a query read from a file or a saved query.
+// Queries which are not named have their name set to the query text, so an empty string is returned for these
+func QueryName(resolvedQuery *modconfig.ResolvedQuery) string {
+	if resolvedQuery.Name == resolvedQuery.RawSQL || resolvedQuery.Name == resolvedQuery.ExecuteSQL {
+		return ""
+	}
+	return resolvedQuery.Name
+}
 
-	endTime := time.Now()
-	hash, err := utils.Base36Hash(resolvedQuery.RawSQL, 8)
+// RowsToSnapshot generates a snapshot from the columns and rows of a query result which has already been read
+func RowsToSnapshot(rawSQL string, cols []*queryresult.ColumnDef, rows [][]interface{}, searchPath []string, startTime, endTime time.Time) (*steampipeconfig.SteampipeSnapshot, error) {
+	data := LeafData{
+		Columns: ColumnDefs(cols),
+		Rows:    make([]map[string]interface{}, len(rows)),
+	}
+	for idx, row := range rows {
+		data.Rows[idx] = RowData(row, cols)
+	}
+	return newQuerySnapshot(rawSQL, "", data, searchPath, startTime, endTime)
+}
+
+// newQuerySnapshot builds a query snapshot containing the given query result data
+func newQuerySnapshot(rawSQL, title string, data LeafData, searchPath []string, startTime, endTime time.Time) (*steampipeconfig.SteampipeSnapshot, error) {
+	hash, err := utils.Base36Hash(rawSQL, 8)
 	if err != nil {
 		return nil, err
 	}
@@ -55,22 +78,22 @@ func QueryResultToSnapshot[T queryresult.TimingContainer](ctx context.Context, r
 	snapshotData := &steampipeconfig.SteampipeSnapshot{
 		SchemaVersion: schemaVersion,
 		Panels: map[string]steampipeconfig.SnapshotPanel{
-			dashboardName:          getPanelDashboard[T](ctx, result, resolvedQuery),
-			"custom.table.results": getPanelTable[T](ctx, result, resolvedQuery),
+			dashboardName:          getPanelDashboard(rawSQL),
+			"custom.table.results": getPanelTable(rawSQL, title, data),
 		},
 		Inputs:     map[string]interface{}{},
 		Variables:  map[string]string{},
 		SearchPath: searchPath,
 		StartTime:  startTime,
 		EndTime:    endTime,
-		Layout:     getLayout[T](result, resolvedQuery),
+		Layout:     getLayout(rawSQL),
 	}
 	// Return the snapshot data
 	return snapshotData, nil
 }
 
-func getPanelDashboard[T queryresult.TimingContainer](ctx context.Context, result *queryresult.Result[T], resolvedQuery *modconfig.ResolvedQuery) *PanelData {
-	hash, err := utils.Base36Hash(resolvedQuery.RawSQL, 8)
+func getPanelDashboard(rawSQL string) *PanelData {
+	hash, err := utils.Base36Hash(rawSQL, 8)
 	if err != nil {
 		return &PanelData{}
 	}
@@ -86,8 +109,8 @@ func getPanelDashboard[T queryresult.TimingContainer](ctx context.Context, resul
 	}
 }
 
-func getPanelTable[T queryresult.TimingContainer](ctx context.Context, result *queryresult.Result[T], resolvedQuery *modconfig.ResolvedQuery) *PanelData {
-	hash, err := utils.Base36Hash(resolvedQuery.RawSQL, 8)
+func getPanelTable(rawSQL, title string, data LeafData) *PanelData {
+	hash, err := utils.Base36Hash(rawSQL, 8)
 	if err != nil {
 		return &PanelData{}
 	}
@@ -99,11 +122,12 @@ func getPanelTable[T queryresult.TimingContainer](ctx context.Context, result *q
 		PanelType:        "table",
 		SourceDefinition: "",
 		Status:           "complete",
-		SQL:              resolvedQuery.RawSQL,
+		Title:            title,
+		SQL:              rawSQL,
 		Properties: map[string]string{
 			"name": "results",
 		},
-		Data: getData(ctx, result),
+		Data: data,
 	}
 }
 
@@ -126,22 +150,10 @@ func getData[T queryresult.TimingContainer](ctx context.Context, result *queryre
 		error_helpers.ShowError(ctx, fmt.Errorf("no columns found in the result"))
 	}
 	// Add column definitions to the JSON output
-	for _, col := range result.Cols {
-		c := &pqueryresult.ColumnDef{
-			Name:         col.Name,
-			OriginalName: col.OriginalName,
-			DataType:     strings.ToUpper(col.DataType),
-		}
-		jsonOutput.Columns = append(jsonOutput.Columns, c)
-	}
+	jsonOutput.Columns = ColumnDefs(result.Cols)
 	// Define function to add each row to the JSON output
 	rowFunc := func(row []interface{}, result *queryresult.Result[T]) {
-		record := map[string]interface{}{}
-		for idx, col := range result.Cols {
-			value, _ := querydisplay.ParseJSONOutputColumnValue(row[idx], col)
-			record[col.Name] = value
-		}
-		jsonOutput.Rows = append(jsonOutput.Rows, record)
+		jsonOutput.Rows = append(jsonOutput.Rows, RowData(row, result.Cols))
 	}
 	// Call iterateResults and ensure rows are processed
 	_, err := querydisplay.IterateResults(result, rowFunc)
@@ -155,8 +167,31 @@ func getData[T queryresult.TimingContainer](ctx context.Context, result *queryre
 	}
 }
 
-func getLayout[T queryresult.TimingContainer](result *queryresult.Result[T], resolvedQuery *modconfig.ResolvedQuery) *steampipeconfig.SnapshotTreeNode {
-	hash, err := utils.Base36Hash(resolvedQuery.RawSQL, 8)
+// ColumnDefs returns the snapshot column definitions for the query result columns (with upper case data types)
+func ColumnDefs(cols []*queryresult.ColumnDef) []*queryresult.ColumnDef {
+	var res []*queryresult.ColumnDef
+	for _, col := range cols {
+		res = append(res, &pqueryresult.ColumnDef{
+			Name:         col.Name,
+			OriginalName: col.OriginalName,
+			DataType:     strings.ToUpper(col.DataType),
+		})
+	}
+	return res
+}
+
+// RowData converts a query result row into a snapshot row, i.e. a map of column name to JSON output value
+func RowData(row []interface{}, cols []*queryresult.ColumnDef) map[string]interface{} {
+	record := map[string]interface{}{}
+	for idx, col := range cols {
+		value, _ := querydisplay.ParseJSONOutputColumnValue(row[idx], col)
+		record[col.Name] = value
+	}
+	return record
+}
+
+func getLayout(rawSQL string) *steampipeconfig.SnapshotTreeNode {
+	hash, err := utils.Base36Hash(rawSQL, 8)
 	if err != nil {
 		return nil
 	}
@@ -176,14 +211,9 @@ func getLayout[T queryresult.TimingContainer](result *queryresult.Result[T], res
 
 // SnapshotToQueryResult function to generate a queryresult with streamed rows from a snapshot
 func SnapshotToQueryResult[T queryresult.TimingContainer](snap *steampipeconfig.SteampipeSnapshot, startTime time.Time) (*queryresult.Result[T], error) {
-	// the table of a snapshot query has a fixed name
-	tablePanel, ok := snap.Panels[pconstants.SnapshotQueryTableName]
-	if !ok {
-		return nil, sperr.New("dashboard does not contain table result for query")
-	}
-	chartRun := tablePanel.(*PanelData)
-	if !ok {
-		return nil, sperr.New("failed to read query result from snapshot")
+	chartRun, err := QueryTablePanel(snap)
+	if err != nil {
+		return nil, err
 	}
 
 	var tim T
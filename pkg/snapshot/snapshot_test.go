@@ -555,3 +555,26 @@ func TestMemoryLeak_RepeatedConversions(t *testing.T) {
 		}
 	}
 }
+
+// TestRowsToSnapshot tests that a snapshot generated from rows which have already been read contains the rows
+func TestRowsToSnapshot(t *testing.T) {
+	cols := []*pqueryresult.ColumnDef{
+		{Name: "id", DataType: "int4"},
+		{Name: "name", DataType: "text"},
+	}
+	rows := [][]interface{}{{int32(1), "a"}, {int32(2), nil}}
+	startTime := time.Now()
+
+	snap, err := RowsToSnapshot("select id, name from t", cols, rows, []string{"public"}, startTime, startTime.Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"public"}, snap.SearchPath)
+
+	panel, err := QueryTablePanel(snap)
+	require.NoError(t, err)
+	assert.Equal(t, "select id, name from t", panel.SQL)
+	require.Len(t, panel.Data.Columns, 2)
+	assert.Equal(t, "INT4", panel.Data.Columns[0].DataType)
+	require.Len(t, panel.Data.Rows, 2)
+	assert.Equal(t, "a", panel.Data.Rows[0]["name"])
+	assert.Nil(t, panel.Data.Rows[1]["name"])
+}